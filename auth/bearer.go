@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"net/http"
+
+	"github.com/xmidt-org/httpaux"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+const (
+	// AuthorizationHeader is the header that carries credentials
+	AuthorizationHeader = "Authorization"
+
+	// BearerPrefix is the prefix for bearer token credentials
+	BearerPrefix = "Bearer "
+)
+
+// bearer is the common implementation for bearer token middleware
+type bearer struct {
+	source TokenSource
+}
+
+// authorize produces a copy of the request with a bearer token from the source.
+func (b bearer) authorize(request *http.Request) (*http.Request, Token, error) {
+	t, err := b.source.Token(request.Context())
+	if err != nil {
+		return nil, t, &TokenError{Err: err}
+	}
+
+	// NOTE: never modify the original request, as this may be an http.RoundTripper
+	authorized := request.Clone(request.Context())
+	authorized.Header.Set(AuthorizationHeader, BearerPrefix+t.AccessToken)
+	return authorized, t, nil
+}
+
+// do sends a request with a bearer token.  If the server responds with 401, a fresh
+// token is obtained and the request is sent once more.
+func (b bearer) do(request *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	authorized, t, err := b.authorize(request)
+	if err != nil {
+		if request.Body != nil {
+			request.Body.Close()
+		}
+
+		return nil, err
+	}
+
+	response, err := next(authorized)
+	if err != nil || response.StatusCode != http.StatusUnauthorized {
+		return response, err
+	}
+
+	hasBody := request.Body != nil && request.Body != http.NoBody
+	if hasBody && request.GetBody == nil {
+		// the body has already been consumed, so the request can't be sent again
+		return response, err
+	}
+
+	if i, ok := b.source.(Invalidator); ok {
+		i.Invalidate(t)
+	}
+
+	retry, fresh, tokenErr := b.authorize(request)
+	if tokenErr != nil || fresh == t {
+		// sending the same token again is pointless
+		return response, err
+	}
+
+	if hasBody {
+		body, bodyErr := request.GetBody()
+		if bodyErr != nil {
+			return response, err
+		}
+
+		retry.Body = body
+	}
+
+	httpaux.Cleanup(response)
+	return next(retry)
+}
+
+// NewBearerClient creates client middleware that sets an Authorization header with a
+// bearer token from the given source.  If the token cannot be obtained, a *TokenError
+// is returned, the request body is closed, and the request is not sent.
+//
+// If the server responds with 401 Unauthorized, the token is invalidated when the source
+// implements Invalidator, and the request is sent once more with a fresh token.  Requests with
+// a body are only resent if GetBody is set.  If the source produces the same token again, the
+// 401 response is returned as is.
+func NewBearerClient(source TokenSource) client.Constructor {
+	b := bearer{source: source}
+	return func(next httpaux.Client) httpaux.Client {
+		if next == nil {
+			next = http.DefaultClient
+		}
+
+		return client.Func(func(request *http.Request) (*http.Response, error) {
+			return b.do(request, next.Do)
+		})
+	}
+}
+
+// NewBearerRoundTripper creates round tripper middleware that authorizes requests just as
+// NewBearerClient does.
+func NewBearerRoundTripper(source TokenSource) roundtrip.Constructor {
+	b := bearer{source: source}
+	return func(next http.RoundTripper) http.RoundTripper {
+		if next == nil {
+			next = http.DefaultTransport
+		}
+
+		return roundtrip.PreserveCloseIdler(
+			next,
+			roundtrip.Func(func(request *http.Request) (*http.Response, error) {
+				return b.do(request, next.RoundTrip)
+			}),
+		)
+	}
+}
@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/httpmock"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+type BearerTestSuite struct {
+	suite.Suite
+}
+
+// sequence produces a TokenSource that returns each token in turn, then the last token forever
+func (suite *BearerTestSuite) sequence(tokens ...string) TokenSource {
+	return TokenSourceFunc(func(context.Context) (Token, error) {
+		t := Token{AccessToken: tokens[0]}
+		if len(tokens) > 1 {
+			tokens = tokens[1:]
+		}
+
+		return t, nil
+	})
+}
+
+func (suite *BearerTestSuite) authorization(token string) httpmock.RequestAsserterFunc {
+	return func(a *assert.Assertions, r *http.Request) {
+		a.Equal(BearerPrefix+token, r.Header.Get(AuthorizationHeader))
+	}
+}
+
+func (suite *BearerTestSuite) unauthorized() *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusUnauthorized,
+		Body:       httpmock.EmptyBody(),
+	}
+}
+
+func (suite *BearerTestSuite) ok() *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       httpmock.EmptyBody(),
+	}
+}
+
+func (suite *BearerTestSuite) TestNewBearerClient() {
+	var (
+		rt = httpmock.NewRoundTripperSuite(suite)
+		c  = client.NewChain(NewBearerClient(StaticTokenSource("test"))).Then(&http.Client{Transport: rt})
+	)
+
+	rt.OnAny().AssertRequest(suite.authorization("test")).Return(suite.ok(), nil).Once()
+	request, err := http.NewRequest("GET", "/test", nil)
+	suite.Require().NoError(err)
+
+	response, err := c.Do(request)
+	suite.NoError(err)
+	suite.Require().NotNil(response)
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Empty(request.Header.Get(AuthorizationHeader))
+	rt.AssertExpectations()
+}
+
+func (suite *BearerTestSuite) TestNewBearerClientDefault() {
+	suite.NotNil(NewBearerClient(StaticTokenSource("test"))(nil))
+}
+
+func (suite *BearerTestSuite) TestTokenError() {
+	var (
+		expectedErr = errors.New("expected")
+		rt          = httpmock.NewRoundTripperSuite(suite)
+		c           = client.NewChain(NewBearerClient(
+			TokenSourceFunc(func(context.Context) (Token, error) {
+				return Token{}, expectedErr
+			}),
+		)).Then(&http.Client{Transport: rt})
+	)
+
+	request, err := http.NewRequest("GET", "/test", nil)
+	suite.Require().NoError(err)
+
+	response, err := c.Do(request)
+	suite.Nil(response)
+
+	var te *TokenError
+	suite.ErrorAs(err, &te)
+	suite.ErrorIs(err, expectedErr)
+	rt.AssertExpectations()
+}
+
+func (suite *BearerTestSuite) TestUnauthorized() {
+	var (
+		next = &httpmock.CloseIdler{
+			RoundTripper: httpmock.NewRoundTripperSuite(suite),
+		}
+
+		cache = NewCache(suite.sequence("stale", "fresh"), CacheConfig{})
+		rt    = roundtrip.NewChain(NewBearerRoundTripper(cache)).Then(next)
+		first = suite.unauthorized()
+	)
+
+	next.OnAny().AssertRequest(suite.authorization("stale"), httpmock.Body("payload")).Return(first, nil).Once()
+	next.OnAny().AssertRequest(suite.authorization("fresh"), httpmock.Body("payload")).Return(suite.ok(), nil).Once()
+
+	request, err := http.NewRequest("POST", "/test", strings.NewReader("payload"))
+	suite.Require().NoError(err)
+
+	response, err := rt.RoundTrip(request)
+	suite.NoError(err)
+	suite.Require().NotNil(response)
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.True(first.Body.(*httpmock.BodyReadCloser).Closed())
+
+	// the fresh token is now cached
+	t, err := cache.Token(context.Background())
+	suite.NoError(err)
+	suite.Equal("fresh", t.AccessToken)
+
+	next.OnCloseIdleConnections().Once()
+	roundtrip.CloseIdleConnections(rt)
+	next.AssertExpectations()
+}
+
+func (suite *BearerTestSuite) TestUnauthorizedSameToken() {
+	var (
+		rt = httpmock.NewRoundTripperSuite(suite)
+		c  = client.NewChain(NewBearerClient(StaticTokenSource("test"))).Then(&http.Client{Transport: rt})
+	)
+
+	rt.OnAny().Return(suite.unauthorized(), nil).Once()
+	request, err := http.NewRequest("GET", "/test", nil)
+	suite.Require().NoError(err)
+
+	response, err := c.Do(request)
+	suite.NoError(err)
+	suite.Require().NotNil(response)
+	suite.Equal(http.StatusUnauthorized, response.StatusCode)
+	rt.AssertExpectations()
+}
+
+func (suite *BearerTestSuite) TestUnauthorizedNoGetBody() {
+	var (
+		rt = httpmock.NewRoundTripperSuite(suite)
+		c  = client.NewChain(NewBearerClient(suite.sequence("stale", "fresh"))).Then(&http.Client{Transport: rt})
+	)
+
+	rt.OnAny().Return(suite.unauthorized(), nil).Once()
+	request, err := http.NewRequest("POST", "/test", io.NopCloser(strings.NewReader("payload")))
+	suite.Require().NoError(err)
+	suite.Require().Nil(request.GetBody)
+
+	response, err := c.Do(request)
+	suite.NoError(err)
+	suite.Require().NotNil(response)
+	suite.Equal(http.StatusUnauthorized, response.StatusCode)
+	rt.AssertExpectations()
+}
+
+func (suite *BearerTestSuite) TestUnauthorizedGetBodyError() {
+	var (
+		rt = httpmock.NewRoundTripperSuite(suite)
+		c  = client.NewChain(NewBearerClient(suite.sequence("stale", "fresh"))).Then(&http.Client{Transport: rt})
+	)
+
+	rt.OnAny().Return(suite.unauthorized(), nil).Once()
+	request, err := http.NewRequest("POST", "/test", strings.NewReader("payload"))
+	suite.Require().NoError(err)
+	request.GetBody = func() (io.ReadCloser, error) {
+		return nil, errors.New("expected")
+	}
+
+	response, err := c.Do(request)
+	suite.NoError(err)
+	suite.Require().NotNil(response)
+	suite.Equal(http.StatusUnauthorized, response.StatusCode)
+	rt.AssertExpectations()
+}
+
+func (suite *BearerTestSuite) TestNewBearerRoundTripperTokenError() {
+	var (
+		expectedErr = errors.New("expected")
+		next        = httpmock.NewRoundTripperSuite(suite)
+		rt          = NewBearerRoundTripper(
+			TokenSourceFunc(func(context.Context) (Token, error) {
+				return Token{}, expectedErr
+			}),
+		)(next)
+	)
+
+	body := httpmock.BodyString("content")
+	request, err := http.NewRequest("POST", "/test", body)
+	suite.Require().NoError(err)
+
+	response, err := rt.RoundTrip(request) //nolint:bodyclose
+	suite.Nil(response)
+
+	var te *TokenError
+	suite.ErrorAs(err, &te)
+	suite.ErrorIs(err, expectedErr)
+	suite.True(body.Closed(), "the request body should be closed when no token is available")
+	next.AssertExpectations()
+}
+
+func (suite *BearerTestSuite) TestNewBearerRoundTripperDefault() {
+	suite.NotNil(NewBearerRoundTripper(StaticTokenSource("test"))(nil))
+}
+
+func TestBearer(t *testing.T) {
+	suite.Run(t, new(BearerTestSuite))
+}
@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/xmidt-org/httpaux/clock"
+)
+
+const (
+	// DefaultLeeway is used when CacheConfig.Leeway is nonpositive.
+	DefaultLeeway time.Duration = 10 * time.Second
+
+	// DefaultRefreshTimeout is used when CacheConfig.RefreshTimeout is nonpositive.
+	DefaultRefreshTimeout time.Duration = 30 * time.Second
+)
+
+// CacheConfig is the set of configuration options for a Cache.
+type CacheConfig struct {
+	// Leeway is how long before a token's expiry that it is refreshed.  This accounts for
+	// clock skew and for the time a request spends in flight.  If nonpositive, DefaultLeeway
+	// is used.
+	Leeway time.Duration `json:"leeway" yaml:"leeway"`
+
+	// RefreshTimeout bounds each call to the underlying TokenSource.  A refresh is shared by
+	// all callers, so it does not honor any one caller's deadline.  This timeout ensures that a
+	// hung token endpoint cannot block refreshes forever.  If nonpositive, DefaultRefreshTimeout
+	// is used.
+	RefreshTimeout time.Duration `json:"refreshTimeout" yaml:"refreshTimeout"`
+
+	// Clock is the optional source of time used to check expiry.  If unset, clock.System is used.
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+// refresh is a single, shared call to the underlying TokenSource
+type refresh struct {
+	done  chan struct{}
+	token Token
+	err   error
+}
+
+// Cache is a TokenSource that caches tokens from another TokenSource.  A cached token is
+// returned until it is within the configured leeway of expiring.  Only one refresh is in flight
+// at a time, and all concurrent callers share its result.
+//
+// A Cache is safe for concurrent use.
+type Cache struct {
+	source         TokenSource
+	leeway         time.Duration
+	refreshTimeout time.Duration
+	now            func() time.Time
+
+	lock    sync.Mutex
+	token   Token
+	pending *refresh
+}
+
+var (
+	_ TokenSource = (*Cache)(nil)
+	_ Invalidator = (*Cache)(nil)
+)
+
+// NewCache creates a Cache that obtains tokens from the given source.
+func NewCache(source TokenSource, cfg CacheConfig) *Cache {
+	c := &Cache{
+		source:         source,
+		leeway:         cfg.Leeway,
+		refreshTimeout: cfg.RefreshTimeout,
+		now:            clock.OrSystem(cfg.Clock).Now,
+	}
+
+	if c.leeway <= 0 {
+		c.leeway = DefaultLeeway
+	}
+
+	if c.refreshTimeout <= 0 {
+		c.refreshTimeout = DefaultRefreshTimeout
+	}
+
+	return c
+}
+
+// Token returns the cached token if it is still valid.  Otherwise, this method waits for
+// a refresh from the underlying source.  Errors from the source are not cached.
+//
+// The refresh is not canceled if the given context is canceled, since other callers may be
+// waiting on it.  Instead, it is bounded by the configured refresh timeout.  This method does
+// return early if the context is canceled.
+func (c *Cache) Token(ctx context.Context) (Token, error) {
+	c.lock.Lock()
+	if c.token.Valid(c.now(), c.leeway) {
+		defer c.lock.Unlock()
+		return c.token, nil
+	}
+
+	r := c.pending
+	if r == nil {
+		r = &refresh{
+			done: make(chan struct{}),
+		}
+
+		c.pending = r
+		go c.refresh(context.WithoutCancel(ctx), r)
+	}
+
+	c.lock.Unlock()
+
+	select {
+	case <-r.done:
+		return r.token, r.err
+
+	case <-ctx.Done():
+		return Token{}, ctx.Err()
+	}
+}
+
+// refresh obtains a token from the underlying source and publishes it to all waiters.
+func (c *Cache) refresh(ctx context.Context, r *refresh) {
+	defer close(r.done)
+	ctx, cancel := context.WithTimeout(ctx, c.refreshTimeout)
+	defer cancel()
+	r.token, r.err = c.source.Token(ctx)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.pending = nil
+	if r.err == nil {
+		c.token = r.token
+	}
+}
+
+// Invalidate discards the given token if it is the currently cached token.  This allows
+// a token that a server has rejected to be replaced, without discarding a newer token that
+// was obtained concurrently.
+func (c *Cache) Invalidate(t Token) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.token == t {
+		c.token = Token{}
+	}
+}
@@ -0,0 +1,189 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/clock"
+)
+
+type CacheTestSuite struct {
+	suite.Suite
+
+	clock *clock.Fake
+	calls atomic.Int32
+}
+
+func (suite *CacheTestSuite) SetupTest() {
+	suite.clock = clock.NewFake(time.Date(2021, time.March, 4, 10, 30, 0, 0, time.UTC))
+	suite.calls.Store(0)
+}
+
+// source produces a new token, valid for a minute, each time it is called
+func (suite *CacheTestSuite) source(context.Context) (Token, error) {
+	n := suite.calls.Add(1)
+	return Token{
+		AccessToken: "token" + strconv.Itoa(int(n)),
+		Expiry:      suite.clock.Now().Add(time.Minute),
+	}, nil
+}
+
+func (suite *CacheTestSuite) newCache(source TokenSourceFunc) *Cache {
+	c := NewCache(source, CacheConfig{
+		Leeway: 10 * time.Second,
+		Clock:  suite.clock,
+	})
+
+	suite.Require().NotNil(c)
+	return c
+}
+
+func (suite *CacheTestSuite) token(c *Cache) Token {
+	t, err := c.Token(context.Background())
+	suite.Require().NoError(err)
+	return t
+}
+
+func (suite *CacheTestSuite) TestDefaults() {
+	c := NewCache(StaticTokenSource("test"), CacheConfig{})
+	suite.Equal(DefaultLeeway, c.leeway)
+	suite.Equal(DefaultRefreshTimeout, c.refreshTimeout)
+	suite.Equal("test", suite.token(c).AccessToken)
+}
+
+func (suite *CacheTestSuite) TestExpiry() {
+	c := suite.newCache(suite.source)
+	suite.Equal("token1", suite.token(c).AccessToken)
+	suite.Equal("token1", suite.token(c).AccessToken)
+
+	// still outside the leeway
+	suite.clock.Add(49 * time.Second)
+	suite.Equal("token1", suite.token(c).AccessToken)
+
+	suite.clock.Add(time.Second)
+	suite.Equal("token2", suite.token(c).AccessToken)
+	suite.Equal(int32(2), suite.calls.Load())
+}
+
+func (suite *CacheTestSuite) TestInvalidate() {
+	c := suite.newCache(suite.source)
+	first := suite.token(c)
+
+	c.Invalidate(Token{AccessToken: "some other token"})
+	suite.Equal(first, suite.token(c))
+
+	c.Invalidate(first)
+	suite.Equal("token2", suite.token(c).AccessToken)
+}
+
+func (suite *CacheTestSuite) TestError() {
+	var (
+		expectedErr = errors.New("expected")
+		fail        = true
+		c           = suite.newCache(func(ctx context.Context) (Token, error) {
+			if fail {
+				return Token{}, expectedErr
+			}
+
+			return suite.source(ctx)
+		})
+	)
+
+	_, err := c.Token(context.Background())
+	suite.ErrorIs(err, expectedErr)
+
+	// errors are not cached
+	fail = false
+	suite.Equal("token1", suite.token(c).AccessToken)
+}
+
+func (suite *CacheTestSuite) TestSingleFlight() {
+	var (
+		release = make(chan struct{})
+		c       = suite.newCache(func(ctx context.Context) (Token, error) {
+			<-release
+			return suite.source(ctx)
+		})
+
+		wg      sync.WaitGroup
+		results = make(chan Token, 10)
+	)
+
+	for i := 0; i < cap(results); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- suite.token(c)
+		}()
+	}
+
+	close(release)
+	wg.Wait()
+	close(results)
+	for t := range results {
+		suite.Equal("token1", t.AccessToken)
+	}
+
+	suite.Equal(int32(1), suite.calls.Load())
+}
+
+func (suite *CacheTestSuite) TestCanceled() {
+	var (
+		release = make(chan struct{})
+		c       = suite.newCache(func(ctx context.Context) (Token, error) {
+			<-release
+			suite.NoError(ctx.Err())
+			return suite.source(ctx)
+		})
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.Token(ctx)
+	suite.ErrorIs(err, context.Canceled)
+
+	// the refresh continues for other callers
+	close(release)
+	suite.Equal("token1", suite.token(c).AccessToken)
+	suite.Equal(int32(1), suite.calls.Load())
+}
+
+func (suite *CacheTestSuite) TestRefreshTimeout() {
+	var (
+		hang atomic.Bool
+		c    = NewCache(
+			TokenSourceFunc(func(ctx context.Context) (Token, error) {
+				if hang.CompareAndSwap(false, true) {
+					// the first refresh hangs until the timeout
+					<-ctx.Done()
+					return Token{}, ctx.Err()
+				}
+
+				return suite.source(ctx)
+			}),
+			CacheConfig{
+				RefreshTimeout: 10 * time.Millisecond,
+				Clock:          suite.clock,
+			},
+		)
+	)
+
+	_, err := c.Token(context.Background())
+	suite.ErrorIs(err, context.DeadlineExceeded)
+
+	// a hung refresh does not block subsequent refreshes
+	suite.Equal("token1", suite.token(c).AccessToken)
+}
+
+func TestCache(t *testing.T) {
+	suite.Run(t, new(CacheTestSuite))
+}
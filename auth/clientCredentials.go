@@ -0,0 +1,268 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xmidt-org/httpaux"
+	"github.com/xmidt-org/httpaux/clock"
+)
+
+const (
+	// maxTokenResponse is the largest token endpoint response body that will be read
+	maxTokenResponse = 1 << 20
+)
+
+// AuthStyle describes how client credentials are sent to a token endpoint.
+type AuthStyle string
+
+const (
+	// AuthStyleHeader sends the client credentials using HTTP Basic authentication.
+	// This is the default, and is the style that RFC 6749 requires servers to support.
+	AuthStyleHeader AuthStyle = ""
+
+	// AuthStyleBody sends the client credentials as client_id and client_secret form parameters.
+	AuthStyleBody AuthStyle = "body"
+)
+
+// ClientCredentialsConfig is the set of configuration options for the OAuth2
+// client_credentials grant.
+type ClientCredentialsConfig struct {
+	// TokenURL is the token endpoint of the authorization server.  This field is required.
+	TokenURL string `json:"tokenURL" yaml:"tokenURL"`
+
+	// ClientID is this application's client identifier.
+	ClientID string `json:"clientID" yaml:"clientID"`
+
+	// ClientSecret is this application's client secret.
+	ClientSecret string `json:"clientSecret" yaml:"clientSecret"`
+
+	// Scopes are the optional scopes to request.
+	Scopes []string `json:"scopes" yaml:"scopes"`
+
+	// Params are optional, additional form parameters sent to the token endpoint, such as audience.
+	Params map[string][]string `json:"params" yaml:"params"`
+
+	// AuthStyle controls how the client credentials are sent.  By default, HTTP Basic
+	// authentication is used.
+	AuthStyle AuthStyle `json:"authStyle" yaml:"authStyle"`
+
+	// Client is the HTTP client used to talk to the token endpoint.  If unset,
+	// http.DefaultClient is used.
+	Client httpaux.Client `json:"-" yaml:"-"`
+
+	// Clock is the optional source of time used to compute token expiry.  If unset,
+	// clock.System is used.
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+// OAuth2Error is returned when a token endpoint responds with something other than a token.
+type OAuth2Error struct {
+	// StatusCode is the HTTP status code of the token endpoint's response.
+	StatusCode int
+
+	// Code is the OAuth2 error code, e.g. invalid_client.  This will be empty if the
+	// response did not contain an OAuth2 error.
+	Code string
+
+	// Description is the optional, human-readable error_description.
+	Description string
+
+	// URI is the optional error_uri.
+	URI string
+}
+
+// Error fulfills the error interface.
+func (err *OAuth2Error) Error() string {
+	var o strings.Builder
+	o.WriteString("token endpoint responded with ")
+	o.WriteString(strconv.Itoa(err.StatusCode))
+	if len(err.Code) > 0 {
+		o.WriteString(": ")
+		o.WriteString(err.Code)
+	}
+
+	if len(err.Description) > 0 {
+		o.WriteString(" [")
+		o.WriteString(err.Description)
+		o.WriteRune(']')
+	}
+
+	return o.String()
+}
+
+// expiresIn is the expires_in field of a token response.  some servers send
+// this field as a string rather than a number.
+type expiresIn int64
+
+func (ei *expiresIn) UnmarshalJSON(b []byte) error {
+	v := strings.Trim(string(b), `"`)
+	if len(v) == 0 || v == "null" {
+		*ei = 0
+		return nil
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid expires_in [%s]: %w", v, err)
+	}
+
+	*ei = expiresIn(n)
+	return nil
+}
+
+// tokenResponse is the JSON body of a token endpoint response, including errors.
+type tokenResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresIn   expiresIn `json:"expires_in"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	ErrorURI         string `json:"error_uri"`
+}
+
+// ClientCredentials is a TokenSource that implements the OAuth2 client_credentials grant
+// as described in RFC 6749, section 4.4.  Each call to Token obtains a new token from the token
+// endpoint, so this type is normally wrapped in a Cache:
+//
+//	source := auth.NewCache(
+//	  auth.NewClientCredentials(auth.ClientCredentialsConfig{
+//	    TokenURL: "https://auth.example.com/oauth2/token",
+//	    ClientID: "my-client",
+//	    ClientSecret: "my-secret",
+//	    Scopes: []string{"read", "write"},
+//	  }),
+//	  auth.CacheConfig{},
+//	)
+type ClientCredentials struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	form         url.Values
+	authStyle    AuthStyle
+	client       httpaux.Client
+	now          func() time.Time
+}
+
+var _ TokenSource = (*ClientCredentials)(nil)
+
+// NewClientCredentials creates a client_credentials TokenSource from a configuration.
+func NewClientCredentials(cfg ClientCredentialsConfig) *ClientCredentials {
+	cc := &ClientCredentials{
+		tokenURL:     cfg.TokenURL,
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		form:         url.Values{},
+		authStyle:    cfg.AuthStyle,
+		client:       cfg.Client,
+		now:          clock.OrSystem(cfg.Clock).Now,
+	}
+
+	for name, values := range cfg.Params {
+		cc.form[name] = append([]string(nil), values...)
+	}
+
+	cc.form.Set("grant_type", "client_credentials")
+	if len(cfg.Scopes) > 0 {
+		cc.form.Set("scope", strings.Join(cfg.Scopes, " "))
+	}
+
+	if cc.authStyle == AuthStyleBody {
+		cc.form.Set("client_id", cc.clientID)
+		cc.form.Set("client_secret", cc.clientSecret)
+	}
+
+	if cc.client == nil {
+		cc.client = http.DefaultClient
+	}
+
+	return cc
+}
+
+// newRequest creates the token request.
+func (cc *ClientCredentials) newRequest(ctx context.Context) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		cc.tokenURL,
+		strings.NewReader(cc.form.Encode()),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if cc.authStyle == AuthStyleHeader {
+		// RFC 6749, section 2.3.1 requires form encoding of the credentials
+		request.SetBasicAuth(url.QueryEscape(cc.clientID), url.QueryEscape(cc.clientSecret))
+	}
+
+	return request, nil
+}
+
+// Token obtains a new token from the token endpoint.  If the endpoint responds with
+// an error, an *OAuth2Error is returned.
+func (cc *ClientCredentials) Token(ctx context.Context) (Token, error) {
+	request, err := cc.newRequest(ctx)
+	if err != nil {
+		return Token{}, err
+	}
+
+	start := cc.now()
+	response, err := cc.client.Do(request)
+	if err != nil {
+		return Token{}, err
+	}
+
+	defer httpaux.Cleanup(response)
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxTokenResponse))
+	if err != nil {
+		return Token{}, err
+	}
+
+	var tr tokenResponse
+	jsonErr := json.Unmarshal(body, &tr)
+
+	switch {
+	case response.StatusCode < 200 || response.StatusCode > 299 || len(tr.Error) > 0:
+		return Token{}, &OAuth2Error{
+			StatusCode:  response.StatusCode,
+			Code:        tr.Error,
+			Description: tr.ErrorDescription,
+			URI:         tr.ErrorURI,
+		}
+
+	case jsonErr != nil:
+		return Token{}, fmt.Errorf("unable to parse token response: %w", jsonErr)
+
+	case len(tr.AccessToken) == 0:
+		return Token{}, &OAuth2Error{
+			StatusCode:  response.StatusCode,
+			Description: "token response did not contain an access_token",
+		}
+	}
+
+	t := Token{
+		AccessToken: tr.AccessToken,
+	}
+
+	if tr.ExpiresIn > 0 {
+		// measure from when the request was sent, to be conservative
+		t.Expiry = start.Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+
+	return t, nil
+}
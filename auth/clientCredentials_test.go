@@ -0,0 +1,268 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/clock"
+	"github.com/xmidt-org/httpaux/httpmock"
+)
+
+type ClientCredentialsTestSuite struct {
+	suite.Suite
+
+	clock *clock.Fake
+
+	// handler is the current token endpoint behavior
+	handler http.HandlerFunc
+	server  *httptest.Server
+}
+
+func (suite *ClientCredentialsTestSuite) SetupSuite() {
+	suite.server = httptest.NewServer(
+		http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			suite.handler(rw, r)
+		}),
+	)
+}
+
+func (suite *ClientCredentialsTestSuite) TearDownSuite() {
+	suite.server.Close()
+}
+
+func (suite *ClientCredentialsTestSuite) SetupTest() {
+	suite.clock = clock.NewFake(time.Date(2021, time.March, 4, 10, 30, 0, 0, time.UTC))
+}
+
+func (suite *ClientCredentialsTestSuite) newClientCredentials(cfg ClientCredentialsConfig) *ClientCredentials {
+	cfg.TokenURL = suite.server.URL + "/token"
+	cfg.Clock = suite.clock
+	cc := NewClientCredentials(cfg)
+	suite.Require().NotNil(cc)
+	return cc
+}
+
+// respond sets up the token endpoint to write a fixed response and capture the form
+func (suite *ClientCredentialsTestSuite) respond(statusCode int, body string, form *url.Values, r **http.Request) {
+	suite.handler = func(rw http.ResponseWriter, request *http.Request) {
+		suite.NoError(request.ParseForm())
+		if form != nil {
+			*form = request.PostForm
+		}
+
+		if r != nil {
+			*r = request
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(statusCode)
+		io.WriteString(rw, body)
+	}
+}
+
+func (suite *ClientCredentialsTestSuite) TestHeaderAuth() {
+	var (
+		form    url.Values
+		request *http.Request
+		cc      = suite.newClientCredentials(ClientCredentialsConfig{
+			ClientID:     "client id",
+			ClientSecret: "secret&more",
+			Scopes:       []string{"read", "write"},
+			Params:       map[string][]string{"audience": {"api"}},
+		})
+	)
+
+	suite.respond(http.StatusOK, `{"access_token":"test","token_type":"Bearer","expires_in":3600}`, &form, &request)
+	t, err := cc.Token(context.Background())
+	suite.Require().NoError(err)
+	suite.Equal(
+		Token{
+			AccessToken: "test",
+			Expiry:      suite.clock.Now().Add(time.Hour),
+		},
+		t,
+	)
+
+	suite.Equal("POST", request.Method)
+	suite.Equal("/token", request.URL.Path)
+	suite.Equal("application/json", request.Header.Get("Accept"))
+
+	id, secret, ok := request.BasicAuth()
+	suite.True(ok)
+	suite.Equal("client+id", id)
+	suite.Equal("secret%26more", secret)
+
+	suite.Equal(
+		url.Values{
+			"grant_type": {"client_credentials"},
+			"scope":      {"read write"},
+			"audience":   {"api"},
+		},
+		form,
+	)
+}
+
+func (suite *ClientCredentialsTestSuite) TestBodyAuth() {
+	var (
+		form    url.Values
+		request *http.Request
+		cc      = suite.newClientCredentials(ClientCredentialsConfig{
+			ClientID:     "id",
+			ClientSecret: "secret",
+			AuthStyle:    AuthStyleBody,
+		})
+	)
+
+	// some servers send expires_in as a string, and some don't send it at all
+	suite.respond(http.StatusOK, `{"access_token":"test","expires_in":"60"}`, &form, &request)
+	t, err := cc.Token(context.Background())
+	suite.Require().NoError(err)
+	suite.Equal(suite.clock.Now().Add(time.Minute), t.Expiry)
+
+	_, _, ok := request.BasicAuth()
+	suite.False(ok)
+	suite.Equal(
+		url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {"id"},
+			"client_secret": {"secret"},
+		},
+		form,
+	)
+
+	suite.respond(http.StatusOK, `{"access_token":"test"}`, nil, nil)
+	t, err = cc.Token(context.Background())
+	suite.Require().NoError(err)
+	suite.True(t.Expiry.IsZero())
+}
+
+func (suite *ClientCredentialsTestSuite) TestErrors() {
+	testCases := []struct {
+		name       string
+		statusCode int
+		body       string
+		expected   *OAuth2Error
+	}{
+		{
+			name:       "OAuth2Error",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"error":"invalid_client","error_description":"bad secret","error_uri":"https://example.com/help"}`,
+			expected: &OAuth2Error{
+				StatusCode:  http.StatusUnauthorized,
+				Code:        "invalid_client",
+				Description: "bad secret",
+				URI:         "https://example.com/help",
+			},
+		},
+		{
+			name:       "NotJSON",
+			statusCode: http.StatusBadGateway,
+			body:       `<html>bad gateway</html>`,
+			expected: &OAuth2Error{
+				StatusCode: http.StatusBadGateway,
+			},
+		},
+		{
+			name:       "ErrorWithOK",
+			statusCode: http.StatusOK,
+			body:       `{"error":"invalid_scope"}`,
+			expected: &OAuth2Error{
+				StatusCode: http.StatusOK,
+				Code:       "invalid_scope",
+			},
+		},
+		{
+			name:       "MissingAccessToken",
+			statusCode: http.StatusOK,
+			body:       `{"token_type":"Bearer"}`,
+			expected: &OAuth2Error{
+				StatusCode:  http.StatusOK,
+				Description: "token response did not contain an access_token",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			cc := suite.newClientCredentials(ClientCredentialsConfig{})
+			suite.respond(testCase.statusCode, testCase.body, nil, nil)
+
+			_, err := cc.Token(context.Background())
+			var actual *OAuth2Error
+			suite.Require().ErrorAs(err, &actual)
+			suite.Equal(testCase.expected, actual)
+			suite.NotEmpty(actual.Error())
+		})
+	}
+}
+
+func (suite *ClientCredentialsTestSuite) TestInvalidJSON() {
+	cc := suite.newClientCredentials(ClientCredentialsConfig{})
+	suite.respond(http.StatusOK, `{"access_token":`, nil, nil)
+	_, err := cc.Token(context.Background())
+	suite.Error(err)
+
+	suite.respond(http.StatusOK, `{"access_token":"test","expires_in":"soon"}`, nil, nil)
+	_, err = cc.Token(context.Background())
+	suite.Error(err)
+}
+
+func (suite *ClientCredentialsTestSuite) TestClientError() {
+	var (
+		expectedErr = errors.New("expected")
+		rt          = httpmock.NewRoundTripperSuite(suite)
+		cc          = NewClientCredentials(ClientCredentialsConfig{
+			TokenURL: "http://example.com/token",
+			Client:   &http.Client{Transport: rt},
+		})
+	)
+
+	rt.OnAny().Return(nil, expectedErr).Once()
+	_, err := cc.Token(context.Background())
+	suite.ErrorIs(err, expectedErr)
+	rt.AssertExpectations()
+}
+
+func (suite *ClientCredentialsTestSuite) TestBadURL() {
+	cc := NewClientCredentials(ClientCredentialsConfig{
+		TokenURL: "this is not a valid URL\x7f",
+	})
+
+	_, err := cc.Token(context.Background())
+	suite.Error(err)
+}
+
+func (suite *ClientCredentialsTestSuite) TestWithCache() {
+	var (
+		calls = 0
+		cc    = suite.newClientCredentials(ClientCredentialsConfig{})
+		cache = NewCache(cc, CacheConfig{Clock: suite.clock})
+	)
+
+	suite.handler = func(rw http.ResponseWriter, _ *http.Request) {
+		calls++
+		io.WriteString(rw, `{"access_token":"test","expires_in":60}`)
+	}
+
+	for i := 0; i < 3; i++ {
+		t, err := cache.Token(context.Background())
+		suite.Require().NoError(err)
+		suite.Equal("test", t.AccessToken)
+	}
+
+	suite.Equal(1, calls)
+}
+
+func TestClientCredentials(t *testing.T) {
+	suite.Run(t, new(ClientCredentialsTestSuite))
+}
@@ -0,0 +1,22 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package auth provides clientside authentication middleware.
+
+Bearer tokens come from a TokenSource.  A Cache wraps another TokenSource so that a token
+is reused until shortly before it expires, and so that concurrent requests share a single
+refresh:
+
+	source := auth.NewCache(mySource, auth.CacheConfig{})
+
+	c := client.NewChain(auth.NewBearerClient(source)).Then(new(http.Client))
+	rt := roundtrip.NewChain(auth.NewBearerRoundTripper(source)).Then(http.DefaultTransport)
+
+NewClientCredentials creates a TokenSource that implements the OAuth2 client_credentials grant
+against a token endpoint.  It obtains a new token on every call, so it is normally wrapped in a Cache.
+
+If a server responds with 401 Unauthorized, the bearer middleware obtains a fresh token and
+sends the request once more.  This requires GetBody on requests with bodies.
+*/
+package auth
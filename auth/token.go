@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Token is an access token together with its expiry.
+type Token struct {
+	// AccessToken is the opaque token value sent to servers.
+	AccessToken string
+
+	// Expiry is the time at which this token expires.  A zero value means
+	// this token does not expire.
+	Expiry time.Time
+}
+
+// Valid tests if this token is usable at the given time, allowing for the given leeway.
+// A token is usable if it is nonempty and does not expire within leeway of now.
+func (t Token) Valid(now time.Time, leeway time.Duration) bool {
+	return len(t.AccessToken) > 0 && (t.Expiry.IsZero() || now.Add(leeway).Before(t.Expiry))
+}
+
+// TokenSource is a strategy for obtaining access tokens.
+type TokenSource interface {
+	// Token returns an access token.  Implementations may or may not cache tokens.
+	Token(context.Context) (Token, error)
+}
+
+// TokenSourceFunc is a function type that implements TokenSource.
+type TokenSourceFunc func(context.Context) (Token, error)
+
+// Token invokes this function.
+func (tsf TokenSourceFunc) Token(ctx context.Context) (Token, error) {
+	return tsf(ctx)
+}
+
+var _ TokenSource = TokenSourceFunc(nil)
+
+// StaticTokenSource returns a TokenSource that always returns the given access token,
+// which never expires.
+func StaticTokenSource(accessToken string) TokenSource {
+	return TokenSourceFunc(func(context.Context) (Token, error) {
+		return Token{AccessToken: accessToken}, nil
+	})
+}
+
+// Invalidator is an optional interface for TokenSource implementations that cache tokens.
+// Bearer middleware uses this interface to discard a token that a server rejected.
+type Invalidator interface {
+	// Invalidate discards the given token, if it is the currently cached token.
+	// The next call to Token will obtain a new token.
+	Invalidate(Token)
+}
+
+// TokenError indicates that a TokenSource could not produce a token.  Requests
+// are not sent when this happens.
+type TokenError struct {
+	// Err is the error returned from the TokenSource
+	Err error
+}
+
+// Unwrap returns the actual error returned from the TokenSource.
+func (err *TokenError) Unwrap() error {
+	return err.Err
+}
+
+// Error fulfills the error interface.
+func (err *TokenError) Error() string {
+	var o strings.Builder
+	o.WriteString("unable to obtain token: [")
+	o.WriteString(err.Err.Error())
+	o.WriteRune(']')
+	return o.String()
+}
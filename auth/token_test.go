@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TokenTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TokenTestSuite) TestValid() {
+	now := time.Date(2021, time.March, 4, 10, 30, 0, 0, time.UTC)
+
+	suite.False(Token{}.Valid(now, 0))
+	suite.True(Token{AccessToken: "test"}.Valid(now, time.Hour))
+
+	t := Token{AccessToken: "test", Expiry: now.Add(time.Minute)}
+	suite.True(t.Valid(now, 0))
+	suite.True(t.Valid(now, 59*time.Second))
+	suite.False(t.Valid(now, time.Minute))
+	suite.False(t.Valid(now.Add(time.Hour), 0))
+}
+
+func (suite *TokenTestSuite) TestStaticTokenSource() {
+	t, err := StaticTokenSource("test").Token(context.Background())
+	suite.NoError(err)
+	suite.Equal(Token{AccessToken: "test"}, t)
+}
+
+func (suite *TokenTestSuite) TestTokenError() {
+	expectedErr := errors.New("expected")
+	err := &TokenError{Err: expectedErr}
+	suite.ErrorIs(err, expectedErr)
+	suite.Contains(err.Error(), "expected")
+}
+
+func TestToken(t *testing.T) {
+	suite.Run(t, new(TokenTestSuite))
+}
@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package balancer
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xmidt-org/httpaux/gate"
+)
+
+// BackendConfig describes a single backend.
+type BackendConfig struct {
+	// URL is the base URL of this backend.  Its scheme and host replace those of each
+	// request sent to this backend.  If it has a path, that path is prepended to each
+	// request's path.
+	URL string `json:"url" yaml:"url"`
+
+	// Gate is the optional gate for this backend.  When the gate is closed, this
+	// backend is unavailable and can be drained.
+	Gate gate.Status `json:"-" yaml:"-"`
+}
+
+// Backend is the runtime state of a single backend.  All methods are safe for concurrent use.
+type Backend struct {
+	url  *url.URL
+	gate gate.Status
+	now  func() time.Time
+
+	outstanding  atomic.Int64
+	failures     atomic.Int64
+	ejectedUntil atomic.Int64 // UnixNano, or zero if never ejected
+}
+
+// URL returns the base URL of this backend.  Callers must not modify the returned URL.
+func (b *Backend) URL() *url.URL {
+	return b.url
+}
+
+// String returns the base URL of this backend.
+func (b *Backend) String() string {
+	return b.url.String()
+}
+
+// Gate returns the gate.Status for this backend, which will be nil if no gate was configured.
+func (b *Backend) Gate() gate.Status {
+	return b.gate
+}
+
+// Outstanding returns the number of requests to this backend that are in progress.
+// A request remains outstanding until its response body is closed or its round trip fails.
+func (b *Backend) Outstanding() int64 {
+	return b.outstanding.Load()
+}
+
+// outstandingBody is a response body that ends its request's outstanding count
+// when it is closed.
+type outstandingBody struct {
+	io.ReadCloser
+	backend *Backend
+	once    sync.Once
+}
+
+func (ob *outstandingBody) Close() error {
+	ob.once.Do(func() {
+		ob.backend.outstanding.Add(-1)
+	})
+
+	return ob.ReadCloser.Close()
+}
+
+// release arranges for the outstanding count to be decremented once the given response
+// is finished.  If there is no response body, the count is decremented immediately.
+//
+// The body of a 101 Switching Protocols response is never wrapped, since it is an
+// io.ReadWriteCloser that callers need to see.  An upgraded connection is no longer
+// an outstanding request.
+func (b *Backend) release(response *http.Response) {
+	if response == nil || response.Body == nil || response.Body == http.NoBody ||
+		response.StatusCode == http.StatusSwitchingProtocols {
+		b.outstanding.Add(-1)
+		return
+	}
+
+	response.Body = &outstandingBody{
+		ReadCloser: response.Body,
+		backend:    b,
+	}
+}
+
+// Ejected tests if this backend has been ejected due to failures and its cooldown
+// has not elapsed.
+func (b *Backend) Ejected() bool {
+	until := b.ejectedUntil.Load()
+	return until != 0 && b.now().UnixNano() < until
+}
+
+// Available tests if this backend should receive traffic.  A backend is available if it
+// is not ejected and its gate, if any, is open.
+func (b *Backend) Available() bool {
+	return !b.Ejected() && (b.gate == nil || b.gate.IsOpen())
+}
+
+// result records the outcome of a round trip.  Once a backend has the given number of
+// consecutive failures, it is ejected for the cooldown.
+func (b *Backend) result(failed bool, consecutiveFailures int64, cooldown time.Duration) {
+	if !failed {
+		b.failures.Store(0)
+		return
+	}
+
+	if b.failures.Add(1) >= consecutiveFailures {
+		b.failures.Store(0)
+		b.ejectedUntil.Store(b.now().Add(cooldown).UnixNano())
+	}
+}
+
+// rewrite produces the request sent to this backend.  The original request is not modified.
+func (b *Backend) rewrite(request *http.Request) *http.Request {
+	outgoing := request.Clone(request.Context())
+	if len(request.Host) == 0 || request.Host == request.URL.Host {
+		// only rewrite the Host header if the caller didn't customize it
+		outgoing.Host = b.url.Host
+	}
+
+	u := *request.URL
+	u.Scheme = b.url.Scheme
+	u.Host = b.url.Host
+	if len(b.url.Path) > 0 && b.url.Path != "/" {
+		u.Path = strings.TrimSuffix(b.url.Path, "/") + "/" + strings.TrimPrefix(u.Path, "/")
+		u.RawPath = ""
+	}
+
+	outgoing.URL = &u
+	return outgoing
+}
@@ -0,0 +1,190 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package balancer
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/clock"
+	"github.com/xmidt-org/httpaux/gate"
+	"github.com/xmidt-org/httpaux/httpmock"
+)
+
+type BackendTestSuite struct {
+	suite.Suite
+
+	clock *clock.Fake
+}
+
+func (suite *BackendTestSuite) SetupTest() {
+	suite.clock = clock.NewFake(time.Now())
+}
+
+func (suite *BackendTestSuite) newBackend(rawURL string, g gate.Status) *Backend {
+	u, err := url.Parse(rawURL)
+	suite.Require().NoError(err)
+	return &Backend{
+		url:  u,
+		gate: g,
+		now:  suite.clock.Now,
+	}
+}
+
+func (suite *BackendTestSuite) TestAccessors() {
+	g := gate.New(gate.Config{Name: "test"})
+	b := suite.newBackend("https://a.example.com", g)
+	suite.Equal("https://a.example.com", b.String())
+	suite.Equal("a.example.com", b.URL().Host)
+	suite.Same(g, b.Gate())
+	suite.Zero(b.Outstanding())
+	suite.False(b.Ejected())
+	suite.True(b.Available())
+
+	g.Close()
+	suite.False(b.Available())
+	g.Open()
+	suite.True(b.Available())
+}
+
+func (suite *BackendTestSuite) TestResult() {
+	b := suite.newBackend("https://a.example.com", nil)
+
+	b.result(true, 2, time.Minute)
+	suite.False(b.Ejected())
+
+	// a success resets the consecutive failures
+	b.result(false, 2, time.Minute)
+	b.result(true, 2, time.Minute)
+	suite.False(b.Ejected())
+
+	b.result(true, 2, time.Minute)
+	suite.True(b.Ejected())
+	suite.False(b.Available())
+
+	suite.clock.Add(59 * time.Second)
+	suite.True(b.Ejected())
+
+	suite.clock.Add(time.Second)
+	suite.False(b.Ejected())
+	suite.True(b.Available())
+}
+
+func (suite *BackendTestSuite) TestRewrite() {
+	testCases := []struct {
+		name         string
+		backend      string
+		target       string
+		host         string
+		expectedURL  string
+		expectedHost string
+	}{
+		{
+			name:         "Simple",
+			backend:      "https://a.example.com:8443",
+			target:       "http://service/path?q=1",
+			expectedURL:  "https://a.example.com:8443/path?q=1",
+			expectedHost: "a.example.com:8443",
+		},
+		{
+			name:         "PathPrefix",
+			backend:      "https://a.example.com/api/",
+			target:       "http://service/path",
+			expectedURL:  "https://a.example.com/api/path",
+			expectedHost: "a.example.com",
+		},
+		{
+			name:         "CustomHost",
+			backend:      "https://a.example.com",
+			target:       "http://service/path",
+			host:         "custom.example.com",
+			expectedURL:  "https://a.example.com/path",
+			expectedHost: "custom.example.com",
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			request, err := http.NewRequest("GET", testCase.target, nil)
+			suite.Require().NoError(err)
+			if len(testCase.host) > 0 {
+				request.Host = testCase.host
+			}
+
+			original := request.URL.String()
+			outgoing := suite.newBackend(testCase.backend, nil).rewrite(request)
+			suite.Equal(testCase.expectedURL, outgoing.URL.String())
+			suite.Equal(testCase.expectedHost, outgoing.Host)
+			suite.Equal(original, request.URL.String())
+		})
+	}
+}
+
+func (suite *BackendTestSuite) TestRelease() {
+	suite.Run("Body", func() {
+		var (
+			b        = suite.newBackend("https://a.example.com", nil)
+			body     = httpmock.BodyString("body")
+			response = &http.Response{StatusCode: http.StatusOK, Body: body}
+		)
+
+		b.outstanding.Add(1)
+		b.release(response)
+		suite.Equal(int64(1), b.Outstanding())
+
+		suite.NoError(response.Body.Close())
+		suite.True(body.Closed())
+		suite.Zero(b.Outstanding())
+
+		// closing again must not decrement again
+		response.Body.Close()
+		suite.Zero(b.Outstanding())
+	})
+
+	testCases := []struct {
+		name     string
+		response *http.Response
+	}{
+		{
+			name: "NilResponse",
+		},
+		{
+			name:     "NilBody",
+			response: &http.Response{StatusCode: http.StatusOK},
+		},
+		{
+			name:     "NoBody",
+			response: &http.Response{StatusCode: http.StatusOK, Body: http.NoBody},
+		},
+		{
+			name:     "SwitchingProtocols",
+			response: &http.Response{StatusCode: http.StatusSwitchingProtocols, Body: httpmock.EmptyBody()},
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			b := suite.newBackend("https://a.example.com", nil)
+			var expectedBody io.ReadCloser
+			if testCase.response != nil {
+				expectedBody = testCase.response.Body
+			}
+
+			b.outstanding.Add(1)
+			b.release(testCase.response)
+			suite.Zero(b.Outstanding())
+			if testCase.response != nil {
+				suite.Equal(expectedBody, testCase.response.Body)
+			}
+		})
+	}
+}
+
+func TestBackend(t *testing.T) {
+	suite.Run(t, new(BackendTestSuite))
+}
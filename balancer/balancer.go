@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package balancer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/xmidt-org/httpaux/breaker"
+	"github.com/xmidt-org/httpaux/clock"
+	"github.com/xmidt-org/httpaux/retry"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+const (
+	// DefaultConsecutiveFailures is the number of consecutive failures that eject a backend
+	// when no positive value is configured.
+	DefaultConsecutiveFailures = 1
+
+	// DefaultCooldown is how long a backend stays ejected when no positive cooldown is configured.
+	DefaultCooldown = 30 * time.Second
+)
+
+var (
+	// ErrNoBackends indicates that a Config had no backends.
+	ErrNoBackends = errors.New("at least one backend is required")
+
+	// ErrNoAvailableBackend is returned from round trips when the Picker could not choose
+	// a backend, e.g. because all backends are ejected or drained.
+	ErrNoAvailableBackend = errors.New("no available backend")
+)
+
+// Config is the set of configuration options for a Balancer.
+type Config struct {
+	// Backends are the equivalent backends that requests are balanced across.
+	// At least one backend is required.
+	Backends []BackendConfig `json:"backends" yaml:"backends"`
+
+	// Picker is the strategy for choosing backends.  If unset, RoundRobin is used.
+	Picker Picker `json:"-" yaml:"-"`
+
+	// Check determines whether a round trip failed.  If unset, breaker.DefaultCheck is used.
+	Check retry.Check `json:"-" yaml:"-"`
+
+	// ConsecutiveFailures is the number of consecutive failures that eject a backend.
+	// If nonpositive, DefaultConsecutiveFailures is used.
+	ConsecutiveFailures int `json:"consecutiveFailures" yaml:"consecutiveFailures"`
+
+	// Cooldown is how long an ejected backend is unavailable.  If nonpositive,
+	// DefaultCooldown is used.
+	Cooldown time.Duration `json:"cooldown" yaml:"cooldown"`
+
+	// Clock is the optional source of time for ejection.  If unset, clock.System is used.
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+// Balancer distributes requests across backends.  A Balancer may decorate any number
+// of round trippers, which will share its backend state.
+type Balancer struct {
+	backends            []*Backend
+	picker              Picker
+	check               retry.Check
+	consecutiveFailures int64
+	cooldown            time.Duration
+}
+
+// New creates a Balancer from the given configuration.  An error is returned if there
+// are no backends or if any backend URL is not an absolute URL.
+func New(cfg Config) (*Balancer, error) {
+	if len(cfg.Backends) == 0 {
+		return nil, ErrNoBackends
+	}
+
+	b := &Balancer{
+		backends:            make([]*Backend, 0, len(cfg.Backends)),
+		picker:              cfg.Picker,
+		check:               cfg.Check,
+		consecutiveFailures: int64(cfg.ConsecutiveFailures),
+		cooldown:            cfg.Cooldown,
+	}
+
+	if b.picker == nil {
+		b.picker = RoundRobin()
+	}
+
+	if b.check == nil {
+		b.check = breaker.DefaultCheck
+	}
+
+	if b.consecutiveFailures < 1 {
+		b.consecutiveFailures = DefaultConsecutiveFailures
+	}
+
+	if b.cooldown <= 0 {
+		b.cooldown = DefaultCooldown
+	}
+
+	now := clock.OrSystem(cfg.Clock).Now
+	for _, bc := range cfg.Backends {
+		u, err := url.Parse(bc.URL)
+		if err != nil {
+			return nil, err
+		} else if len(u.Scheme) == 0 || len(u.Host) == 0 {
+			return nil, fmt.Errorf("backend URL [%s] must be absolute", bc.URL)
+		}
+
+		b.backends = append(b.backends, &Backend{
+			url:  u,
+			gate: bc.Gate,
+			now:  now,
+		})
+	}
+
+	return b, nil
+}
+
+// Backends returns the backends of this Balancer, in their configured order.
+// The returned slice is a copy, but the backends themselves are shared.
+func (b *Balancer) Backends() []*Backend {
+	return append([]*Backend(nil), b.backends...)
+}
+
+// Then decorates a round tripper so that each request is sent to a backend chosen by this
+// Balancer.  This method is a roundtrip.Constructor.
+//
+// A request counts toward its backend's Outstanding until the response body is closed,
+// so callers must always close response bodies.
+//
+// The returned http.RoundTripper preserves the CloseIdleConnections method of next.
+// If next is nil, http.DefaultTransport is decorated.
+func (b *Balancer) Then(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return roundtrip.PreserveCloseIdler(
+		next,
+		roundtrip.Func(func(request *http.Request) (*http.Response, error) {
+			backend := b.picker.Pick(request, b.backends)
+			if backend == nil {
+				if request.Body != nil {
+					request.Body.Close()
+				}
+
+				return nil, ErrNoAvailableBackend
+			}
+
+			backend.outstanding.Add(1)
+			response, err := next.RoundTrip(backend.rewrite(request))
+			backend.result(b.check(response, err), b.consecutiveFailures, b.cooldown)
+			backend.release(response)
+			return response, err
+		}),
+	)
+}
@@ -0,0 +1,313 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package balancer
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/clock"
+	"github.com/xmidt-org/httpaux/gate"
+	"github.com/xmidt-org/httpaux/httpmock"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+type BalancerTestSuite struct {
+	suite.Suite
+
+	clock *clock.Fake
+}
+
+func (suite *BalancerTestSuite) SetupTest() {
+	suite.clock = clock.NewFake(time.Now())
+}
+
+func (suite *BalancerTestSuite) newRequest() *http.Request {
+	request, err := http.NewRequest("GET", "http://service/path", nil)
+	suite.Require().NoError(err)
+	return request
+}
+
+// expectHost sets up an expected round trip to the given host
+func (suite *BalancerTestSuite) expectHost(next *httpmock.RoundTripper, host string, statusCode int, err error) {
+	var response *http.Response
+	if err == nil {
+		response = &http.Response{StatusCode: statusCode, Body: httpmock.EmptyBody()}
+	}
+
+	next.OnMatchAll(
+		httpmock.RequestMatcherFunc(func(r *http.Request) bool { return r.URL.Host == host }),
+	).Return(response, err).Once()
+}
+
+func (suite *BalancerTestSuite) roundTrip(rt http.RoundTripper) (*http.Response, error) {
+	response, err := rt.RoundTrip(suite.newRequest())
+	if response != nil {
+		response.Body.Close()
+	}
+
+	return response, err
+}
+
+func (suite *BalancerTestSuite) TestNew() {
+	suite.Run("NoBackends", func() {
+		b, err := New(Config{})
+		suite.ErrorIs(err, ErrNoBackends)
+		suite.Nil(b)
+	})
+
+	suite.Run("InvalidURL", func() {
+		b, err := New(Config{Backends: []BackendConfig{{URL: "http://bad url:%"}}})
+		suite.Error(err)
+		suite.Nil(b)
+	})
+
+	suite.Run("RelativeURL", func() {
+		b, err := New(Config{Backends: []BackendConfig{{URL: "/relative"}}})
+		suite.Error(err)
+		suite.Nil(b)
+	})
+
+	suite.Run("Defaults", func() {
+		b, err := New(Config{
+			Backends: []BackendConfig{{URL: "http://a"}, {URL: "http://b"}},
+		})
+
+		suite.Require().NoError(err)
+		suite.Equal(int64(DefaultConsecutiveFailures), b.consecutiveFailures)
+		suite.Equal(DefaultCooldown, b.cooldown)
+		suite.NotNil(b.picker)
+		suite.NotNil(b.check)
+
+		backends := b.Backends()
+		suite.Require().Len(backends, 2)
+		suite.Equal("http://a", backends[0].String())
+		suite.Equal("http://b", backends[1].String())
+		suite.NotNil(b.Then(nil))
+	})
+}
+
+func (suite *BalancerTestSuite) TestThen() {
+	b, err := New(Config{
+		Backends: []BackendConfig{{URL: "https://a:8443"}, {URL: "https://b:8443"}},
+	})
+
+	suite.Require().NoError(err)
+	next := httpmock.NewRoundTripperSuite(suite)
+	next.AssertRequest(
+		httpmock.RequestAsserterFunc(func(assert *assert.Assertions, r *http.Request) {
+			assert.Equal("https", r.URL.Scheme)
+			assert.Equal(r.URL.Host, r.Host)
+		}),
+	)
+
+	suite.expectHost(next, "a:8443", http.StatusOK, nil)
+	suite.expectHost(next, "b:8443", http.StatusOK, nil)
+
+	rt := roundtrip.NewChain(b.Then).Then(next)
+	for i := 0; i < 2; i++ {
+		request := suite.newRequest()
+		response, err := rt.RoundTrip(request)
+		suite.Require().NoError(err)
+		response.Body.Close()
+		suite.Equal("http://service/path", request.URL.String())
+	}
+
+	next.AssertExpectations()
+	for _, backend := range b.Backends() {
+		suite.Zero(backend.Outstanding())
+	}
+}
+
+func (suite *BalancerTestSuite) TestOutstanding() {
+	expectedErr := errors.New("expected")
+	b, err := New(Config{
+		Backends: []BackendConfig{{URL: "http://a"}},
+		Check:    func(*http.Response, error) bool { return false },
+	})
+
+	suite.Require().NoError(err)
+	next := httpmock.NewRoundTripperSuite(suite)
+	rt := b.Then(next)
+	backend := b.Backends()[0]
+
+	suite.expectHost(next, "a", http.StatusOK, nil)
+	suite.expectHost(next, "a", http.StatusOK, nil)
+	suite.expectHost(next, "a", 0, expectedErr)
+
+	// a request remains outstanding until its response body is closed
+	first, err := rt.RoundTrip(suite.newRequest())
+	suite.Require().NoError(err)
+	suite.Equal(int64(1), backend.Outstanding())
+
+	second, err := rt.RoundTrip(suite.newRequest())
+	suite.Require().NoError(err)
+	suite.Equal(int64(2), backend.Outstanding())
+
+	first.Body.Close()
+	suite.Equal(int64(1), backend.Outstanding())
+	second.Body.Close()
+	suite.Zero(backend.Outstanding())
+
+	// a failed round trip is no longer outstanding
+	_, err = rt.RoundTrip(suite.newRequest()) //nolint:bodyclose
+	suite.ErrorIs(err, expectedErr)
+	suite.Zero(backend.Outstanding())
+	next.AssertExpectations()
+}
+
+func (suite *BalancerTestSuite) TestEjection() {
+	b, err := New(Config{
+		Backends: []BackendConfig{{URL: "http://a"}, {URL: "http://b"}},
+		Cooldown: time.Minute,
+		Clock:    suite.clock,
+	})
+
+	suite.Require().NoError(err)
+	next := httpmock.NewRoundTripperSuite(suite)
+	rt := b.Then(next)
+
+	// a fails, which ejects it, so b gets all the traffic
+	suite.expectHost(next, "a", http.StatusServiceUnavailable, nil)
+	suite.expectHost(next, "b", http.StatusOK, nil)
+	suite.expectHost(next, "b", http.StatusOK, nil)
+	suite.expectHost(next, "b", http.StatusOK, nil)
+	for i := 0; i < 4; i++ {
+		suite.roundTrip(rt)
+	}
+
+	next.AssertExpectations()
+	suite.True(b.Backends()[0].Ejected())
+
+	// once the cooldown elapses, a returns
+	suite.clock.Add(time.Minute)
+	suite.False(b.Backends()[0].Ejected())
+	suite.expectHost(next, "a", http.StatusOK, nil)
+	suite.expectHost(next, "b", http.StatusOK, nil)
+	suite.roundTrip(rt)
+	suite.roundTrip(rt)
+	next.AssertExpectations()
+}
+
+func (suite *BalancerTestSuite) TestConsecutiveFailures() {
+	expectedErr := errors.New("expected")
+	b, err := New(Config{
+		Backends:            []BackendConfig{{URL: "http://a"}},
+		ConsecutiveFailures: 2,
+		Clock:               suite.clock,
+	})
+
+	suite.Require().NoError(err)
+	next := httpmock.NewRoundTripperSuite(suite)
+	rt := b.Then(next)
+
+	suite.expectHost(next, "a", 0, expectedErr)
+	suite.expectHost(next, "a", http.StatusOK, nil)
+	suite.expectHost(next, "a", 0, expectedErr)
+	suite.expectHost(next, "a", 0, expectedErr)
+
+	_, err = suite.roundTrip(rt)
+	suite.ErrorIs(err, expectedErr)
+	_, err = suite.roundTrip(rt)
+	suite.NoError(err)
+	_, err = suite.roundTrip(rt)
+	suite.ErrorIs(err, expectedErr)
+	suite.False(b.Backends()[0].Ejected())
+	_, err = suite.roundTrip(rt)
+	suite.ErrorIs(err, expectedErr)
+	suite.True(b.Backends()[0].Ejected())
+
+	_, err = suite.roundTrip(rt)
+	suite.ErrorIs(err, ErrNoAvailableBackend)
+	next.AssertExpectations()
+}
+
+func (suite *BalancerTestSuite) TestCustomCheck() {
+	b, err := New(Config{
+		Backends: []BackendConfig{{URL: "http://a"}},
+		Check: func(r *http.Response, err error) bool {
+			return r != nil && r.StatusCode == http.StatusTooManyRequests
+		},
+	})
+
+	suite.Require().NoError(err)
+	next := httpmock.NewRoundTripperSuite(suite)
+	rt := b.Then(next)
+
+	suite.expectHost(next, "a", http.StatusInternalServerError, nil)
+	suite.roundTrip(rt)
+	suite.False(b.Backends()[0].Ejected())
+
+	suite.expectHost(next, "a", http.StatusTooManyRequests, nil)
+	suite.roundTrip(rt)
+	suite.True(b.Backends()[0].Ejected())
+	next.AssertExpectations()
+}
+
+func (suite *BalancerTestSuite) TestDrain() {
+	g := gate.New(gate.Config{Name: "a"})
+	b, err := New(Config{
+		Backends: []BackendConfig{{URL: "http://a", Gate: g}, {URL: "http://b"}},
+	})
+
+	suite.Require().NoError(err)
+	next := httpmock.NewRoundTripperSuite(suite)
+	rt := b.Then(next)
+
+	g.Close()
+	suite.expectHost(next, "b", http.StatusOK, nil)
+	suite.expectHost(next, "b", http.StatusOK, nil)
+	suite.roundTrip(rt)
+	suite.roundTrip(rt)
+	next.AssertExpectations()
+
+	g.Open()
+	suite.expectHost(next, "a", http.StatusOK, nil)
+	suite.expectHost(next, "b", http.StatusOK, nil)
+	suite.roundTrip(rt)
+	suite.roundTrip(rt)
+	next.AssertExpectations()
+}
+
+func (suite *BalancerTestSuite) TestNoAvailableBackend() {
+	b, err := New(Config{
+		Backends: []BackendConfig{{URL: "http://a"}},
+		Picker: PickerFunc(func(*http.Request, []*Backend) *Backend {
+			return nil
+		}),
+	})
+
+	suite.Require().NoError(err)
+	next := httpmock.NewRoundTripperSuite(suite)
+
+	body := httpmock.BodyString("content")
+	request, err := http.NewRequest("POST", "http://service/", body)
+	suite.Require().NoError(err)
+
+	response, err := b.Then(next).RoundTrip(request) //nolint:bodyclose
+	suite.ErrorIs(err, ErrNoAvailableBackend)
+	suite.Nil(response)
+	suite.True(body.Closed())
+	next.AssertExpectations()
+}
+
+func (suite *BalancerTestSuite) TestCloseIdleConnections() {
+	b, err := New(Config{
+		Backends: []BackendConfig{{URL: "http://a"}},
+	})
+
+	suite.Require().NoError(err)
+	next := &httpmock.CloseIdler{RoundTripper: httpmock.NewRoundTripperSuite(suite)}
+	next.OnCloseIdleConnections().Once()
+	roundtrip.CloseIdleConnections(b.Then(next))
+	next.AssertExpectations()
+}
+
+func TestBalancer(t *testing.T) {
+	suite.Run(t, new(BalancerTestSuite))
+}
@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package balancer implements client-side load balancing across equivalent backends.
+
+A Balancer rewrites each request's URL to point at one of its backends, as chosen by a
+Picker.  Built-in pickers implement round-robin, random, least-outstanding-requests, and
+power-of-two-choices strategies:
+
+	b, err := balancer.New(balancer.Config{
+	  Backends: []balancer.BackendConfig{
+	    {URL: "https://a.example.com"},
+	    {URL: "https://b.example.com"},
+	  },
+	  Picker: balancer.LeastOutstanding(),
+	})
+
+	c := &http.Client{
+	  Transport: roundtrip.NewChain(b.Then).Then(nil),
+	}
+
+Backends that fail, as judged by a retry.Check, are passively ejected for a cooldown period.
+Each backend can also have a gate.Status, so that it can be drained by closing its gate.
+Pickers can see both of these through Backend.Available.
+*/
+package balancer
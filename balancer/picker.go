@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package balancer
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"sync/atomic"
+)
+
+// Picker is the strategy for choosing the backend for a request.
+type Picker interface {
+	// Pick chooses a backend for the given request.  All of a Balancer's backends are
+	// passed, in their configured order, so that a Picker can make its own decisions about
+	// ejected backends or backends whose gates are closed.  Typically, only backends
+	// for which Available returns true should be chosen.
+	//
+	// If no backend is suitable, this method must return nil.
+	Pick(request *http.Request, backends []*Backend) *Backend
+}
+
+// PickerFunc is a function type that implements Picker.
+type PickerFunc func(*http.Request, []*Backend) *Backend
+
+func (pf PickerFunc) Pick(request *http.Request, backends []*Backend) *Backend {
+	return pf(request, backends)
+}
+
+// available returns the backends that are available.
+func available(backends []*Backend) (a []*Backend) {
+	a = make([]*Backend, 0, len(backends))
+	for _, b := range backends {
+		if b.Available() {
+			a = append(a, b)
+		}
+	}
+
+	return
+}
+
+// RoundRobin returns a Picker that cycles through the available backends.
+func RoundRobin() Picker {
+	var next atomic.Uint64
+	return PickerFunc(func(_ *http.Request, backends []*Backend) *Backend {
+		if len(backends) == 0 {
+			return nil
+		}
+
+		start := next.Add(1) - 1
+		for i := range uint64(len(backends)) {
+			if b := backends[(start+i)%uint64(len(backends))]; b.Available() {
+				return b
+			}
+		}
+
+		return nil
+	})
+}
+
+// Random returns a Picker that chooses uniformly among the available backends.  The intn
+// function returns a random integer in [0, n).  If intn is nil, math/rand/v2.IntN is used.
+func Random(intn func(n int) int) Picker {
+	if intn == nil {
+		intn = rand.IntN
+	}
+
+	return PickerFunc(func(_ *http.Request, backends []*Backend) *Backend {
+		a := available(backends)
+		if len(a) == 0 {
+			return nil
+		}
+
+		return a[intn(len(a))]
+	})
+}
+
+// LeastOutstanding returns a Picker that chooses the available backend with the fewest
+// outstanding round trips.  Ties are broken in round-robin fashion, so that traffic is
+// spread across backends even when there is little concurrency.
+func LeastOutstanding() Picker {
+	var next atomic.Uint64
+	return PickerFunc(func(_ *http.Request, backends []*Backend) (chosen *Backend) {
+		if len(backends) == 0 {
+			return nil
+		}
+
+		var least int64
+		start := next.Add(1) - 1
+		for i := range uint64(len(backends)) {
+			b := backends[(start+i)%uint64(len(backends))]
+			if !b.Available() {
+				continue
+			}
+
+			if outstanding := b.Outstanding(); chosen == nil || outstanding < least {
+				chosen, least = b, outstanding
+			}
+		}
+
+		return
+	})
+}
+
+// PowerOfTwoChoices returns a Picker that randomly chooses two distinct available backends
+// and picks the one with fewer outstanding round trips.  This approximates LeastOutstanding
+// without examining every backend.  The intn function returns a random integer in [0, n).
+// If intn is nil, math/rand/v2.IntN is used.
+func PowerOfTwoChoices(intn func(n int) int) Picker {
+	if intn == nil {
+		intn = rand.IntN
+	}
+
+	return PickerFunc(func(_ *http.Request, backends []*Backend) *Backend {
+		a := available(backends)
+		switch len(a) {
+		case 0:
+			return nil
+
+		case 1:
+			return a[0]
+		}
+
+		// choose two distinct indices
+		i := intn(len(a))
+		j := intn(len(a) - 1)
+		if j >= i {
+			j++
+		}
+
+		if a[j].Outstanding() < a[i].Outstanding() {
+			return a[j]
+		}
+
+		return a[i]
+	})
+}
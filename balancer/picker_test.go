@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package balancer
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/gate"
+)
+
+type PickerTestSuite struct {
+	suite.Suite
+
+	backends []*Backend
+	gates    []gate.Interface
+}
+
+func (suite *PickerTestSuite) SetupTest() {
+	suite.backends = nil
+	suite.gates = nil
+	for i := 0; i < 4; i++ {
+		g := gate.New(gate.Config{Name: strconv.Itoa(i)})
+		suite.gates = append(suite.gates, g)
+		suite.backends = append(suite.backends, &Backend{
+			url:  &url.URL{Scheme: "http", Host: "backend" + strconv.Itoa(i)},
+			gate: g,
+			now:  time.Now,
+		})
+	}
+}
+
+// pick invokes a picker, returning the index of the chosen backend or -1 if none was chosen
+func (suite *PickerTestSuite) pick(p Picker) int {
+	chosen := p.Pick(new(http.Request), suite.backends)
+	for i, b := range suite.backends {
+		if b == chosen {
+			return i
+		}
+	}
+
+	suite.Require().Nil(chosen)
+	return -1
+}
+
+// sequence returns a fake intn that yields the given values in order
+func sequence(values ...int) func(int) int {
+	return func(int) int {
+		v := values[0]
+		values = values[1:]
+		return v
+	}
+}
+
+func (suite *PickerTestSuite) TestRoundRobin() {
+	p := RoundRobin()
+	suite.Equal(0, suite.pick(p))
+	suite.Equal(1, suite.pick(p))
+	suite.Equal(2, suite.pick(p))
+	suite.Equal(3, suite.pick(p))
+	suite.Equal(0, suite.pick(p))
+
+	suite.gates[1].Close()
+	suite.Equal(2, suite.pick(p)) // start=1, but 1 is closed
+	suite.Equal(2, suite.pick(p)) // start=2
+	suite.Equal(3, suite.pick(p))
+
+	for _, g := range suite.gates {
+		g.Close()
+	}
+
+	suite.Equal(-1, suite.pick(p))
+	suite.Nil(p.Pick(new(http.Request), nil))
+}
+
+func (suite *PickerTestSuite) TestRandom() {
+	suite.gates[0].Close()
+	p := Random(sequence(0, 2))
+	suite.Equal(1, suite.pick(p))
+	suite.Equal(3, suite.pick(p))
+
+	for _, g := range suite.gates {
+		g.Close()
+	}
+
+	suite.Equal(-1, suite.pick(Random(nil)))
+}
+
+func (suite *PickerTestSuite) TestLeastOutstanding() {
+	p := LeastOutstanding()
+	suite.backends[0].outstanding.Store(2)
+	suite.backends[1].outstanding.Store(1)
+	suite.backends[2].outstanding.Store(3)
+	suite.backends[3].outstanding.Store(1)
+
+	// ties are broken by the rotating starting point
+	suite.Equal(1, suite.pick(p))
+	suite.Equal(1, suite.pick(p))
+	suite.Equal(3, suite.pick(p))
+	suite.Equal(3, suite.pick(p))
+
+	suite.gates[1].Close()
+	suite.gates[3].Close()
+	suite.Equal(0, suite.pick(p))
+
+	for _, g := range suite.gates {
+		g.Close()
+	}
+
+	suite.Equal(-1, suite.pick(p))
+	suite.Nil(p.Pick(new(http.Request), nil))
+}
+
+func (suite *PickerTestSuite) TestPowerOfTwoChoices() {
+	suite.backends[0].outstanding.Store(5)
+	suite.backends[1].outstanding.Store(1)
+	suite.backends[2].outstanding.Store(3)
+
+	suite.Equal(1, suite.pick(PowerOfTwoChoices(sequence(0, 0)))) // 0 and 1
+	suite.Equal(2, suite.pick(PowerOfTwoChoices(sequence(2, 0)))) // 2 and 0
+	suite.Equal(3, suite.pick(PowerOfTwoChoices(sequence(3, 2)))) // 3 and 2
+
+	for _, g := range suite.gates[1:] {
+		g.Close()
+	}
+
+	suite.Equal(0, suite.pick(PowerOfTwoChoices(nil)))
+
+	suite.gates[0].Close()
+	suite.Equal(-1, suite.pick(PowerOfTwoChoices(nil)))
+}
+
+func TestPicker(t *testing.T) {
+	suite.Run(t, new(PickerTestSuite))
+}
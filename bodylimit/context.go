@@ -0,0 +1,22 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package bodylimit
+
+import "context"
+
+// contextKey is the internal context.Context key that stores the per-request limit
+type contextKey struct{}
+
+// WithMaxSize returns a subcontext that overrides the configured limit for any request
+// that uses it.  A nonpositive value removes the limit for the request.
+func WithMaxSize(ctx context.Context, maxSize int64) context.Context {
+	return context.WithValue(ctx, contextKey{}, maxSize)
+}
+
+// MaxSizeFromContext returns the per-request limit carried by the given context.
+// If there is no limit, this function returns zero and false.
+func MaxSizeFromContext(ctx context.Context) (int64, bool) {
+	maxSize, ok := ctx.Value(contextKey{}).(int64)
+	return maxSize, ok
+}
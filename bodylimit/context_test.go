@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package bodylimit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ContextTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ContextTestSuite) TestMissing() {
+	maxSize, ok := MaxSizeFromContext(context.Background())
+	suite.Zero(maxSize)
+	suite.False(ok)
+}
+
+func (suite *ContextTestSuite) TestWithMaxSize() {
+	for _, expected := range []int64{-1, 0, 100} {
+		maxSize, ok := MaxSizeFromContext(
+			WithMaxSize(context.Background(), expected),
+		)
+
+		suite.Equal(expected, maxSize)
+		suite.True(ok)
+	}
+}
+
+func TestContext(t *testing.T) {
+	suite.Run(t, new(ContextTestSuite))
+}
@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package bodylimit provides client middleware that limits the size of response bodies.
+
+A misbehaving server can send an arbitrarily large response, which code that uses
+io.ReadAll will happily buffer.  The middleware in this package fails a request early
+when the response's Content-Length exceeds the limit, and otherwise wraps the response
+body so that reading past the limit returns a *TooLargeError:
+
+	c := client.NewChain(
+	  bodylimit.NewClient(bodylimit.Config{
+	    MaxSize: 1 << 20,
+	  }),
+	).Then(new(http.Client))
+
+The limit may be changed for individual requests with WithMaxSize:
+
+	request = request.WithContext(
+	  bodylimit.WithMaxSize(request.Context(), 100 << 20),
+	)
+*/
+package bodylimit
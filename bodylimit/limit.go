@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package bodylimit
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/xmidt-org/httpaux"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/internal/limit"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+// DefaultMaxSize is the largest response body allowed when no positive maximum is configured.
+const DefaultMaxSize int64 = 10 << 20
+
+// TooLargeError is returned when a response body exceeds the limit.  If the response's
+// Content-Length exceeded the limit, this error is returned from the round trip.  Otherwise,
+// this error is returned when reading the response body crosses the limit.
+type TooLargeError struct {
+	// Limit is the maximum number of bytes allowed in the response body.
+	Limit int64
+
+	// ContentLength is the response's declared Content-Length when this error
+	// was returned from the round trip.  This field is -1 when this error was
+	// returned while reading the body.
+	ContentLength int64
+}
+
+func (tle *TooLargeError) Error() string {
+	var o strings.Builder
+	o.WriteString("response body ")
+	if tle.ContentLength >= 0 {
+		o.WriteString("of ")
+		o.WriteString(strconv.FormatInt(tle.ContentLength, 10))
+		o.WriteString(" bytes ")
+	}
+
+	o.WriteString("exceeds the limit of ")
+	o.WriteString(strconv.FormatInt(tle.Limit, 10))
+	o.WriteString(" bytes")
+	return o.String()
+}
+
+// Config is the set of configuration options for response body limits.
+type Config struct {
+	// MaxSize is the largest response body allowed.  If nonpositive, DefaultMaxSize is used.
+	// This limit can be overridden for individual requests with WithMaxSize.
+	MaxSize int64 `json:"maxSize" yaml:"maxSize"`
+}
+
+func (cfg Config) maxSize() int64 {
+	if cfg.MaxSize > 0 {
+		return cfg.MaxSize
+	}
+
+	return DefaultMaxSize
+}
+
+// limiter enforces a body size limit on responses
+type limiter struct {
+	maxSize int64
+}
+
+// limit returns the limit to enforce for the given request.  A nonpositive limit
+// means that the response is not limited.
+func (l limiter) limit(request *http.Request) int64 {
+	if maxSize, ok := MaxSizeFromContext(request.Context()); ok {
+		return maxSize
+	}
+
+	return l.maxSize
+}
+
+// apply enforces the request's limit on the result of a round trip.
+func (l limiter) apply(request *http.Request, response *http.Response, err error) (*http.Response, error) {
+	if err != nil || response == nil || response.Body == nil || response.Body == http.NoBody {
+		return response, err
+	}
+
+	// a switching protocols response has a writable body, which must not be hidden
+	if response.StatusCode == http.StatusSwitchingProtocols {
+		return response, nil
+	}
+
+	maxSize := l.limit(request)
+	if maxSize <= 0 {
+		return response, nil
+	}
+
+	if response.ContentLength > maxSize {
+		response.Body.Close()
+		return nil, &TooLargeError{
+			Limit:         maxSize,
+			ContentLength: response.ContentLength,
+		}
+	}
+
+	response.Body = limitedBody{
+		Reader: &limit.Reader{
+			R:     response.Body,
+			Limit: maxSize,
+			Err:   &TooLargeError{Limit: maxSize, ContentLength: -1},
+		},
+		Closer: response.Body,
+	}
+
+	return response, nil
+}
+
+// limitedBody is a response body that returns a *TooLargeError once more than
+// the limit would have been read.
+type limitedBody struct {
+	*limit.Reader
+	io.Closer
+}
+
+// NewClient creates client middleware that limits the size of response bodies.
+// A response whose Content-Length exceeds the limit is closed, and a *TooLargeError
+// is returned instead.  Otherwise, the response body is wrapped so that reading past
+// the limit returns a *TooLargeError.
+//
+// If the decorated client is nil, http.DefaultClient is used.
+func NewClient(cfg Config) client.Constructor {
+	l := limiter{maxSize: cfg.maxSize()}
+	return func(next httpaux.Client) httpaux.Client {
+		if next == nil {
+			next = http.DefaultClient
+		}
+
+		return client.Func(func(request *http.Request) (*http.Response, error) {
+			response, err := next.Do(request)
+			return l.apply(request, response, err)
+		})
+	}
+}
+
+// NewRoundTripper creates round tripper middleware that limits the size of response
+// bodies just as NewClient does.
+//
+// If the decorated round tripper is nil, http.DefaultTransport is used.
+func NewRoundTripper(cfg Config) roundtrip.Constructor {
+	l := limiter{maxSize: cfg.maxSize()}
+	return func(next http.RoundTripper) http.RoundTripper {
+		if next == nil {
+			next = http.DefaultTransport
+		}
+
+		return roundtrip.PreserveCloseIdler(
+			next,
+			roundtrip.Func(func(request *http.Request) (*http.Response, error) {
+				response, err := next.RoundTrip(request)
+				return l.apply(request, response, err)
+			}),
+		)
+	}
+}
@@ -0,0 +1,271 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package bodylimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/httpmock"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+type TooLargeErrorTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TooLargeErrorTestSuite) TestContentLength() {
+	err := &TooLargeError{Limit: 10, ContentLength: 20}
+	suite.Contains(err.Error(), "20")
+	suite.Contains(err.Error(), "10")
+}
+
+func (suite *TooLargeErrorTestSuite) TestRead() {
+	err := &TooLargeError{Limit: 10, ContentLength: -1}
+	suite.Contains(err.Error(), "10")
+	suite.NotContains(err.Error(), "-1")
+}
+
+func TestTooLargeError(t *testing.T) {
+	suite.Run(t, new(TooLargeErrorTestSuite))
+}
+
+type LimitTestSuite struct {
+	suite.Suite
+}
+
+func (suite *LimitTestSuite) newRequest(ctx context.Context) *http.Request {
+	request, err := http.NewRequestWithContext(ctx, "GET", "http://example.com/", nil)
+	suite.Require().NoError(err)
+	return request
+}
+
+// response creates a response with the given body and content length
+func (suite *LimitTestSuite) response(body *httpmock.BodyReadCloser, contentLength int64) *http.Response {
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		ContentLength: contentLength,
+		Body:          body,
+	}
+}
+
+func (suite *LimitTestSuite) TestApply() {
+	testCases := []struct {
+		name          string
+		cfg           Config
+		ctx           context.Context
+		body          string
+		contentLength int64
+		expectedBody  string
+		expectedErr   *TooLargeError
+	}{
+		{
+			name:          "UnderLimit",
+			cfg:           Config{MaxSize: 10},
+			ctx:           context.Background(),
+			body:          "short",
+			contentLength: -1,
+			expectedBody:  "short",
+		},
+		{
+			name:          "AtLimit",
+			cfg:           Config{MaxSize: 5},
+			ctx:           context.Background(),
+			body:          "exact",
+			contentLength: 5,
+			expectedBody:  "exact",
+		},
+		{
+			name:          "ReadPastLimit",
+			cfg:           Config{MaxSize: 5},
+			ctx:           context.Background(),
+			body:          "this is too long",
+			contentLength: -1,
+			expectedBody:  "this ",
+			expectedErr:   &TooLargeError{Limit: 5, ContentLength: -1},
+		},
+		{
+			name:          "DefaultMaxSize",
+			ctx:           context.Background(),
+			body:          strings.Repeat("x", int(DefaultMaxSize)+1),
+			contentLength: -1,
+			expectedBody:  strings.Repeat("x", int(DefaultMaxSize)),
+			expectedErr:   &TooLargeError{Limit: DefaultMaxSize, ContentLength: -1},
+		},
+		{
+			name:          "ContextOverride",
+			cfg:           Config{MaxSize: 100},
+			ctx:           WithMaxSize(context.Background(), 3),
+			body:          "this is too long",
+			contentLength: -1,
+			expectedBody:  "thi",
+			expectedErr:   &TooLargeError{Limit: 3, ContentLength: -1},
+		},
+		{
+			name:          "ContextUnlimited",
+			cfg:           Config{MaxSize: 3},
+			ctx:           WithMaxSize(context.Background(), 0),
+			body:          "this is not too long",
+			contentLength: 20,
+			expectedBody:  "this is not too long",
+		},
+		{
+			name:          "MaxInt64",
+			cfg:           Config{MaxSize: math.MaxInt64},
+			ctx:           context.Background(),
+			body:          "unlimited",
+			contentLength: -1,
+			expectedBody:  "unlimited",
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			var (
+				body = httpmock.BodyString(testCase.body)
+				l    = limiter{maxSize: testCase.cfg.maxSize()}
+			)
+
+			response, err := l.apply(suite.newRequest(testCase.ctx), suite.response(body, testCase.contentLength), nil)
+			suite.Require().NoError(err)
+			suite.Require().NotNil(response)
+
+			actual, err := io.ReadAll(response.Body)
+			suite.Equal(testCase.expectedBody, string(actual))
+			if testCase.expectedErr != nil {
+				var tle *TooLargeError
+				suite.Require().ErrorAs(err, &tle)
+				suite.Equal(*testCase.expectedErr, *tle)
+
+				// the error is sticky
+				n, err := response.Body.Read(make([]byte, 10))
+				suite.Zero(n)
+				suite.ErrorAs(err, &tle)
+			} else {
+				suite.NoError(err)
+			}
+
+			suite.NoError(response.Body.Close())
+			suite.True(body.Closed())
+		})
+	}
+}
+
+func (suite *LimitTestSuite) TestApplyContentLengthTooLarge() {
+	var (
+		body = httpmock.BodyString("this is too long")
+		l    = limiter{maxSize: 5}
+	)
+
+	response, err := l.apply(suite.newRequest(context.Background()), suite.response(body, 16), nil) //nolint:bodyclose
+	suite.Nil(response)
+
+	var tle *TooLargeError
+	suite.Require().ErrorAs(err, &tle)
+	suite.Equal(TooLargeError{Limit: 5, ContentLength: 16}, *tle)
+	suite.True(body.Closed())
+}
+
+func (suite *LimitTestSuite) TestApplyUnwrapped() {
+	testCases := []struct {
+		name     string
+		response *http.Response
+	}{
+		{
+			name:     "NoBody",
+			response: &http.Response{StatusCode: http.StatusOK, Body: http.NoBody},
+		},
+		{
+			name: "SwitchingProtocols",
+			response: &http.Response{
+				StatusCode:    http.StatusSwitchingProtocols,
+				ContentLength: 100,
+				Body:          httpmock.EmptyBody(),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			expectedBody := testCase.response.Body
+			l := limiter{maxSize: 5}
+
+			response, err := l.apply(suite.newRequest(context.Background()), testCase.response, nil) //nolint:bodyclose
+			suite.NoError(err)
+			suite.Require().NotNil(response)
+			suite.Equal(expectedBody, response.Body)
+		})
+	}
+}
+
+func (suite *LimitTestSuite) TestApplyError() {
+	expectedErr := errors.New("expected")
+	response, err := limiter{maxSize: 5}.apply(suite.newRequest(context.Background()), nil, expectedErr) //nolint:bodyclose
+	suite.Nil(response)
+	suite.ErrorIs(err, expectedErr)
+}
+
+func (suite *LimitTestSuite) TestNewClient() {
+	var (
+		body = httpmock.BodyString("this is too long")
+		next = httpmock.NewRoundTripperSuite(suite)
+		c    = client.NewChain(NewClient(Config{MaxSize: 5})).Then(&http.Client{Transport: next})
+	)
+
+	next.OnAny().Return(suite.response(body, -1), nil).Once()
+	response, err := c.Do(suite.newRequest(context.Background()))
+	suite.Require().NoError(err)
+	suite.Require().NotNil(response)
+
+	actual, err := io.ReadAll(response.Body)
+	suite.Equal("this ", string(actual))
+
+	var tle *TooLargeError
+	suite.ErrorAs(err, &tle)
+	suite.NoError(response.Body.Close())
+	suite.True(body.Closed())
+	next.AssertExpectations()
+}
+
+func (suite *LimitTestSuite) TestNewClientDefault() {
+	suite.NotNil(NewClient(Config{})(nil))
+}
+
+func (suite *LimitTestSuite) TestNewRoundTripper() {
+	var (
+		body = httpmock.BodyString("this is too long")
+		next = &httpmock.CloseIdler{
+			RoundTripper: httpmock.NewRoundTripperSuite(suite),
+		}
+
+		rt = roundtrip.NewChain(NewRoundTripper(Config{MaxSize: 5})).Then(next)
+	)
+
+	next.OnAny().Return(suite.response(body, 16), nil).Once()
+	response, err := rt.RoundTrip(suite.newRequest(context.Background())) //nolint:bodyclose
+	suite.Nil(response)
+
+	var tle *TooLargeError
+	suite.ErrorAs(err, &tle)
+	suite.True(body.Closed())
+
+	next.OnCloseIdleConnections().Once()
+	roundtrip.CloseIdleConnections(rt)
+	next.AssertExpectations()
+}
+
+func (suite *LimitTestSuite) TestNewRoundTripperDefault() {
+	suite.NotNil(NewRoundTripper(Config{})(nil))
+}
+
+func TestLimit(t *testing.T) {
+	suite.Run(t, new(LimitTestSuite))
+}
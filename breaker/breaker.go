@@ -0,0 +1,375 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/xmidt-org/httpaux/clock"
+	"github.com/xmidt-org/httpaux/retry"
+)
+
+const (
+	// DefaultWindow is used when Config.Window is nonpositive.
+	DefaultWindow time.Duration = 10 * time.Second
+
+	// DefaultMinRequests is used when Config.MinRequests is nonpositive.
+	DefaultMinRequests = 10
+
+	// DefaultCooldown is used when Config.Cooldown is nonpositive.
+	DefaultCooldown time.Duration = 30 * time.Second
+
+	// DefaultHalfOpenProbes is used when Config.HalfOpenProbes is nonpositive.
+	DefaultHalfOpenProbes = 1
+
+	// windowBuckets is the number of slices a window is divided into.
+	windowBuckets = 10
+)
+
+// State is the state of a Breaker.
+type State int
+
+const (
+	// Closed means that traffic is allowed.
+	Closed State = iota
+
+	// Open means that traffic is rejected.
+	Open
+
+	// HalfOpen means that a limited number of probe requests are allowed in
+	// order to decide whether to close the Breaker.
+	HalfOpen
+)
+
+// String returns a human-readable label for this state.
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+
+	case Open:
+		return "open"
+
+	case HalfOpen:
+		return "half-open"
+
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// OpenError is returned by decorated clients and round trippers to indicate that
+// the Breaker rejected a request without sending it.
+type OpenError struct {
+	// Name is the name of the Breaker that rejected the request.
+	Name string
+
+	// State is the Breaker's state at the time the request was rejected.  This will be
+	// Open, or HalfOpen if the maximum number of probes was already in flight.
+	State State
+}
+
+// Error satisfies the error interface
+func (oe *OpenError) Error() string {
+	return fmt.Sprintf("Breaker [%s] %s", oe.Name, oe.State)
+}
+
+// DefaultCheck is the retry.Check used to judge results when Config.Check is unset.
+// A result is a failure if there was an error or the response status code is 5xx.
+// A canceled context is not considered a failure, since it reflects the caller's choice
+// rather than the health of the server.
+func DefaultCheck(r *http.Response, err error) bool {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return false
+
+	case err != nil:
+		return true
+
+	default:
+		return r != nil && r.StatusCode >= 500
+	}
+}
+
+// Config is the set of configuration options for a Breaker.
+type Config struct {
+	// Name is an optional identifier for this Breaker.  It's useful in logs and metrics
+	// when an application uses multiple Breakers.
+	Name string `json:"name" yaml:"name"`
+
+	// ConsecutiveFailures is the number of failures in a row that trips this Breaker.
+	// If nonpositive, consecutive failures do not trip the Breaker.
+	ConsecutiveFailures int `json:"consecutiveFailures" yaml:"consecutiveFailures"`
+
+	// FailureRatio is the ratio of failures to requests within the Window that trips this
+	// Breaker.  If nonpositive, the failure ratio does not trip the Breaker.
+	FailureRatio float64 `json:"failureRatio" yaml:"failureRatio"`
+
+	// MinRequests is the minimum number of requests within the Window before FailureRatio
+	// is considered.  This prevents a handful of failures from tripping the Breaker when
+	// traffic is light.  If nonpositive, DefaultMinRequests is used.
+	MinRequests int `json:"minRequests" yaml:"minRequests"`
+
+	// Window is the rolling time period over which requests and failures are counted
+	// for FailureRatio.  If nonpositive, DefaultWindow is used.
+	Window time.Duration `json:"window" yaml:"window"`
+
+	// Cooldown is how long this Breaker stays open before moving to half-open.
+	// If nonpositive, DefaultCooldown is used.
+	Cooldown time.Duration `json:"cooldown" yaml:"cooldown"`
+
+	// HalfOpenProbes is both the number of concurrent probe requests allowed while half-open
+	// and the number of successful probes required to close this Breaker.  If nonpositive,
+	// DefaultHalfOpenProbes is used.
+	HalfOpenProbes int `json:"halfOpenProbes" yaml:"halfOpenProbes"`
+
+	// Check is the predicate that decides whether a result is a failure.  A result for which
+	// this predicate returns true counts as a failure.  If unset, DefaultCheck is used.
+	Check retry.Check `json:"-" yaml:"-"`
+
+	// OnStateChange is an optional callback invoked each time this Breaker changes state.
+	// The callback is invoked synchronously, after the state has changed, and must not block.
+	OnStateChange func(name string, from, to State) `json:"-" yaml:"-"`
+
+	// Clock is the optional source of time.  If unset, clock.System is used.
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+// bucket is a slice of time within a Breaker's window
+type bucket struct {
+	// epoch is the absolute index of this bucket, used to detect stale buckets
+	epoch    int64
+	requests int64
+	failures int64
+}
+
+// Breaker is a circuit breaker.  A Breaker is safe for concurrent use.
+type Breaker struct {
+	name                string
+	consecutiveFailures int
+	failureRatio        float64
+	minRequests         int64
+	cooldown            time.Duration
+	halfOpenProbes      int
+	check               retry.Check
+	onStateChange       func(string, State, State)
+	now                 func() time.Time
+
+	lock        sync.Mutex
+	state       State
+	generation  uint64
+	consecutive int
+	openedAt    time.Time
+	probes      int
+	successes   int
+	bucketWidth int64
+	buckets     [windowBuckets]bucket
+}
+
+// New creates a closed Breaker from a configuration.
+func New(cfg Config) *Breaker {
+	b := &Breaker{
+		name:                cfg.Name,
+		consecutiveFailures: cfg.ConsecutiveFailures,
+		failureRatio:        cfg.FailureRatio,
+		minRequests:         int64(cfg.MinRequests),
+		cooldown:            cfg.Cooldown,
+		halfOpenProbes:      cfg.HalfOpenProbes,
+		check:               cfg.Check,
+		onStateChange:       cfg.OnStateChange,
+		now:                 clock.OrSystem(cfg.Clock).Now,
+	}
+
+	if b.minRequests <= 0 {
+		b.minRequests = DefaultMinRequests
+	}
+
+	if b.cooldown <= 0 {
+		b.cooldown = DefaultCooldown
+	}
+
+	if b.halfOpenProbes <= 0 {
+		b.halfOpenProbes = DefaultHalfOpenProbes
+	}
+
+	if b.check == nil {
+		b.check = DefaultCheck
+	}
+
+	if b.onStateChange == nil {
+		b.onStateChange = func(string, State, State) {}
+	}
+
+	window := cfg.Window
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	b.bucketWidth = max(int64(window)/windowBuckets, 1)
+	return b
+}
+
+// Name returns the configured name of this Breaker.
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State returns the current state of this Breaker.  An open Breaker whose cooldown
+// has elapsed reports HalfOpen.
+func (b *Breaker) State() State {
+	b.lock.Lock()
+	from := b.state
+	to := b.refresh()
+	b.lock.Unlock()
+
+	b.notify(from, to)
+	return to
+}
+
+// Allow asks this Breaker for permission to send a request.  If the request is
+// allowed, the returned function must be called exactly once with the result.
+// Otherwise, an *OpenError is returned.
+//
+// Most code will not need this method, since Then and ThenRoundTrip take care
+// of calling it.
+func (b *Breaker) Allow() (func(*http.Response, error), error) {
+	b.lock.Lock()
+	from := b.state
+	to := b.refresh()
+
+	var err error
+	switch {
+	case to == Open:
+		err = &OpenError{Name: b.name, State: Open}
+
+	case to == HalfOpen && b.probes >= b.halfOpenProbes:
+		err = &OpenError{Name: b.name, State: HalfOpen}
+
+	case to == HalfOpen:
+		b.probes++
+	}
+
+	generation := b.generation
+	b.lock.Unlock()
+
+	b.notify(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(r *http.Response, err error) {
+		b.done(generation, b.check(r, err))
+	}, nil
+}
+
+// done records the result of an allowed request.  results from a prior
+// generation, i.e. before the last state change, are ignored.
+func (b *Breaker) done(generation uint64, failed bool) {
+	b.lock.Lock()
+	from := b.state
+	if generation == b.generation {
+		switch b.state {
+		case Closed:
+			b.record(failed)
+			if b.shouldTrip() {
+				b.setState(Open)
+			}
+
+		case HalfOpen:
+			b.probes--
+			if failed {
+				b.setState(Open)
+			} else if b.successes++; b.successes >= b.halfOpenProbes {
+				b.setState(Closed)
+			}
+		}
+	}
+
+	to := b.state
+	b.lock.Unlock()
+
+	b.notify(from, to)
+}
+
+// refresh moves an open breaker to half-open once its cooldown elapses.
+// the lock must be held.
+func (b *Breaker) refresh() State {
+	if b.state == Open && !b.now().Before(b.openedAt.Add(b.cooldown)) {
+		b.setState(HalfOpen)
+	}
+
+	return b.state
+}
+
+// setState transitions to a new state, resetting the bookkeeping for that state.
+// the lock must be held.
+func (b *Breaker) setState(s State) {
+	b.state = s
+	b.generation++
+	b.probes = 0
+	b.successes = 0
+
+	switch s {
+	case Closed:
+		b.consecutive = 0
+		b.buckets = [windowBuckets]bucket{}
+
+	case Open:
+		b.openedAt = b.now()
+	}
+}
+
+// notify invokes the state change callback if the state changed.
+// the lock must not be held.
+func (b *Breaker) notify(from, to State) {
+	if from != to {
+		b.onStateChange(b.name, from, to)
+	}
+}
+
+// record adds a result to the current bucket.  the lock must be held.
+func (b *Breaker) record(failed bool) {
+	epoch := b.now().UnixNano() / b.bucketWidth
+	current := &b.buckets[epoch%windowBuckets]
+	if current.epoch != epoch {
+		*current = bucket{epoch: epoch}
+	}
+
+	current.requests++
+	if failed {
+		current.failures++
+		b.consecutive++
+	} else {
+		b.consecutive = 0
+	}
+}
+
+// shouldTrip tests whether the recorded results should open this breaker.
+// the lock must be held.
+func (b *Breaker) shouldTrip() bool {
+	if b.consecutiveFailures > 0 && b.consecutive >= b.consecutiveFailures {
+		return true
+	} else if b.failureRatio <= 0.0 {
+		return false
+	}
+
+	var (
+		oldest             = b.now().UnixNano()/b.bucketWidth - windowBuckets
+		requests, failures int64
+	)
+
+	for _, bk := range b.buckets {
+		if bk.epoch > oldest {
+			requests += bk.requests
+			failures += bk.failures
+		}
+	}
+
+	return requests >= b.minRequests && float64(failures)/float64(requests) >= b.failureRatio
+}
@@ -0,0 +1,236 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package breaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/clock"
+)
+
+type StateTestSuite struct {
+	suite.Suite
+}
+
+func (suite *StateTestSuite) TestString() {
+	suite.Equal("closed", Closed.String())
+	suite.Equal("open", Open.String())
+	suite.Equal("half-open", HalfOpen.String())
+	suite.Equal("State(17)", State(17).String())
+}
+
+func TestState(t *testing.T) {
+	suite.Run(t, new(StateTestSuite))
+}
+
+type DefaultCheckTestSuite struct {
+	suite.Suite
+}
+
+func (suite *DefaultCheckTestSuite) Test() {
+	suite.False(DefaultCheck(&http.Response{StatusCode: http.StatusOK}, nil))
+	suite.False(DefaultCheck(&http.Response{StatusCode: http.StatusNotFound}, nil))
+	suite.True(DefaultCheck(&http.Response{StatusCode: http.StatusServiceUnavailable}, nil))
+	suite.True(DefaultCheck(nil, errors.New("expected")))
+	suite.False(DefaultCheck(nil, context.Canceled))
+	suite.False(DefaultCheck(nil, nil))
+}
+
+func TestDefaultCheck(t *testing.T) {
+	suite.Run(t, new(DefaultCheckTestSuite))
+}
+
+type BreakerTestSuite struct {
+	suite.Suite
+
+	clock       *clock.Fake
+	transitions [][2]State
+}
+
+func (suite *BreakerTestSuite) SetupTest() {
+	suite.clock = clock.NewFake(time.Date(2021, time.March, 4, 10, 30, 0, 0, time.UTC))
+	suite.transitions = nil
+}
+
+func (suite *BreakerTestSuite) newBreaker(cfg Config) *Breaker {
+	cfg.Name = "test"
+	cfg.Clock = suite.clock
+	cfg.OnStateChange = func(name string, from, to State) {
+		suite.Equal("test", name)
+		suite.transitions = append(suite.transitions, [2]State{from, to})
+	}
+
+	b := New(cfg)
+	suite.Require().NotNil(b)
+	suite.Equal("test", b.Name())
+	suite.Equal(Closed, b.State())
+	return b
+}
+
+// result sends a single request through the breaker with the given outcome
+func (suite *BreakerTestSuite) result(b *Breaker, failed bool) {
+	done, err := b.Allow()
+	suite.Require().NoError(err)
+	suite.Require().NotNil(done)
+
+	if failed {
+		done(&http.Response{StatusCode: http.StatusServiceUnavailable}, nil)
+	} else {
+		done(&http.Response{StatusCode: http.StatusOK}, nil)
+	}
+}
+
+// assertRejected verifies that the breaker rejects requests in the given state
+func (suite *BreakerTestSuite) assertRejected(b *Breaker, expected State) {
+	done, err := b.Allow()
+	suite.Nil(done)
+
+	var oe *OpenError
+	suite.Require().ErrorAs(err, &oe)
+	suite.Equal("test", oe.Name)
+	suite.Equal(expected, oe.State)
+	suite.Contains(oe.Error(), "test")
+}
+
+func (suite *BreakerTestSuite) TestDefaults() {
+	b := New(Config{})
+	suite.Equal(Closed, b.State())
+	suite.Equal(int64(DefaultMinRequests), b.minRequests)
+	suite.Equal(DefaultCooldown, b.cooldown)
+	suite.Equal(DefaultHalfOpenProbes, b.halfOpenProbes)
+	suite.Equal(int64(DefaultWindow)/windowBuckets, b.bucketWidth)
+
+	// with no trip conditions, a breaker never opens
+	for i := 0; i < 100; i++ {
+		done, err := b.Allow()
+		suite.Require().NoError(err)
+		done(nil, errors.New("expected"))
+	}
+
+	suite.Equal(Closed, b.State())
+}
+
+func (suite *BreakerTestSuite) TestConsecutiveFailures() {
+	b := suite.newBreaker(Config{
+		ConsecutiveFailures: 3,
+	})
+
+	suite.result(b, true)
+	suite.result(b, true)
+	suite.result(b, false) // resets the count
+	suite.result(b, true)
+	suite.result(b, true)
+	suite.Equal(Closed, b.State())
+
+	suite.result(b, true)
+	suite.Equal(Open, b.State())
+	suite.assertRejected(b, Open)
+	suite.Equal([][2]State{{Closed, Open}}, suite.transitions)
+}
+
+func (suite *BreakerTestSuite) TestFailureRatio() {
+	b := suite.newBreaker(Config{
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		Window:       10 * time.Second,
+	})
+
+	// below MinRequests, so no trip
+	suite.result(b, true)
+	suite.result(b, true)
+	suite.result(b, true)
+	suite.Equal(Closed, b.State())
+
+	// the failures expire
+	suite.clock.Add(20 * time.Second)
+	suite.result(b, false)
+	suite.result(b, false)
+	suite.result(b, false)
+	suite.result(b, true)
+	suite.Equal(Closed, b.State())
+
+	suite.result(b, true)
+	suite.result(b, true)
+	suite.Equal(Open, b.State())
+}
+
+func (suite *BreakerTestSuite) TestHalfOpen() {
+	b := suite.newBreaker(Config{
+		ConsecutiveFailures: 1,
+		Cooldown:            time.Minute,
+		HalfOpenProbes:      2,
+	})
+
+	suite.result(b, true)
+	suite.Equal(Open, b.State())
+
+	suite.clock.Add(59 * time.Second)
+	suite.assertRejected(b, Open)
+
+	suite.clock.Add(time.Second)
+	probe1, err := b.Allow()
+	suite.Require().NoError(err)
+	probe2, err := b.Allow()
+	suite.Require().NoError(err)
+	suite.assertRejected(b, HalfOpen)
+	suite.Equal(HalfOpen, b.State())
+
+	probe1(&http.Response{StatusCode: http.StatusOK}, nil)
+	suite.Equal(HalfOpen, b.State())
+	probe2(&http.Response{StatusCode: http.StatusOK}, nil)
+	suite.Equal(Closed, b.State())
+
+	suite.Equal(
+		[][2]State{{Closed, Open}, {Open, HalfOpen}, {HalfOpen, Closed}},
+		suite.transitions,
+	)
+}
+
+func (suite *BreakerTestSuite) TestHalfOpenFailure() {
+	b := suite.newBreaker(Config{
+		ConsecutiveFailures: 1,
+		Cooldown:            time.Minute,
+	})
+
+	suite.result(b, true)
+	suite.clock.Add(time.Minute)
+	suite.result(b, true)
+	suite.Equal(Open, b.State())
+
+	// the cooldown starts over
+	suite.clock.Add(59 * time.Second)
+	suite.assertRejected(b, Open)
+
+	suite.Equal(
+		[][2]State{{Closed, Open}, {Open, HalfOpen}, {HalfOpen, Open}},
+		suite.transitions,
+	)
+}
+
+func (suite *BreakerTestSuite) TestStaleResults() {
+	b := suite.newBreaker(Config{
+		ConsecutiveFailures: 1,
+		Cooldown:            time.Minute,
+	})
+
+	// a request admitted while closed finishes after the breaker trips
+	stale, err := b.Allow()
+	suite.Require().NoError(err)
+	suite.result(b, true)
+	suite.Equal(Open, b.State())
+
+	suite.clock.Add(time.Minute)
+	suite.Equal(HalfOpen, b.State())
+	stale(&http.Response{StatusCode: http.StatusOK}, nil)
+	suite.Equal(HalfOpen, b.State())
+}
+
+func TestBreaker(t *testing.T) {
+	suite.Run(t, new(BreakerTestSuite))
+}
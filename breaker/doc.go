@@ -0,0 +1,28 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package breaker implements a circuit breaker for HTTP clients.
+
+A Breaker starts out closed, allowing all traffic.  When too many requests fail, either
+consecutively or as a ratio of requests within a rolling window, the Breaker trips to open.
+An open Breaker rejects requests with an *OpenError without sending them.  After a cooldown,
+the Breaker moves to half-open and allows a limited number of probe requests through.  If the
+probes succeed, the Breaker closes.  If any probe fails, the Breaker opens again.
+
+Whether a result is a failure is decided by a retry.Check:
+
+	b := breaker.New(breaker.Config{
+	  Name: "backend",
+	  ConsecutiveFailures: 5,
+	  FailureRatio: 0.5,
+	  Cooldown: 30 * time.Second,
+	})
+
+	c := client.NewChain(b.Then).Then(new(http.Client))
+	rt := roundtrip.NewChain(b.ThenRoundTrip).Then(http.DefaultTransport)
+
+A single Breaker may decorate any number of clients and round trippers.  They will all share
+the same state.
+*/
+package breaker
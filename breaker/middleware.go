@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package breaker
+
+import (
+	"net/http"
+
+	"github.com/xmidt-org/httpaux"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+// Then decorates a client so that its traffic is controlled by this Breaker.
+// This method is a client.Constructor.
+//
+// If next is nil, http.DefaultClient is decorated.
+func (b *Breaker) Then(next httpaux.Client) httpaux.Client {
+	if next == nil {
+		next = http.DefaultClient
+	}
+
+	return client.Func(func(request *http.Request) (*http.Response, error) {
+		done, err := b.Allow()
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := next.Do(request)
+		done(response, err)
+		return response, err
+	})
+}
+
+// ThenRoundTrip decorates a round tripper so that its traffic is controlled by this Breaker.
+// This method is a roundtrip.Constructor.
+//
+// The returned http.RoundTripper preserves the CloseIdleConnections method of next.
+// If next is nil, http.DefaultTransport is decorated.  As required of any
+// http.RoundTripper, the request body is closed when the breaker rejects a request.
+func (b *Breaker) ThenRoundTrip(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return roundtrip.PreserveCloseIdler(
+		next,
+		roundtrip.Func(func(request *http.Request) (*http.Response, error) {
+			done, err := b.Allow()
+			if err != nil {
+				if request.Body != nil {
+					request.Body.Close()
+				}
+
+				return nil, err
+			}
+
+			response, err := next.RoundTrip(request)
+			done(response, err)
+			return response, err
+		}),
+	)
+}
+
+var (
+	_ client.Constructor    = (*Breaker)(nil).Then
+	_ roundtrip.Constructor = (*Breaker)(nil).ThenRoundTrip
+)
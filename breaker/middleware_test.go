@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package breaker
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/httpmock"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+}
+
+func (suite *MiddlewareTestSuite) newRequest() *http.Request {
+	request, err := http.NewRequest("GET", "/test", nil)
+	suite.Require().NoError(err)
+	return request
+}
+
+func (suite *MiddlewareTestSuite) TestThen() {
+	var (
+		b  = New(Config{ConsecutiveFailures: 2})
+		rt = httpmock.NewRoundTripperSuite(suite)
+		c  = client.NewChain(b.Then).Then(&http.Client{Transport: rt})
+	)
+
+	rt.OnAny().Return(&http.Response{StatusCode: http.StatusServiceUnavailable, Body: httpmock.EmptyBody()}, nil).Twice()
+	for i := 0; i < 2; i++ {
+		response, err := c.Do(suite.newRequest())
+		suite.NoError(err)
+		suite.Require().NotNil(response)
+		suite.Equal(http.StatusServiceUnavailable, response.StatusCode)
+	}
+
+	response, err := c.Do(suite.newRequest())
+	suite.Nil(response)
+
+	var oe *OpenError
+	suite.ErrorAs(err, &oe)
+	rt.AssertExpectations()
+}
+
+func (suite *MiddlewareTestSuite) TestThenDefault() {
+	suite.NotNil(New(Config{}).Then(nil))
+}
+
+func (suite *MiddlewareTestSuite) TestThenRoundTrip() {
+	var (
+		b    = New(Config{ConsecutiveFailures: 1})
+		next = &httpmock.CloseIdler{
+			RoundTripper: httpmock.NewRoundTripperSuite(suite),
+		}
+
+		decorated = roundtrip.NewChain(b.ThenRoundTrip).Then(next)
+	)
+
+	next.RoundTripper.OnAny().Return(nil, http.ErrHandlerTimeout).Once()
+	response, err := decorated.RoundTrip(suite.newRequest())
+	suite.Nil(response)
+	suite.ErrorIs(err, http.ErrHandlerTimeout)
+
+	response, err = decorated.RoundTrip(suite.newRequest())
+	suite.Nil(response)
+
+	var oe *OpenError
+	suite.ErrorAs(err, &oe)
+	suite.Equal(Open, oe.State)
+
+	next.OnCloseIdleConnections().Once()
+	roundtrip.CloseIdleConnections(decorated)
+	next.AssertExpectations()
+}
+
+func (suite *MiddlewareTestSuite) TestThenRoundTripClosesBody() {
+	var (
+		b    = New(Config{ConsecutiveFailures: 1})
+		next = httpmock.NewRoundTripperSuite(suite)
+		rt   = b.ThenRoundTrip(next)
+	)
+
+	next.OnAny().Return(nil, http.ErrHandlerTimeout).Once()
+	response, err := rt.RoundTrip(suite.newRequest()) //nolint:bodyclose
+	suite.Nil(response)
+	suite.ErrorIs(err, http.ErrHandlerTimeout)
+
+	body := httpmock.BodyString("content")
+	request, err := http.NewRequest("POST", "/test", body)
+	suite.Require().NoError(err)
+
+	response, err = rt.RoundTrip(request) //nolint:bodyclose
+	suite.Nil(response)
+
+	var oe *OpenError
+	suite.ErrorAs(err, &oe)
+	suite.True(body.Closed(), "a rejected request's body should be closed")
+	next.AssertExpectations()
+}
+
+func (suite *MiddlewareTestSuite) TestThenRoundTripDefault() {
+	suite.NotNil(New(Config{}).ThenRoundTrip(nil))
+}
+
+func TestMiddleware(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	cacheControlHeader = "Cache-Control"
+	pragmaHeader       = "Pragma"
+
+	// maxDeltaSeconds is the largest delta-seconds value a cache must understand.
+	// Larger values are treated as this value.  See RFC 9111, section 1.2.2.
+	maxDeltaSeconds = math.MaxInt32
+)
+
+// directives is a parsed Cache-Control header.  Directive names are lowercased,
+// and values are unquoted.  Directives without values map to the empty string.
+type directives map[string]string
+
+// nextDirective splits off the first comma-delimited item of a Cache-Control value,
+// honoring quoted strings.
+func nextDirective(v string) (item, rest string) {
+	var inQuote, escaped bool
+	for i := 0; i < len(v); i++ {
+		switch c := v[i]; {
+		case escaped:
+			escaped = false
+
+		case inQuote && c == '\\':
+			escaped = true
+
+		case c == '"':
+			inQuote = !inQuote
+
+		case c == ',' && !inQuote:
+			return v[:i], v[i+1:]
+		}
+	}
+
+	return v, ""
+}
+
+// unquote removes the quotes and escapes from a quoted-string.  Any other
+// value is returned as is.
+func unquote(v string) string {
+	if len(v) < 2 || v[0] != '"' || v[len(v)-1] != '"' {
+		return v
+	}
+
+	var o strings.Builder
+	escaped := false
+	for _, c := range v[1 : len(v)-1] {
+		if !escaped && c == '\\' {
+			escaped = true
+			continue
+		}
+
+		escaped = false
+		o.WriteRune(c)
+	}
+
+	return o.String()
+}
+
+// parseDirectives parses the Cache-Control values in the given header.  If a directive
+// appears more than once, the first occurrence is used.
+func parseDirectives(h http.Header) directives {
+	d := make(directives)
+	for _, v := range h.Values(cacheControlHeader) {
+		for len(v) > 0 {
+			var item string
+			item, v = nextDirective(v)
+
+			name, value, _ := strings.Cut(item, "=")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if len(name) == 0 {
+				continue
+			}
+
+			if _, exists := d[name]; !exists {
+				d[name] = unquote(strings.TrimSpace(value))
+			}
+		}
+	}
+
+	return d
+}
+
+// parseRequestDirectives parses the Cache-Control of a request.  A request with no
+// Cache-Control but with a Pragma of no-cache is treated as if it had a no-cache directive.
+// See RFC 9111, section 5.4.
+func parseRequestDirectives(h http.Header) directives {
+	d := parseDirectives(h)
+	if len(h.Values(cacheControlHeader)) == 0 {
+		for _, v := range h.Values(pragmaHeader) {
+			for _, p := range strings.Split(v, ",") {
+				if strings.EqualFold(strings.TrimSpace(p), "no-cache") {
+					d["no-cache"] = ""
+				}
+			}
+		}
+	}
+
+	return d
+}
+
+// has tests if the given directive is present, with or without a value.
+func (d directives) has(name string) bool {
+	_, ok := d[name]
+	return ok
+}
+
+// parseSeconds parses a delta-seconds value.  See RFC 9111, section 1.2.2.
+func parseSeconds(v string) (time.Duration, bool) {
+	if len(v) == 0 {
+		return 0, false
+	}
+
+	for i := 0; i < len(v); i++ {
+		if v[i] < '0' || v[i] > '9' {
+			return 0, false
+		}
+	}
+
+	s, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || s > maxDeltaSeconds {
+		// the only possible error at this point is overflow
+		s = maxDeltaSeconds
+	}
+
+	return time.Duration(s) * time.Second, true
+}
+
+// seconds returns the delta-seconds value of the given directive.  This method returns false if
+// the directive is not present or its value is not a valid delta-seconds.
+func (d directives) seconds(name string) (time.Duration, bool) {
+	v, ok := d[name]
+	if !ok {
+		return 0, false
+	}
+
+	return parseSeconds(v)
+}
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DirectivesTestSuite struct {
+	suite.Suite
+}
+
+func (suite *DirectivesTestSuite) TestParseDirectives() {
+	testCases := []struct {
+		name     string
+		values   []string
+		expected directives
+	}{
+		{
+			name:     "Empty",
+			expected: directives{},
+		},
+		{
+			name:     "Simple",
+			values:   []string{"no-cache, MAX-AGE=60"},
+			expected: directives{"no-cache": "", "max-age": "60"},
+		},
+		{
+			name:     "MultipleValues",
+			values:   []string{"private", " max-age = 30 ,, public"},
+			expected: directives{"private": "", "max-age": "30", "public": ""},
+		},
+		{
+			name:     "Quoted",
+			values:   []string{`no-cache="Set-Cookie, X-Foo", ext="a \"quoted\" value", max-age=5`},
+			expected: directives{"no-cache": "Set-Cookie, X-Foo", "ext": `a "quoted" value`, "max-age": "5"},
+		},
+		{
+			name:     "Duplicate",
+			values:   []string{"max-age=10, max-age=20"},
+			expected: directives{"max-age": "10"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			h := http.Header{}
+			for _, v := range testCase.values {
+				h.Add(cacheControlHeader, v)
+			}
+
+			suite.Equal(testCase.expected, parseDirectives(h))
+		})
+	}
+}
+
+func (suite *DirectivesTestSuite) TestParseRequestDirectives() {
+	suite.Run("Pragma", func() {
+		d := parseRequestDirectives(http.Header{pragmaHeader: {"foo, No-Cache"}})
+		suite.True(d.has("no-cache"))
+	})
+
+	suite.Run("PragmaIgnored", func() {
+		d := parseRequestDirectives(http.Header{
+			pragmaHeader:       {"no-cache"},
+			cacheControlHeader: {"max-age=10"},
+		})
+
+		suite.False(d.has("no-cache"))
+	})
+}
+
+func (suite *DirectivesTestSuite) TestSeconds() {
+	d := directives{
+		"valid":    "120",
+		"empty":    "",
+		"negative": "-1",
+		"invalid":  "1.5",
+		"huge":     "99999999999999999999999",
+	}
+
+	v, ok := d.seconds("valid")
+	suite.True(ok)
+	suite.Equal(2*time.Minute, v)
+
+	v, ok = d.seconds("huge")
+	suite.True(ok)
+	suite.Equal(time.Duration(maxDeltaSeconds)*time.Second, v)
+
+	for _, name := range []string{"missing", "empty", "negative", "invalid"} {
+		_, ok = d.seconds(name)
+		suite.False(ok, name)
+	}
+}
+
+func TestDirectives(t *testing.T) {
+	suite.Run(t, new(DirectivesTestSuite))
+}
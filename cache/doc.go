@@ -0,0 +1,30 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package cache implements HTTP caching for clients as described by RFC 9111.
+See https://www.rfc-editor.org/rfc/rfc9111.
+
+NewRoundTripper decorates an http.RoundTripper so that cacheable GET responses are stored
+and reused while they are fresh:
+
+	c := &http.Client{
+	  Transport: roundtrip.NewChain(
+	    cache.NewRoundTripper(cache.Config{
+	      Storage: cache.NewLRU(64 << 20), // 64 MiB
+	    }),
+	  ).Then(nil),
+	}
+
+Freshness is determined from the Cache-Control max-age directive, the Expires header, or
+heuristically from Last-Modified.  Stale responses that carry an ETag or Last-Modified are
+revalidated with If-None-Match and If-Modified-Since, and a 304 Not Modified is returned to
+the caller as the stored response.  Request and response directives such as no-store,
+no-cache, and only-if-cached are honored, as is the Vary header.
+
+By default, the cache is private, as is appropriate for a single client.  Config.Shared enables
+the stricter rules for shared caches, such as not storing private responses.
+
+Storage is pluggable.  LRU is an in-memory implementation bounded by the total bytes stored.
+*/
+package cache
@@ -0,0 +1,286 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ageHeader          = "Age"
+	dateHeader         = "Date"
+	expiresHeader      = "Expires"
+	lastModifiedHeader = "Last-Modified"
+	etagHeader         = "Etag"
+	varyHeader         = "Vary"
+
+	// heuristicFraction is the fraction of the time since Last-Modified that a response
+	// without explicit freshness is considered fresh.  See RFC 9111, section 4.2.2.
+	heuristicFraction = 10
+)
+
+// hopByHopHeaders are the headers that are never stored.  See RFC 9111, section 3.1.
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Connection",
+	"Proxy-Authenticate",
+	"Proxy-Authentication-Info",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// understoodStatusCodes are the status codes this package will store, given explicit
+// freshness or a public directive.
+var understoodStatusCodes = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusFound:                true,
+	http.StatusTemporaryRedirect:    true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// heuristicStatusCodes are the status codes which are heuristically cacheable.
+// See RFC 9110, section 15.1.  Partial content is excluded, since this package
+// does not handle range requests.
+var heuristicStatusCodes = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// Entry is a stored response.  Storage implementations may serialize entries however they
+// wish, but must otherwise treat them as immutable.
+type Entry struct {
+	// StatusCode is the status code of the stored response.
+	StatusCode int `json:"statusCode"`
+
+	// Header is the stored response header.
+	Header http.Header `json:"header"`
+
+	// Body is the complete response body.
+	Body []byte `json:"body"`
+
+	// Vary holds the request header values selected by the response's Vary header.
+	// A later request must have the same values in order to use this entry.
+	Vary http.Header `json:"vary,omitempty"`
+
+	// RequestTime is when the request that produced this entry was sent.
+	RequestTime time.Time `json:"requestTime"`
+
+	// ResponseTime is when the response for this entry was received.
+	ResponseTime time.Time `json:"responseTime"`
+}
+
+// headerSize computes the approximate number of bytes in an http.Header.
+func headerSize(h http.Header) (size int64) {
+	for k, values := range h {
+		for _, v := range values {
+			size += int64(len(k) + len(v))
+		}
+	}
+
+	return
+}
+
+// Size is the approximate number of bytes this entry occupies, which is
+// used to enforce limits on storage.
+func (e *Entry) Size() int64 {
+	return int64(len(e.Body)) + headerSize(e.Header) + headerSize(e.Vary)
+}
+
+// storedHeader produces the header to store from a response header.  Hop-by-hop headers
+// and any headers nominated by Connection are removed.
+func storedHeader(h http.Header) http.Header {
+	stored := h.Clone()
+	for _, v := range h.Values("Connection") {
+		for _, name := range strings.Split(v, ",") {
+			stored.Del(strings.TrimSpace(name))
+		}
+	}
+
+	for _, name := range hopByHopHeaders {
+		stored.Del(name)
+	}
+
+	return stored
+}
+
+// varyNames returns the canonicalized header names in the given response's Vary header.
+func varyNames(h http.Header) (names []string) {
+	for _, v := range h.Values(varyHeader) {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); len(name) > 0 {
+				names = append(names, textproto.CanonicalMIMEHeaderKey(name))
+			}
+		}
+	}
+
+	return
+}
+
+// newEntry creates an Entry for a response with an already read body.
+func newEntry(request *http.Request, response *http.Response, body []byte, requestTime, responseTime time.Time) *Entry {
+	e := &Entry{
+		StatusCode:   response.StatusCode,
+		Header:       storedHeader(response.Header),
+		Body:         body,
+		RequestTime:  requestTime,
+		ResponseTime: responseTime,
+	}
+
+	if names := varyNames(response.Header); len(names) > 0 {
+		e.Vary = make(http.Header, len(names))
+		for _, name := range names {
+			e.Vary[name] = append([]string{}, request.Header.Values(name)...)
+		}
+	}
+
+	return e
+}
+
+// matches tests if the given request selects this entry.  See RFC 9111, section 4.1.
+func (e *Entry) matches(request *http.Request) bool {
+	for name, values := range e.Vary {
+		if strings.Join(values, ",") != strings.Join(request.Header.Values(name), ",") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// directives parses the Cache-Control of this entry.
+func (e *Entry) directives() directives {
+	return parseDirectives(e.Header)
+}
+
+// date returns the Date of the stored response.  If there is no valid Date, the
+// time the response was received is used.
+func (e *Entry) date() time.Time {
+	if t, err := http.ParseTime(e.Header.Get(dateHeader)); err == nil {
+		return t
+	}
+
+	return e.ResponseTime
+}
+
+// age computes the current age of this entry.  See RFC 9111, section 4.2.3.
+func (e *Entry) age(now time.Time) time.Duration {
+	ageValue, _ := parseSeconds(e.Header.Get(ageHeader))
+	apparentAge := max(0, e.ResponseTime.Sub(e.date()))
+	correctedAgeValue := ageValue + e.ResponseTime.Sub(e.RequestTime)
+	return max(apparentAge, correctedAgeValue) + now.Sub(e.ResponseTime)
+}
+
+// lifetime computes the freshness lifetime of this entry.  See RFC 9111, section 4.2.1.
+func (e *Entry) lifetime(d directives, shared bool) time.Duration {
+	if shared {
+		if v, ok := d.seconds("s-maxage"); ok {
+			return v
+		}
+	}
+
+	if v, ok := d.seconds("max-age"); ok {
+		return v
+	}
+
+	if values := e.Header.Values(expiresHeader); len(values) > 0 {
+		// an invalid Expires, such as "0", represents a time in the past
+		expires, err := http.ParseTime(values[0])
+		if err != nil {
+			return 0
+		}
+
+		return max(0, expires.Sub(e.date()))
+	}
+
+	if heuristicStatusCodes[e.StatusCode] || d.has("public") {
+		if lastModified, err := http.ParseTime(e.Header.Get(lastModifiedHeader)); err == nil {
+			return max(0, e.date().Sub(lastModified)/heuristicFraction)
+		}
+	}
+
+	return 0
+}
+
+// hasValidators tests if this entry can be revalidated with a conditional request.
+func (e *Entry) hasValidators() bool {
+	return len(e.Header.Get(etagHeader)) > 0 || len(e.Header.Get(lastModifiedHeader)) > 0
+}
+
+// conditional produces a request that revalidates this entry.  See RFC 9111, section 4.3.1.
+func (e *Entry) conditional(request *http.Request) *http.Request {
+	conditional := request.Clone(request.Context())
+	if etag := e.Header.Get(etagHeader); len(etag) > 0 {
+		conditional.Header.Set("If-None-Match", etag)
+	}
+
+	if lastModified := e.Header.Get(lastModifiedHeader); len(lastModified) > 0 {
+		conditional.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	return conditional
+}
+
+// update produces a new Entry with the header fields from a 304 response.  This entry
+// is not modified.  See RFC 9111, section 3.2.
+func (e *Entry) update(notModified *http.Response, requestTime, responseTime time.Time) *Entry {
+	updated := *e
+	updated.Header = e.Header.Clone()
+	updated.RequestTime = requestTime
+	updated.ResponseTime = responseTime
+
+	for name, values := range storedHeader(notModified.Header) {
+		if name != "Content-Length" {
+			updated.Header[name] = values
+		}
+	}
+
+	return &updated
+}
+
+// response produces an HTTP response from this entry.
+func (e *Entry) response(request *http.Request, age time.Duration) *http.Response {
+	header := e.Header.Clone()
+	header.Set(ageHeader, strconv.FormatInt(int64(age/time.Second), 10))
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       request,
+	}
+}
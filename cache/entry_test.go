@@ -0,0 +1,232 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EntryTestSuite struct {
+	suite.Suite
+
+	now time.Time
+}
+
+func (suite *EntryTestSuite) SetupTest() {
+	suite.now = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+}
+
+func (suite *EntryTestSuite) newEntry(namesAndValues ...string) *Entry {
+	e := &Entry{
+		StatusCode:   http.StatusOK,
+		Header:       http.Header{},
+		RequestTime:  suite.now,
+		ResponseTime: suite.now,
+	}
+
+	for i := 0; i < len(namesAndValues); i += 2 {
+		e.Header.Add(namesAndValues[i], namesAndValues[i+1])
+	}
+
+	return e
+}
+
+func (suite *EntryTestSuite) TestNewEntry() {
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("Accept", "text/plain")
+
+	response := &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Connection":        {"close, X-Hop"},
+			"X-Hop":             {"true"},
+			"Transfer-Encoding": {"chunked"},
+			"Content-Type":      {"text/plain"},
+			"Vary":              {"accept, Accept-Language"},
+		},
+	}
+
+	e := newEntry(request, response, []byte("body"), suite.now, suite.now.Add(time.Second))
+	suite.Equal(http.StatusOK, e.StatusCode)
+	suite.Equal([]byte("body"), e.Body)
+	suite.Equal(suite.now, e.RequestTime)
+	suite.Equal(suite.now.Add(time.Second), e.ResponseTime)
+	suite.Equal(
+		http.Header{
+			"Content-Type": {"text/plain"},
+			"Vary":         {"accept, Accept-Language"},
+		},
+		e.Header,
+	)
+
+	suite.Equal(
+		http.Header{
+			"Accept":          {"text/plain"},
+			"Accept-Language": {},
+		},
+		e.Vary,
+	)
+
+	suite.Positive(e.Size())
+	suite.True(e.matches(request))
+
+	other := httptest.NewRequest("GET", "/", nil)
+	other.Header.Set("Accept", "application/json")
+	suite.False(e.matches(other))
+
+	other.Header.Set("Accept", "text/plain")
+	other.Header.Set("Accept-Language", "en")
+	suite.False(e.matches(other))
+}
+
+func (suite *EntryTestSuite) TestAge() {
+	suite.Run("Resident", func() {
+		e := suite.newEntry()
+		suite.Equal(time.Duration(0), e.age(suite.now))
+		suite.Equal(time.Minute, e.age(suite.now.Add(time.Minute)))
+	})
+
+	suite.Run("AgeHeader", func() {
+		e := suite.newEntry(ageHeader, "30")
+		e.RequestTime = suite.now.Add(-2 * time.Second)
+		suite.Equal(32*time.Second, e.age(suite.now))
+	})
+
+	suite.Run("ApparentAge", func() {
+		e := suite.newEntry(dateHeader, suite.now.Add(-time.Minute).Format(http.TimeFormat))
+		suite.Equal(time.Minute+time.Second, e.age(suite.now.Add(time.Second)))
+	})
+}
+
+func (suite *EntryTestSuite) TestLifetime() {
+	date := suite.now.Format(http.TimeFormat)
+	testCases := []struct {
+		name     string
+		entry    *Entry
+		shared   bool
+		expected time.Duration
+	}{
+		{
+			name:     "None",
+			entry:    suite.newEntry(),
+			expected: 0,
+		},
+		{
+			name:     "MaxAge",
+			entry:    suite.newEntry(cacheControlHeader, "max-age=60, s-maxage=120"),
+			expected: time.Minute,
+		},
+		{
+			name:     "SMaxAge",
+			entry:    suite.newEntry(cacheControlHeader, "max-age=60, s-maxage=120"),
+			shared:   true,
+			expected: 2 * time.Minute,
+		},
+		{
+			name: "Expires",
+			entry: suite.newEntry(
+				dateHeader, date,
+				expiresHeader, suite.now.Add(time.Hour).Format(http.TimeFormat),
+			),
+			expected: time.Hour,
+		},
+		{
+			name:     "InvalidExpires",
+			entry:    suite.newEntry(expiresHeader, "0"),
+			expected: 0,
+		},
+		{
+			name: "Heuristic",
+			entry: suite.newEntry(
+				dateHeader, date,
+				lastModifiedHeader, suite.now.Add(-10*time.Hour).Format(http.TimeFormat),
+			),
+			expected: time.Hour,
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			suite.Equal(
+				testCase.expected,
+				testCase.entry.lifetime(testCase.entry.directives(), testCase.shared),
+			)
+		})
+	}
+
+	suite.Run("NoHeuristic", func() {
+		e := suite.newEntry(lastModifiedHeader, suite.now.Add(-10*time.Hour).Format(http.TimeFormat))
+		e.StatusCode = http.StatusFound
+		suite.Zero(e.lifetime(e.directives(), false))
+	})
+}
+
+func (suite *EntryTestSuite) TestConditional() {
+	request := httptest.NewRequest("GET", "/", nil)
+	suite.False(suite.newEntry().hasValidators())
+
+	e := suite.newEntry(etagHeader, `"v1"`, lastModifiedHeader, "Fri, 01 Mar 2024 00:00:00 GMT")
+	suite.True(e.hasValidators())
+
+	conditional := e.conditional(request)
+	suite.Equal(`"v1"`, conditional.Header.Get("If-None-Match"))
+	suite.Equal("Fri, 01 Mar 2024 00:00:00 GMT", conditional.Header.Get("If-Modified-Since"))
+	suite.Empty(request.Header)
+}
+
+func (suite *EntryTestSuite) TestUpdate() {
+	e := suite.newEntry(etagHeader, `"v1"`, cacheControlHeader, "max-age=10", "Content-Length", "4")
+	updated := e.update(
+		&http.Response{
+			StatusCode: http.StatusNotModified,
+			Header: http.Header{
+				cacheControlHeader: {"max-age=20"},
+				"Content-Length":   {"0"},
+				"Connection":       {"keep-alive"},
+			},
+		},
+		suite.now.Add(time.Minute),
+		suite.now.Add(2*time.Minute),
+	)
+
+	suite.Equal("max-age=10", e.Header.Get(cacheControlHeader))
+	suite.Equal(suite.now, e.ResponseTime)
+
+	suite.Equal("max-age=20", updated.Header.Get(cacheControlHeader))
+	suite.Equal(`"v1"`, updated.Header.Get(etagHeader))
+	suite.Equal("4", updated.Header.Get("Content-Length"))
+	suite.Empty(updated.Header.Get("Connection"))
+	suite.Equal(suite.now.Add(time.Minute), updated.RequestTime)
+	suite.Equal(suite.now.Add(2*time.Minute), updated.ResponseTime)
+}
+
+func (suite *EntryTestSuite) TestResponse() {
+	request := httptest.NewRequest("GET", "/", nil)
+	e := suite.newEntry("Content-Type", "text/plain")
+	e.Body = []byte("hello")
+
+	response := e.response(request, 90*time.Second)
+	defer response.Body.Close()
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Equal("200 OK", response.Status)
+	suite.Equal("90", response.Header.Get(ageHeader))
+	suite.Equal("text/plain", response.Header.Get("Content-Type"))
+	suite.Equal(int64(5), response.ContentLength)
+	suite.Same(request, response.Request)
+
+	body, err := io.ReadAll(response.Body)
+	suite.NoError(err)
+	suite.Equal("hello", string(body))
+	suite.Empty(e.Header.Get(ageHeader))
+}
+
+func TestEntry(t *testing.T) {
+	suite.Run(t, new(EntryTestSuite))
+}
@@ -0,0 +1,324 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/xmidt-org/httpaux"
+	"github.com/xmidt-org/httpaux/clock"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+const (
+	// DefaultMaxEntrySize is the largest response body that will be stored when
+	// no positive maximum is configured.
+	DefaultMaxEntrySize int64 = 1 << 20
+)
+
+// conditionalHeaders are the request headers that make a request conditional.  Requests
+// that are already conditional are passed through, since the caller is managing validation.
+var conditionalHeaders = []string{
+	"If-Match",
+	"If-None-Match",
+	"If-Modified-Since",
+	"If-Unmodified-Since",
+	"If-Range",
+}
+
+// safeMethods are the methods that never invalidate stored responses.  See RFC 9110, section 9.2.1.
+var safeMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// Config is the set of configuration options for a caching http.RoundTripper.
+type Config struct {
+	// Storage is where responses are stored.  If unset, an LRU with DefaultLRUSize
+	// is created.
+	Storage Storage `json:"-" yaml:"-"`
+
+	// MaxEntrySize is the largest response body that will be stored.  Larger responses
+	// are passed through to the caller without being stored.  If nonpositive, DefaultMaxEntrySize
+	// is used.
+	MaxEntrySize int64 `json:"maxEntrySize" yaml:"maxEntrySize"`
+
+	// Shared indicates whether this cache is shared by multiple users, which applies the
+	// stricter rules of RFC 9111.  In particular, a shared cache does not store private
+	// responses and honors s-maxage.  By default, a cache is private.
+	Shared bool `json:"shared" yaml:"shared"`
+
+	// Clock is the optional source of time for computing the age of stored responses.
+	// If unset, clock.System is used.
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+// roundTripper is the caching decorator
+type roundTripper struct {
+	next         http.RoundTripper
+	storage      Storage
+	maxEntrySize int64
+	shared       bool
+	now          func() time.Time
+}
+
+// NewRoundTripper creates a roundtrip.Constructor that caches responses to GET requests.
+// Successful responses to unsafe methods, such as POST, invalidate any stored response for
+// the same URL.  All other requests are passed through.
+//
+// If the decorated round tripper is nil, http.DefaultTransport is used.  The decorated
+// round tripper's CloseIdleConnections method is preserved.
+func NewRoundTripper(cfg Config) roundtrip.Constructor {
+	storage := cfg.Storage
+	if storage == nil {
+		storage = NewLRU(DefaultLRUSize)
+	}
+
+	maxEntrySize := cfg.MaxEntrySize
+	if maxEntrySize < 1 {
+		maxEntrySize = DefaultMaxEntrySize
+	}
+
+	now := clock.OrSystem(cfg.Clock).Now
+	return func(next http.RoundTripper) http.RoundTripper {
+		if next == nil {
+			next = http.DefaultTransport
+		}
+
+		return roundtrip.PreserveCloseIdler(
+			next,
+			&roundTripper{
+				next:         next,
+				storage:      storage,
+				maxEntrySize: maxEntrySize,
+				shared:       cfg.Shared,
+				now:          now,
+			},
+		)
+	}
+}
+
+// cacheable tests if the given request can use the cache at all.
+func cacheable(request *http.Request, requestDirectives directives) bool {
+	if request.Method != http.MethodGet || len(request.Header.Get("Range")) > 0 || requestDirectives.has("no-store") {
+		return false
+	}
+
+	for _, name := range conditionalHeaders {
+		if len(request.Header.Get(name)) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fresh tests if a stored entry can be used without revalidation.  See RFC 9111, section 4.2.
+func (rt *roundTripper) fresh(e *Entry, requestDirectives directives, now time.Time) bool {
+	responseDirectives := e.directives()
+	if requestDirectives.has("no-cache") || responseDirectives.has("no-cache") {
+		return false
+	}
+
+	age := e.age(now)
+	if maxAge, ok := requestDirectives.seconds("max-age"); ok && age > maxAge {
+		return false
+	}
+
+	lifetime := e.lifetime(responseDirectives, rt.shared)
+	if minFresh, ok := requestDirectives.seconds("min-fresh"); ok {
+		lifetime -= minFresh
+	}
+
+	if lifetime > age {
+		return true
+	}
+
+	// the entry is stale, but the request may accept that
+	if responseDirectives.has("must-revalidate") ||
+		(rt.shared && (responseDirectives.has("proxy-revalidate") || responseDirectives.has("s-maxage"))) {
+		return false
+	}
+
+	maxStale, ok := requestDirectives["max-stale"]
+	if !ok {
+		return false
+	} else if len(maxStale) == 0 {
+		// max-stale without a value accepts a response of any staleness
+		return true
+	}
+
+	d, ok := parseSeconds(maxStale)
+	return ok && age-lifetime <= d
+}
+
+// storable tests if a response may be stored.  See RFC 9111, section 3.
+func (rt *roundTripper) storable(request *http.Request, response *http.Response) bool {
+	if !understoodStatusCodes[response.StatusCode] {
+		return false
+	}
+
+	d := parseDirectives(response.Header)
+	switch {
+	case d.has("no-store"):
+		return false
+
+	case rt.shared && d.has("private"):
+		return false
+
+	case rt.shared && len(request.Header.Get("Authorization")) > 0 &&
+		!d.has("must-revalidate") && !d.has("public") && !d.has("s-maxage"):
+		// see RFC 9111, section 3.5
+		return false
+	}
+
+	for _, name := range varyNames(response.Header) {
+		if name == "*" {
+			return false
+		}
+	}
+
+	return len(response.Header.Values(expiresHeader)) > 0 ||
+		d.has("max-age") ||
+		(rt.shared && d.has("s-maxage")) ||
+		d.has("public") ||
+		heuristicStatusCodes[response.StatusCode]
+}
+
+// gatewayTimeout is the response to an only-if-cached request that can't
+// be satisfied from storage.  See RFC 9111, section 5.2.1.7.
+func gatewayTimeout(request *http.Request) *http.Response {
+	return &http.Response{
+		Status:     "504 " + http.StatusText(http.StatusGatewayTimeout),
+		StatusCode: http.StatusGatewayTimeout,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    request,
+	}
+}
+
+func (rt *roundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	requestDirectives := parseRequestDirectives(request.Header)
+	if !cacheable(request, requestDirectives) {
+		response, err := rt.next.RoundTrip(request)
+		if err == nil && !safeMethods[request.Method] {
+			rt.invalidate(request, response)
+		}
+
+		return response, err
+	}
+
+	key := request.URL.String()
+	stored, ok := rt.storage.Get(key)
+	if ok && !stored.matches(request) {
+		stored, ok = nil, false
+	}
+
+	requestTime := rt.now()
+	switch {
+	case ok && rt.fresh(stored, requestDirectives, requestTime):
+		return stored.response(request, stored.age(requestTime)), nil
+
+	case requestDirectives.has("only-if-cached"):
+		return gatewayTimeout(request), nil
+	}
+
+	outgoing := request
+	revalidating := ok && stored.hasValidators()
+	if revalidating {
+		outgoing = stored.conditional(request)
+	}
+
+	response, err := rt.next.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+
+	responseTime := rt.now()
+	if revalidating && response.StatusCode == http.StatusNotModified {
+		// the stored response is still valid, so use it in place of the 304
+		httpaux.Cleanup(response)
+		updated := stored.update(response, requestTime, responseTime)
+		rt.storage.Set(key, updated)
+		return updated.response(request, updated.age(responseTime)), nil
+	}
+
+	if !rt.storable(request, response) {
+		if ok {
+			// the stored response has been superseded
+			rt.storage.Delete(key)
+		}
+
+		return response, nil
+	}
+
+	return rt.store(key, request, response, requestTime, responseTime)
+}
+
+// store reads the response body and stores the response.  If the body is too large, the response
+// is returned unstored with a body that yields the complete original content.
+func (rt *roundTripper) store(key string, request *http.Request, response *http.Response, requestTime, responseTime time.Time) (*http.Response, error) {
+	if response.ContentLength > rt.maxEntrySize {
+		rt.storage.Delete(key)
+		return response, nil
+	}
+
+	original := response.Body
+	body, err := io.ReadAll(io.LimitReader(original, rt.maxEntrySize+1))
+	if err != nil {
+		original.Close()
+		return nil, err
+	}
+
+	if int64(len(body)) > rt.maxEntrySize {
+		rt.storage.Delete(key)
+		response.Body = struct {
+			io.Reader
+			io.Closer
+		}{
+			Reader: io.MultiReader(bytes.NewReader(body), original),
+			Closer: original,
+		}
+
+		return response, nil
+	}
+
+	original.Close()
+	rt.storage.Set(key, newEntry(request, response, body, requestTime, responseTime))
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	response.ContentLength = int64(len(body))
+	return response, nil
+}
+
+// invalidate removes stored responses affected by a successful request with an
+// unsafe method.  See RFC 9111, section 4.4.
+func (rt *roundTripper) invalidate(request *http.Request, response *http.Response) {
+	if response.StatusCode < 200 || response.StatusCode > 399 {
+		return
+	}
+
+	rt.storage.Delete(request.URL.String())
+	for _, name := range []string{"Location", "Content-Location"} {
+		v := response.Header.Get(name)
+		if len(v) == 0 {
+			continue
+		}
+
+		if u, err := url.Parse(v); err == nil {
+			u = request.URL.ResolveReference(u)
+			if u.Scheme == request.URL.Scheme && u.Host == request.URL.Host {
+				rt.storage.Delete(u.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,538 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/clock"
+	"github.com/xmidt-org/httpaux/httpmock"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+const testURL = "http://example.com/config"
+
+type RoundTripperTestSuite struct {
+	suite.Suite
+
+	clock   *clock.Fake
+	storage *LRU
+
+	// handler produces origin responses, and requests holds what was sent to the origin
+	handler  http.HandlerFunc
+	requests []*http.Request
+}
+
+func (suite *RoundTripperTestSuite) SetupTest() {
+	suite.clock = clock.NewFake(time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC))
+	suite.storage = NewLRU(0)
+	suite.handler = nil
+	suite.requests = nil
+}
+
+// origin is the decorated round tripper, which serves requests with the suite's handler
+func (suite *RoundTripperTestSuite) origin(request *http.Request) (*http.Response, error) {
+	suite.requests = append(suite.requests, request)
+	rw := httptest.NewRecorder()
+	suite.handler(rw, request)
+	return rw.Result(), nil
+}
+
+func (suite *RoundTripperTestSuite) newRoundTripper(cfg Config) http.RoundTripper {
+	cfg.Storage = suite.storage
+	cfg.Clock = suite.clock
+	return NewRoundTripper(cfg)(roundtrip.Func(suite.origin))
+}
+
+// respond sets a handler that writes the given headers, status code, and body
+func (suite *RoundTripperTestSuite) respond(statusCode int, body string, namesAndValues ...string) {
+	suite.handler = func(rw http.ResponseWriter, _ *http.Request) {
+		for i := 0; i < len(namesAndValues); i += 2 {
+			rw.Header().Add(namesAndValues[i], namesAndValues[i+1])
+		}
+
+		rw.WriteHeader(statusCode)
+		io.WriteString(rw, body)
+	}
+}
+
+// send executes a request with the given headers, returning the response and its fully read body
+func (suite *RoundTripperTestSuite) send(rt http.RoundTripper, method string, namesAndValues ...string) (*http.Response, string) {
+	request, err := http.NewRequest(method, testURL, nil)
+	suite.Require().NoError(err)
+	for i := 0; i < len(namesAndValues); i += 2 {
+		request.Header.Add(namesAndValues[i], namesAndValues[i+1])
+	}
+
+	response, err := rt.RoundTrip(request)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(response)
+	defer response.Body.Close()
+
+	b, err := io.ReadAll(response.Body)
+	suite.Require().NoError(err)
+	return response, string(b)
+}
+
+func (suite *RoundTripperTestSuite) get(rt http.RoundTripper, namesAndValues ...string) (*http.Response, string) {
+	return suite.send(rt, "GET", namesAndValues...)
+}
+
+// assertOrigin asserts the number of requests that reached the origin
+func (suite *RoundTripperTestSuite) assertOrigin(expected int) {
+	suite.Len(suite.requests, expected)
+}
+
+func (suite *RoundTripperTestSuite) TestMaxAge() {
+	rt := suite.newRoundTripper(Config{})
+	suite.respond(http.StatusOK, "v1", cacheControlHeader, "max-age=60")
+
+	response, body := suite.get(rt)
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Equal("v1", body)
+	suite.assertOrigin(1)
+
+	suite.respond(http.StatusOK, "v2", cacheControlHeader, "max-age=60")
+	suite.clock.Add(30 * time.Second)
+	response, body = suite.get(rt)
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Equal("v1", body)
+	suite.Equal("30", response.Header.Get(ageHeader))
+	suite.assertOrigin(1)
+
+	// stale without validators, so this is a normal fetch
+	suite.clock.Add(time.Minute)
+	_, body = suite.get(rt)
+	suite.Equal("v2", body)
+	suite.assertOrigin(2)
+	suite.Empty(suite.requests[1].Header.Get("If-None-Match"))
+	suite.Empty(suite.requests[1].Header.Get("If-Modified-Since"))
+
+	_, body = suite.get(rt)
+	suite.Equal("v2", body)
+	suite.assertOrigin(2)
+}
+
+func (suite *RoundTripperTestSuite) TestExpires() {
+	rt := suite.newRoundTripper(Config{})
+	suite.respond(http.StatusOK, "v1",
+		dateHeader, suite.clock.Now().Format(http.TimeFormat),
+		expiresHeader, suite.clock.Now().Add(time.Minute).Format(http.TimeFormat),
+	)
+
+	suite.get(rt)
+	suite.clock.Add(59 * time.Second)
+	_, body := suite.get(rt)
+	suite.Equal("v1", body)
+	suite.assertOrigin(1)
+
+	suite.clock.Add(time.Second)
+	suite.get(rt)
+	suite.assertOrigin(2)
+}
+
+func (suite *RoundTripperTestSuite) TestHeuristic() {
+	rt := suite.newRoundTripper(Config{})
+	suite.respond(http.StatusOK, "v1",
+		dateHeader, suite.clock.Now().Format(http.TimeFormat),
+		lastModifiedHeader, suite.clock.Now().Add(-10*time.Hour).Format(http.TimeFormat),
+	)
+
+	suite.get(rt)
+	suite.clock.Add(59 * time.Minute)
+	suite.get(rt)
+	suite.assertOrigin(1)
+}
+
+func (suite *RoundTripperTestSuite) TestRevalidate() {
+	suite.Run("ETag", func() {
+		suite.SetupTest()
+		rt := suite.newRoundTripper(Config{})
+		suite.respond(http.StatusOK, "v1", cacheControlHeader, "max-age=10", etagHeader, `"v1"`)
+		suite.get(rt)
+
+		suite.respond(http.StatusNotModified, "", cacheControlHeader, "max-age=20", "X-Updated", "true")
+		suite.clock.Add(15 * time.Second)
+		response, body := suite.get(rt)
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal("v1", body)
+		suite.Equal("true", response.Header.Get("X-Updated"))
+		suite.Equal("0", response.Header.Get(ageHeader))
+		suite.assertOrigin(2)
+		suite.Equal(`"v1"`, suite.requests[1].Header.Get("If-None-Match"))
+
+		// the 304 refreshed the stored response
+		suite.clock.Add(15 * time.Second)
+		_, body = suite.get(rt)
+		suite.Equal("v1", body)
+		suite.assertOrigin(2)
+	})
+
+	suite.Run("LastModified", func() {
+		suite.SetupTest()
+		rt := suite.newRoundTripper(Config{})
+		lastModified := suite.clock.Now().Add(-time.Hour).Format(http.TimeFormat)
+		suite.respond(http.StatusOK, "v1", cacheControlHeader, "max-age=10", lastModifiedHeader, lastModified)
+		suite.get(rt)
+
+		suite.respond(http.StatusNotModified, "")
+		suite.clock.Add(15 * time.Second)
+		_, body := suite.get(rt)
+		suite.Equal("v1", body)
+		suite.assertOrigin(2)
+		suite.Equal(lastModified, suite.requests[1].Header.Get("If-Modified-Since"))
+	})
+
+	suite.Run("Changed", func() {
+		suite.SetupTest()
+		rt := suite.newRoundTripper(Config{})
+		suite.respond(http.StatusOK, "v1", cacheControlHeader, "max-age=10", etagHeader, `"v1"`)
+		suite.get(rt)
+
+		suite.respond(http.StatusOK, "v2", cacheControlHeader, "max-age=10", etagHeader, `"v2"`)
+		suite.clock.Add(15 * time.Second)
+		_, body := suite.get(rt)
+		suite.Equal("v2", body)
+
+		_, body = suite.get(rt)
+		suite.Equal("v2", body)
+		suite.assertOrigin(2)
+	})
+
+	suite.Run("NoCacheResponse", func() {
+		suite.SetupTest()
+		rt := suite.newRoundTripper(Config{})
+		suite.respond(http.StatusOK, "v1", cacheControlHeader, "no-cache, max-age=60", etagHeader, `"v1"`)
+		suite.get(rt)
+
+		suite.respond(http.StatusNotModified, "")
+		_, body := suite.get(rt)
+		suite.Equal("v1", body)
+		suite.assertOrigin(2)
+		suite.Equal(`"v1"`, suite.requests[1].Header.Get("If-None-Match"))
+	})
+
+	suite.Run("NoCacheRequest", func() {
+		suite.SetupTest()
+		rt := suite.newRoundTripper(Config{})
+		suite.respond(http.StatusOK, "v1", cacheControlHeader, "max-age=60", etagHeader, `"v1"`)
+		suite.get(rt)
+
+		suite.respond(http.StatusNotModified, "")
+		suite.get(rt, cacheControlHeader, "no-cache")
+		suite.get(rt, pragmaHeader, "no-cache")
+		suite.assertOrigin(3)
+	})
+}
+
+func (suite *RoundTripperTestSuite) TestRequestDirectives() {
+	suite.Run("MaxAge", func() {
+		suite.SetupTest()
+		rt := suite.newRoundTripper(Config{})
+		suite.respond(http.StatusOK, "v1", cacheControlHeader, "max-age=60")
+		suite.get(rt)
+
+		suite.clock.Add(20 * time.Second)
+		suite.get(rt, cacheControlHeader, "max-age=30")
+		suite.assertOrigin(1)
+		suite.get(rt, cacheControlHeader, "max-age=10")
+		suite.assertOrigin(2)
+	})
+
+	suite.Run("MinFresh", func() {
+		suite.SetupTest()
+		rt := suite.newRoundTripper(Config{})
+		suite.respond(http.StatusOK, "v1", cacheControlHeader, "max-age=60")
+		suite.get(rt)
+
+		suite.clock.Add(40 * time.Second)
+		suite.get(rt, cacheControlHeader, "min-fresh=10")
+		suite.assertOrigin(1)
+		suite.get(rt, cacheControlHeader, "min-fresh=30")
+		suite.assertOrigin(2)
+	})
+
+	suite.Run("MaxStale", func() {
+		suite.SetupTest()
+		rt := suite.newRoundTripper(Config{})
+		suite.respond(http.StatusOK, "v1", cacheControlHeader, "max-age=60")
+		suite.get(rt)
+
+		suite.clock.Add(90 * time.Second)
+		suite.get(rt, cacheControlHeader, "max-stale=30")
+		suite.get(rt, cacheControlHeader, "max-stale")
+		suite.assertOrigin(1)
+		suite.get(rt, cacheControlHeader, "max-stale=10")
+		suite.assertOrigin(2)
+	})
+
+	suite.Run("MaxStaleMustRevalidate", func() {
+		suite.SetupTest()
+		rt := suite.newRoundTripper(Config{})
+		suite.respond(http.StatusOK, "v1", cacheControlHeader, "max-age=60, must-revalidate")
+		suite.get(rt)
+
+		suite.clock.Add(90 * time.Second)
+		suite.get(rt, cacheControlHeader, "max-stale")
+		suite.assertOrigin(2)
+	})
+
+	suite.Run("NoStore", func() {
+		suite.SetupTest()
+		rt := suite.newRoundTripper(Config{})
+		suite.respond(http.StatusOK, "v1", cacheControlHeader, "max-age=60")
+		suite.get(rt, cacheControlHeader, "no-store")
+		suite.Zero(suite.storage.Len())
+		suite.get(rt)
+		suite.assertOrigin(2)
+	})
+
+	suite.Run("OnlyIfCached", func() {
+		suite.SetupTest()
+		rt := suite.newRoundTripper(Config{})
+		response, _ := suite.get(rt, cacheControlHeader, "only-if-cached")
+		suite.Equal(http.StatusGatewayTimeout, response.StatusCode)
+		suite.assertOrigin(0)
+
+		suite.respond(http.StatusOK, "v1", cacheControlHeader, "max-age=60")
+		suite.get(rt)
+		response, body := suite.get(rt, cacheControlHeader, "only-if-cached")
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal("v1", body)
+		suite.assertOrigin(1)
+	})
+}
+
+func (suite *RoundTripperTestSuite) TestNotStored() {
+	testCases := []struct {
+		name           string
+		cfg            Config
+		requestHeader  []string
+		statusCode     int
+		responseHeader []string
+	}{
+		{
+			name:           "NoStore",
+			statusCode:     http.StatusOK,
+			responseHeader: []string{cacheControlHeader, "no-store, max-age=60"},
+		},
+		{
+			name:           "SharedPrivate",
+			cfg:            Config{Shared: true},
+			statusCode:     http.StatusOK,
+			responseHeader: []string{cacheControlHeader, "private, max-age=60"},
+		},
+		{
+			name:           "SharedAuthorization",
+			cfg:            Config{Shared: true},
+			requestHeader:  []string{"Authorization", "Bearer token"},
+			statusCode:     http.StatusOK,
+			responseHeader: []string{cacheControlHeader, "max-age=60"},
+		},
+		{
+			name:           "VaryStar",
+			statusCode:     http.StatusOK,
+			responseHeader: []string{cacheControlHeader, "max-age=60", varyHeader, "*"},
+		},
+		{
+			name:           "NotUnderstood",
+			statusCode:     http.StatusInternalServerError,
+			responseHeader: []string{cacheControlHeader, "max-age=60"},
+		},
+		{
+			name:       "NoFreshness",
+			statusCode: http.StatusFound,
+		},
+		{
+			name:          "Range",
+			requestHeader: []string{"Range", "bytes=0-10"},
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "Conditional",
+			requestHeader: []string{"If-None-Match", `"v1"`},
+			statusCode:    http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			suite.SetupTest()
+			rt := suite.newRoundTripper(testCase.cfg)
+			suite.respond(testCase.statusCode, "body", testCase.responseHeader...)
+
+			response, body := suite.get(rt, testCase.requestHeader...)
+			suite.Equal(testCase.statusCode, response.StatusCode)
+			suite.Equal("body", body)
+			suite.Zero(suite.storage.Len())
+		})
+	}
+}
+
+func (suite *RoundTripperTestSuite) TestPrivate() {
+	rt := suite.newRoundTripper(Config{})
+	suite.respond(http.StatusOK, "v1", cacheControlHeader, "private, max-age=60")
+	suite.get(rt, "Authorization", "Bearer token")
+	suite.get(rt, "Authorization", "Bearer token")
+	suite.assertOrigin(1)
+}
+
+func (suite *RoundTripperTestSuite) TestSharedMaxAge() {
+	rt := suite.newRoundTripper(Config{Shared: true})
+	suite.respond(http.StatusOK, "v1", cacheControlHeader, "max-age=10, s-maxage=60")
+	suite.get(rt)
+	suite.clock.Add(30 * time.Second)
+	suite.get(rt)
+	suite.assertOrigin(1)
+}
+
+func (suite *RoundTripperTestSuite) TestVary() {
+	rt := suite.newRoundTripper(Config{})
+	suite.handler = func(rw http.ResponseWriter, request *http.Request) {
+		rw.Header().Set(cacheControlHeader, "max-age=60")
+		rw.Header().Set(varyHeader, "Accept")
+		io.WriteString(rw, request.Header.Get("Accept"))
+	}
+
+	_, body := suite.get(rt, "Accept", "text/plain")
+	suite.Equal("text/plain", body)
+	_, body = suite.get(rt, "Accept", "text/plain")
+	suite.Equal("text/plain", body)
+	suite.assertOrigin(1)
+
+	_, body = suite.get(rt, "Accept", "application/json")
+	suite.Equal("application/json", body)
+	suite.assertOrigin(2)
+}
+
+func (suite *RoundTripperTestSuite) TestInvalidate() {
+	rt := suite.newRoundTripper(Config{})
+	suite.respond(http.StatusOK, "v1", cacheControlHeader, "max-age=60")
+	suite.get(rt)
+	suite.Equal(1, suite.storage.Len())
+
+	// a failed unsafe request doesn't invalidate
+	suite.respond(http.StatusBadRequest, "")
+	suite.send(rt, "POST")
+	suite.Equal(1, suite.storage.Len())
+
+	// safe methods are passed through, but don't invalidate
+	suite.respond(http.StatusOK, "")
+	suite.send(rt, "HEAD")
+	suite.Equal(1, suite.storage.Len())
+
+	suite.send(rt, "PUT")
+	suite.Zero(suite.storage.Len())
+	suite.assertOrigin(4)
+
+	suite.Run("Location", func() {
+		suite.storage.Set("http://example.com/other", &Entry{})
+		suite.storage.Set("http://other.com/config", &Entry{})
+		suite.respond(http.StatusCreated, "",
+			"Location", "/other",
+			"Content-Location", "http://other.com/config",
+		)
+
+		suite.send(rt, "POST")
+		_, ok := suite.storage.Get("http://example.com/other")
+		suite.False(ok)
+		_, ok = suite.storage.Get("http://other.com/config")
+		suite.True(ok)
+	})
+}
+
+func (suite *RoundTripperTestSuite) TestMaxEntrySize() {
+	suite.Run("ContentLength", func() {
+		suite.SetupTest()
+		rt := suite.newRoundTripper(Config{MaxEntrySize: 4})
+		suite.respond(http.StatusOK, "too large", cacheControlHeader, "max-age=60", "Content-Length", "9")
+		_, body := suite.get(rt)
+		suite.Equal("too large", body)
+		suite.Zero(suite.storage.Len())
+	})
+
+	suite.Run("Streamed", func() {
+		suite.SetupTest()
+		rt := suite.newRoundTripper(Config{MaxEntrySize: 4})
+		suite.respond(http.StatusOK, "too large", cacheControlHeader, "max-age=60")
+		_, body := suite.get(rt)
+		suite.Equal("too large", body)
+		suite.Zero(suite.storage.Len())
+	})
+
+	suite.Run("Fits", func() {
+		suite.SetupTest()
+		rt := suite.newRoundTripper(Config{MaxEntrySize: 4})
+		suite.respond(http.StatusOK, "fits", cacheControlHeader, "max-age=60")
+		_, body := suite.get(rt)
+		suite.Equal("fits", body)
+		suite.Equal(1, suite.storage.Len())
+	})
+}
+
+func (suite *RoundTripperTestSuite) TestError() {
+	expectedErr := errors.New("expected")
+	next := httpmock.NewRoundTripperSuite(suite)
+	next.OnAny().Return(nil, expectedErr).Once()
+
+	rt := NewRoundTripper(Config{})(next)
+	request, err := http.NewRequest("GET", testURL, nil)
+	suite.Require().NoError(err)
+
+	response, err := rt.RoundTrip(request) //nolint:bodyclose
+	suite.ErrorIs(err, expectedErr)
+	suite.Nil(response)
+	next.AssertExpectations()
+}
+
+func (suite *RoundTripperTestSuite) TestReadError() {
+	expectedErr := errors.New("expected")
+	body := httpmock.BodyString("partial")
+	next := httpmock.NewRoundTripperSuite(suite)
+	next.OnAny().Return(
+		&http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{cacheControlHeader: {"max-age=60"}},
+			Body: struct {
+				io.Reader
+				io.Closer
+			}{
+				Reader: io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(expectedErr)),
+				Closer: body,
+			},
+		},
+		nil,
+	).Once()
+
+	rt := NewRoundTripper(Config{})(next)
+	request, err := http.NewRequest("GET", testURL, nil)
+	suite.Require().NoError(err)
+
+	response, err := rt.RoundTrip(request) //nolint:bodyclose
+	suite.ErrorIs(err, expectedErr)
+	suite.Nil(response)
+	suite.True(body.Closed())
+	next.AssertExpectations()
+}
+
+func (suite *RoundTripperTestSuite) TestCloseIdleConnections() {
+	next := &httpmock.CloseIdler{RoundTripper: httpmock.NewRoundTripperSuite(suite)}
+	next.OnCloseIdleConnections().Once()
+	roundtrip.CloseIdleConnections(NewRoundTripper(Config{})(next))
+	next.AssertExpectations()
+
+	suite.NotNil(NewRoundTripper(Config{})(nil))
+}
+
+func TestRoundTripper(t *testing.T) {
+	suite.Run(t, new(RoundTripperTestSuite))
+}
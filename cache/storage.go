@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+const (
+	// DefaultLRUSize is the maximum number of bytes an LRU stores when
+	// no positive maximum is supplied.
+	DefaultLRUSize int64 = 16 << 20
+)
+
+// Storage is the strategy for storing cached responses.  Implementations must be
+// safe for concurrent use.
+type Storage interface {
+	// Get returns the entry for the given key, if any.  Callers must not modify the returned entry.
+	Get(key string) (*Entry, bool)
+
+	// Set stores an entry, replacing any existing entry for the key.  The entry
+	// will not be modified after it is passed to this method.
+	Set(key string, e *Entry)
+
+	// Delete removes any entry for the given key.
+	Delete(key string)
+}
+
+// lruItem is the element stored in an LRU's list
+type lruItem struct {
+	key   string
+	entry *Entry
+	size  int64
+}
+
+// LRU is an in-memory Storage that evicts the least recently used entries once
+// the total size of all entries exceeds a limit.
+type LRU struct {
+	lock     sync.Mutex
+	maxBytes int64
+	size     int64
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+var _ Storage = (*LRU)(nil)
+
+// NewLRU creates an LRU that holds at most maxBytes, as computed by Entry.Size.
+// If maxBytes is nonpositive, DefaultLRUSize is used.
+func NewLRU(maxBytes int64) *LRU {
+	if maxBytes < 1 {
+		maxBytes = DefaultLRUSize
+	}
+
+	return &LRU{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry for the given key and marks it as the most recently used.
+func (l *LRU) Get(key string) (*Entry, bool) {
+	defer l.lock.Unlock()
+	l.lock.Lock()
+
+	if element, ok := l.items[key]; ok {
+		l.order.MoveToFront(element)
+		return element.Value.(*lruItem).entry, true
+	}
+
+	return nil, false
+}
+
+// remove removes an element.  The lock must be held.
+func (l *LRU) remove(element *list.Element) {
+	item := l.order.Remove(element).(*lruItem)
+	delete(l.items, item.key)
+	l.size -= item.size
+}
+
+// Set stores the given entry as the most recently used, evicting other entries as necessary.
+// An entry larger than this LRU's maximum is not stored, and any existing entry for the key is removed.
+func (l *LRU) Set(key string, e *Entry) {
+	defer l.lock.Unlock()
+	l.lock.Lock()
+
+	if element, ok := l.items[key]; ok {
+		l.remove(element)
+	}
+
+	size := e.Size()
+	if size > l.maxBytes {
+		return
+	}
+
+	for l.size+size > l.maxBytes {
+		l.remove(l.order.Back())
+	}
+
+	l.items[key] = l.order.PushFront(&lruItem{
+		key:   key,
+		entry: e,
+		size:  size,
+	})
+
+	l.size += size
+}
+
+// Delete removes the entry for the given key, if any.
+func (l *LRU) Delete(key string) {
+	defer l.lock.Unlock()
+	l.lock.Lock()
+
+	if element, ok := l.items[key]; ok {
+		l.remove(element)
+	}
+}
+
+// Len returns the number of entries currently stored.
+func (l *LRU) Len() int {
+	defer l.lock.Unlock()
+	l.lock.Lock()
+	return l.order.Len()
+}
+
+// Size returns the total size of the entries currently stored.
+func (l *LRU) Size() int64 {
+	defer l.lock.Unlock()
+	l.lock.Lock()
+	return l.size
+}
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LRUTestSuite struct {
+	suite.Suite
+}
+
+// entry creates an Entry whose Size is exactly n
+func (suite *LRUTestSuite) entry(n int) *Entry {
+	e := &Entry{Body: make([]byte, n)}
+	suite.Require().Equal(int64(n), e.Size())
+	return e
+}
+
+func (suite *LRUTestSuite) TestDefault() {
+	suite.Equal(DefaultLRUSize, NewLRU(0).maxBytes)
+	suite.Equal(DefaultLRUSize, NewLRU(-1).maxBytes)
+}
+
+func (suite *LRUTestSuite) TestGetSetDelete() {
+	l := NewLRU(100)
+	_, ok := l.Get("a")
+	suite.False(ok)
+
+	a := suite.entry(10)
+	l.Set("a", a)
+	actual, ok := l.Get("a")
+	suite.True(ok)
+	suite.Same(a, actual)
+	suite.Equal(1, l.Len())
+	suite.Equal(int64(10), l.Size())
+
+	replacement := suite.entry(20)
+	l.Set("a", replacement)
+	actual, ok = l.Get("a")
+	suite.True(ok)
+	suite.Same(replacement, actual)
+	suite.Equal(1, l.Len())
+	suite.Equal(int64(20), l.Size())
+
+	l.Delete("a")
+	l.Delete("nosuch")
+	_, ok = l.Get("a")
+	suite.False(ok)
+	suite.Zero(l.Len())
+	suite.Zero(l.Size())
+}
+
+func (suite *LRUTestSuite) TestEviction() {
+	l := NewLRU(100)
+	l.Set("a", suite.entry(40))
+	l.Set("b", suite.entry(40))
+
+	// touch a, so that b is the least recently used
+	_, ok := l.Get("a")
+	suite.True(ok)
+
+	l.Set("c", suite.entry(40))
+	_, ok = l.Get("b")
+	suite.False(ok)
+	_, ok = l.Get("a")
+	suite.True(ok)
+	_, ok = l.Get("c")
+	suite.True(ok)
+	suite.Equal(int64(80), l.Size())
+
+	// an entry that's too large is not stored and removes the existing entry
+	l.Set("a", suite.entry(101))
+	_, ok = l.Get("a")
+	suite.False(ok)
+	suite.Equal(1, l.Len())
+	suite.Equal(int64(40), l.Size())
+
+	// an entry that fills the LRU evicts everything else
+	l.Set("d", suite.entry(100))
+	suite.Equal(1, l.Len())
+	suite.Equal(int64(100), l.Size())
+}
+
+func TestLRU(t *testing.T) {
+	suite.Run(t, new(LRUTestSuite))
+}
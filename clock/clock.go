@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clock
+
+import "time"
+
+// Timer is the behavior common to timers created by a Clock.  This interface
+// mirrors the methods of *time.Timer.
+type Timer interface {
+	// C returns the channel on which the current time is sent when this timer fires.
+	// For timers created with AfterFunc, this method returns nil.
+	C() <-chan time.Time
+
+	// Stop prevents this timer from firing.  This method returns true if the
+	// timer was active, false if the timer had already fired or been stopped.
+	Stop() bool
+
+	// Reset changes this timer to fire after the given duration.  This method
+	// returns true if the timer was active.
+	Reset(time.Duration) bool
+}
+
+// Clock is a source of time and timers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTimer creates a Timer that sends the current time on its channel
+	// after the given duration.
+	NewTimer(time.Duration) Timer
+
+	// AfterFunc creates a Timer that invokes the given function after the
+	// given duration.
+	AfterFunc(time.Duration, func()) Timer
+}
+
+// System is the Clock backed by the time package.
+var System Clock = systemClock{}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (st systemTimer) C() <-chan time.Time {
+	return st.Timer.C
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return systemTimer{time.AfterFunc(d, f)}
+}
+
+// OrSystem returns the given Clock, or System if the given Clock is nil.
+// This is useful when a Clock is an optional configuration value.
+func OrSystem(c Clock) Clock {
+	if c == nil {
+		return System
+	}
+
+	return c
+}
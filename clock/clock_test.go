@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SystemTestSuite struct {
+	suite.Suite
+}
+
+func (suite *SystemTestSuite) TestNow() {
+	before := time.Now()
+	now := System.Now()
+	suite.False(now.Before(before))
+}
+
+func (suite *SystemTestSuite) TestNewTimer() {
+	t := System.NewTimer(time.Millisecond)
+	suite.Require().NotNil(t.C())
+
+	select {
+	case <-t.C():
+		// passing
+	case <-time.After(5 * time.Second):
+		suite.Fail("the timer did not fire")
+	}
+
+	suite.False(t.Stop())
+}
+
+func (suite *SystemTestSuite) TestAfterFunc() {
+	called := make(chan struct{})
+	t := System.AfterFunc(time.Millisecond, func() { close(called) })
+	suite.Nil(t.C())
+
+	select {
+	case <-called:
+		// passing
+	case <-time.After(5 * time.Second):
+		suite.Fail("the function was not called")
+	}
+}
+
+func (suite *SystemTestSuite) TestOrSystem() {
+	suite.Equal(System, OrSystem(nil))
+
+	fake := NewFake(time.Now())
+	suite.Equal(fake, OrSystem(fake))
+}
+
+func TestSystem(t *testing.T) {
+	suite.Run(t, new(SystemTestSuite))
+}
@@ -0,0 +1,21 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package clock provides an abstraction over time for code that waits or measures elapsed time.
+
+Production code uses System, which delegates to the time package.  Tests use a Fake,
+which only moves when told to.  This allows timed behavior, such as retry backoff, to be
+tested deterministically without sleeping:
+
+	fake := clock.NewFake(time.Now())
+	timer := fake.NewTimer(5 * time.Second)
+
+	fake.Add(4 * time.Second) // timer has not fired
+	fake.Add(time.Second)     // timer fires
+	<-timer.C()
+
+When the code under test waits in another goroutine, Fake.BlockUntil synchronizes the test
+with the creation of that goroutine's timers.
+*/
+package clock
@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only changes when Add or Set is called.  Timers
+// created by a Fake fire, in order, as time passes their deadlines.
+//
+// A Fake is safe for concurrent use.
+type Fake struct {
+	lock    sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	timers  []*fakeTimer
+}
+
+var _ Clock = (*Fake)(nil)
+
+// NewFake creates a Fake clock whose current time is the given start.
+func NewFake(start time.Time) *Fake {
+	f := &Fake{
+		now: start,
+	}
+
+	f.changed = sync.NewCond(&f.lock)
+	return f
+}
+
+// Now returns this clock's current time.
+func (f *Fake) Now() time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.now
+}
+
+// NewTimer creates a Timer that fires when this clock is advanced past the given duration.
+// A nonpositive duration fires the timer immediately.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	ft := &fakeTimer{
+		clock: f,
+		c:     make(chan time.Time, 1),
+	}
+
+	ft.Reset(d)
+	return ft
+}
+
+// AfterFunc creates a Timer that calls the given function when this clock is advanced past
+// the given duration.  Unlike time.AfterFunc, the function is invoked synchronously from
+// the goroutine that advances this clock.  A nonpositive duration invokes the function
+// immediately.
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	ft := &fakeTimer{
+		clock: f,
+		fn:    fn,
+	}
+
+	ft.Reset(d)
+	return ft
+}
+
+// Add advances this clock by the given duration, firing any timers whose deadlines are
+// reached.  Timers fire in deadline order, and Now reflects each timer's deadline as it fires.
+func (f *Fake) Add(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set changes this clock's current time, firing any timers whose deadlines are reached.
+// Setting the time backwards fires no timers.
+func (f *Fake) Set(t time.Time) {
+	for {
+		f.lock.Lock()
+		if len(f.timers) == 0 || f.timers[0].when.After(t) {
+			f.now = t
+			f.lock.Unlock()
+			return
+		}
+
+		ft := f.timers[0]
+		f.timers = f.timers[1:]
+		f.now = ft.when
+		f.changed.Broadcast()
+		f.lock.Unlock()
+
+		// fire outside the lock, so that callbacks may use this clock
+		ft.fire(ft.when)
+	}
+}
+
+// Timers returns the number of timers that have not yet fired or been stopped.
+func (f *Fake) Timers() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return len(f.timers)
+}
+
+// BlockUntil waits until at least n timers are pending.  This allows a test to be
+// certain that code running in other goroutines is waiting before advancing the clock.
+func (f *Fake) BlockUntil(n int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for len(f.timers) < n {
+		f.changed.Wait()
+	}
+}
+
+// schedule adds a timer.  the lock must be held.
+func (f *Fake) schedule(ft *fakeTimer) {
+	f.timers = append(f.timers, ft)
+	sort.SliceStable(f.timers, func(i, j int) bool {
+		return f.timers[i].when.Before(f.timers[j].when)
+	})
+
+	f.changed.Broadcast()
+}
+
+// unschedule removes a timer, returning true if that timer was pending.
+// the lock must be held.
+func (f *Fake) unschedule(ft *fakeTimer) bool {
+	for i, t := range f.timers {
+		if t == ft {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			f.changed.Broadcast()
+			return true
+		}
+	}
+
+	return false
+}
+
+// fakeTimer is a Timer driven by a Fake clock
+type fakeTimer struct {
+	clock *Fake
+	when  time.Time
+	c     chan time.Time
+	fn    func()
+}
+
+func (ft *fakeTimer) C() <-chan time.Time {
+	return ft.c
+}
+
+func (ft *fakeTimer) Stop() bool {
+	ft.clock.lock.Lock()
+	defer ft.clock.lock.Unlock()
+	return ft.clock.unschedule(ft)
+}
+
+func (ft *fakeTimer) Reset(d time.Duration) bool {
+	ft.clock.lock.Lock()
+	active := ft.clock.unschedule(ft)
+	ft.when = ft.clock.now.Add(d)
+	if d > 0 {
+		ft.clock.schedule(ft)
+		ft.clock.lock.Unlock()
+		return active
+	}
+
+	now := ft.clock.now
+	ft.clock.lock.Unlock()
+	ft.fire(now)
+	return active
+}
+
+func (ft *fakeTimer) fire(now time.Time) {
+	if ft.fn != nil {
+		ft.fn()
+		return
+	}
+
+	// as with time.Timer, never block if nobody has received the previous time
+	select {
+	case ft.c <- now:
+	default:
+	}
+}
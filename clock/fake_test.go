@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FakeTestSuite struct {
+	suite.Suite
+
+	start time.Time
+	fake  *Fake
+}
+
+func (suite *FakeTestSuite) SetupTest() {
+	suite.start = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	suite.fake = NewFake(suite.start)
+}
+
+// assertFired asserts that a timer's channel has the expected time available
+func (suite *FakeTestSuite) assertFired(t Timer, expected time.Time) {
+	select {
+	case actual := <-t.C():
+		suite.Equal(expected, actual)
+	default:
+		suite.Fail("the timer did not fire")
+	}
+}
+
+// assertNotFired asserts that a timer's channel has nothing available
+func (suite *FakeTestSuite) assertNotFired(t Timer) {
+	select {
+	case <-t.C():
+		suite.Fail("the timer should not have fired")
+	default:
+		// passing
+	}
+}
+
+func (suite *FakeTestSuite) TestNow() {
+	suite.Equal(suite.start, suite.fake.Now())
+
+	suite.fake.Add(time.Hour)
+	suite.Equal(suite.start.Add(time.Hour), suite.fake.Now())
+
+	suite.fake.Set(suite.start)
+	suite.Equal(suite.start, suite.fake.Now())
+}
+
+func (suite *FakeTestSuite) TestNewTimer() {
+	t := suite.fake.NewTimer(5 * time.Second)
+	suite.Equal(1, suite.fake.Timers())
+
+	suite.fake.Add(4 * time.Second)
+	suite.assertNotFired(t)
+
+	suite.fake.Add(2 * time.Second)
+	suite.assertFired(t, suite.start.Add(5*time.Second))
+	suite.Equal(suite.start.Add(6*time.Second), suite.fake.Now())
+	suite.Zero(suite.fake.Timers())
+	suite.False(t.Stop())
+}
+
+func (suite *FakeTestSuite) TestNewTimerImmediate() {
+	t := suite.fake.NewTimer(0)
+	suite.assertFired(t, suite.start)
+	suite.Zero(suite.fake.Timers())
+}
+
+func (suite *FakeTestSuite) TestStop() {
+	t := suite.fake.NewTimer(time.Second)
+	suite.True(t.Stop())
+	suite.False(t.Stop())
+	suite.Zero(suite.fake.Timers())
+
+	suite.fake.Add(time.Minute)
+	suite.assertNotFired(t)
+}
+
+func (suite *FakeTestSuite) TestReset() {
+	t := suite.fake.NewTimer(time.Second)
+	suite.True(t.Reset(time.Minute))
+
+	suite.fake.Add(time.Second)
+	suite.assertNotFired(t)
+
+	suite.fake.Add(time.Minute)
+	suite.assertFired(t, suite.start.Add(time.Minute))
+	suite.False(t.Reset(time.Second))
+	suite.Equal(1, suite.fake.Timers())
+}
+
+func (suite *FakeTestSuite) TestAfterFunc() {
+	var order []int
+	suite.fake.AfterFunc(3*time.Second, func() { order = append(order, 3) })
+	t := suite.fake.AfterFunc(2*time.Second, func() { order = append(order, 2) })
+	suite.Nil(t.C())
+
+	suite.fake.AfterFunc(time.Second, func() {
+		order = append(order, 1)
+		suite.Equal(suite.start.Add(time.Second), suite.fake.Now())
+
+		// callbacks may use the clock
+		suite.fake.AfterFunc(time.Second, func() { order = append(order, 4) })
+	})
+
+	suite.fake.Add(10 * time.Second)
+	suite.Equal([]int{1, 2, 4, 3}, order)
+	suite.Zero(suite.fake.Timers())
+}
+
+func (suite *FakeTestSuite) TestSetBackwards() {
+	t := suite.fake.NewTimer(time.Second)
+	suite.fake.Set(suite.start.Add(-time.Hour))
+	suite.assertNotFired(t)
+	suite.Equal(suite.start.Add(-time.Hour), suite.fake.Now())
+}
+
+func (suite *FakeTestSuite) TestBlockUntil() {
+	done := make(chan time.Time)
+	go func() {
+		t := suite.fake.NewTimer(time.Minute)
+		done <- <-t.C()
+	}()
+
+	suite.fake.BlockUntil(1)
+	suite.fake.Add(time.Minute)
+
+	select {
+	case actual := <-done:
+		suite.Equal(suite.start.Add(time.Minute), actual)
+	case <-time.After(5 * time.Second):
+		suite.Fail("the timer did not fire")
+	}
+}
+
+func TestFake(t *testing.T) {
+	suite.Run(t, new(FakeTestSuite))
+}
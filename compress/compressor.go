@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+// compressor is the request compression decorator
+type compressor struct {
+	next     http.RoundTripper
+	encoding string
+	level    int
+	minSize  int64
+}
+
+// NewCompressor creates a roundtrip.Constructor that compresses request bodies of at least
+// Config.MinSize bytes and sets the Content-Encoding header.  Requests without a body or that
+// already have a Content-Encoding are sent as is.
+//
+// Compressed bodies are buffered, so the outgoing request has an accurate ContentLength and a
+// GetBody that replays the compressed content.  The original request is never modified.
+//
+// If the decorated round tripper is nil, http.DefaultTransport is used.  The decorated
+// round tripper's CloseIdleConnections method is preserved.
+func NewCompressor(cfg Config) roundtrip.Constructor {
+	c := compressor{
+		encoding: cfg.encoding(),
+		level:    cfg.level(),
+		minSize:  cfg.MinSize,
+	}
+
+	if c.minSize < 1 {
+		c.minSize = DefaultMinSize
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		if next == nil {
+			next = http.DefaultTransport
+		}
+
+		decorated := c
+		decorated.next = next
+		return roundtrip.PreserveCloseIdler(next, &decorated)
+	}
+}
+
+// compress reads all of the given content and returns it compressed.
+func (c *compressor) compress(content io.Reader) ([]byte, error) {
+	var (
+		compressed bytes.Buffer
+		w          io.WriteCloser
+	)
+
+	// the level is validated at construction, so these can't fail
+	if c.encoding == Deflate {
+		w, _ = zlib.NewWriterLevel(&compressed, c.level)
+	} else {
+		w, _ = gzip.NewWriterLevel(&compressed, c.level)
+	}
+
+	if _, err := io.Copy(w, content); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return compressed.Bytes(), nil
+}
+
+// withBody sets a replayable body on the given request.
+func withBody(request *http.Request, body []byte) {
+	request.ContentLength = int64(len(body))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	request.Body, _ = request.GetBody()
+}
+
+func (c *compressor) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Body == nil || request.Body == http.NoBody ||
+		len(request.Header.Get(contentEncodingHeader)) > 0 ||
+		(request.ContentLength > 0 && request.ContentLength < c.minSize) {
+		return c.next.RoundTrip(request)
+	}
+
+	// the length may be unknown, so read enough to decide whether to compress
+	original := request.Body
+	prefix, err := io.ReadAll(io.LimitReader(original, c.minSize))
+	if err != nil {
+		original.Close()
+		return nil, err
+	}
+
+	outgoing := request.Clone(request.Context())
+	if int64(len(prefix)) < c.minSize {
+		// the entire body has been read, and it's too small to compress
+		original.Close()
+		withBody(outgoing, prefix)
+		return c.next.RoundTrip(outgoing)
+	}
+
+	compressed, err := c.compress(io.MultiReader(bytes.NewReader(prefix), original))
+	original.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	outgoing.Header.Set(contentEncodingHeader, c.encoding)
+	withBody(outgoing, compressed)
+	return c.next.RoundTrip(outgoing)
+}
@@ -0,0 +1,232 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/httpmock"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+type CompressorTestSuite struct {
+	suite.Suite
+}
+
+// decompress reads all of a body compressed with the given encoding
+func decompress(assert *assert.Assertions, encoding string, body io.Reader) string {
+	var (
+		r   io.Reader
+		err error
+	)
+
+	if encoding == Deflate {
+		r, err = zlib.NewReader(body)
+	} else {
+		r, err = gzip.NewReader(body)
+	}
+
+	if !assert.NoError(err) {
+		return ""
+	}
+
+	b, err := io.ReadAll(r)
+	assert.NoError(err)
+	return string(b)
+}
+
+// noLength hides the type of a reader, so that http.NewRequest can't compute ContentLength
+type noLength struct {
+	io.Reader
+}
+
+// trackingBody is a request body that records whether it was closed
+type trackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (tb *trackingBody) Close() error {
+	tb.closed = true
+	return nil
+}
+
+func (suite *CompressorTestSuite) newRequest(body io.Reader) *http.Request {
+	request, err := http.NewRequest("POST", "http://example.com/", body)
+	suite.Require().NoError(err)
+	return request
+}
+
+// roundTrip sends a request through the compressor, asserting what the decorated round tripper saw
+func (suite *CompressorTestSuite) roundTrip(cfg Config, request *http.Request, asserter func(*assert.Assertions, *http.Request)) {
+	next := httpmock.NewRoundTripperSuite(suite)
+	next.OnAny().AssertRequest(
+		httpmock.RequestAsserterFunc(asserter),
+	).Return(&http.Response{StatusCode: http.StatusOK, Body: httpmock.EmptyBody()}, nil).Once()
+
+	response, err := NewCompressor(cfg)(next).RoundTrip(request)
+	suite.Require().NoError(err)
+	response.Body.Close()
+	next.AssertExpectations()
+}
+
+func (suite *CompressorTestSuite) TestCompress() {
+	content := strings.Repeat("compress me ", 100)
+	testCases := []struct {
+		name     string
+		cfg      Config
+		body     io.Reader
+		expected string
+	}{
+		{
+			name:     "Gzip",
+			cfg:      Config{MinSize: 100},
+			body:     strings.NewReader(content),
+			expected: Gzip,
+		},
+		{
+			name:     "Deflate",
+			cfg:      Config{Encoding: Deflate, MinSize: 100},
+			body:     strings.NewReader(content),
+			expected: Deflate,
+		},
+		{
+			name:     "UnknownLength",
+			cfg:      Config{MinSize: 100},
+			body:     noLength{strings.NewReader(content)},
+			expected: Gzip,
+		},
+		{
+			name:     "ExactlyMinSize",
+			cfg:      Config{MinSize: int64(len(content))},
+			body:     noLength{strings.NewReader(content)},
+			expected: Gzip,
+		},
+		{
+			name:     "DefaultMinSize",
+			body:     strings.NewReader(content),
+			expected: Gzip,
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			request := suite.newRequest(testCase.body)
+			suite.roundTrip(testCase.cfg, request, func(assert *assert.Assertions, outgoing *http.Request) {
+				assert.Equal(testCase.expected, outgoing.Header.Get(contentEncodingHeader))
+				assert.Positive(outgoing.ContentLength)
+				assert.Less(outgoing.ContentLength, int64(len(content)))
+
+				compressed, err := io.ReadAll(outgoing.Body)
+				assert.NoError(err)
+				assert.Equal(outgoing.ContentLength, int64(len(compressed)))
+				assert.Equal(content, decompress(assert, testCase.expected, bytes.NewReader(compressed)))
+
+				// GetBody must replay the compressed content
+				replay, err := outgoing.GetBody()
+				if assert.NoError(err) {
+					assert.Equal(content, decompress(assert, testCase.expected, replay))
+				}
+			})
+
+			suite.Empty(request.Header.Get(contentEncodingHeader))
+		})
+	}
+}
+
+func (suite *CompressorTestSuite) TestNotCompressed() {
+	suite.Run("NoBody", func() {
+		suite.roundTrip(Config{}, suite.newRequest(nil), func(assert *assert.Assertions, outgoing *http.Request) {
+			assert.Empty(outgoing.Header.Get(contentEncodingHeader))
+		})
+	})
+
+	suite.Run("AlreadyEncoded", func() {
+		request := suite.newRequest(strings.NewReader(strings.Repeat("x", 2000)))
+		request.Header.Set(contentEncodingHeader, "br")
+		suite.roundTrip(Config{}, request, func(assert *assert.Assertions, outgoing *http.Request) {
+			assert.Same(request, outgoing)
+		})
+	})
+
+	suite.Run("SmallKnownLength", func() {
+		request := suite.newRequest(strings.NewReader("small"))
+		suite.roundTrip(Config{}, request, func(assert *assert.Assertions, outgoing *http.Request) {
+			assert.Same(request, outgoing)
+		})
+	})
+
+	suite.Run("SmallUnknownLength", func() {
+		request := suite.newRequest(noLength{strings.NewReader("small")})
+		suite.roundTrip(Config{}, request, func(assert *assert.Assertions, outgoing *http.Request) {
+			assert.Empty(outgoing.Header.Get(contentEncodingHeader))
+			assert.Equal(int64(5), outgoing.ContentLength)
+
+			b, err := io.ReadAll(outgoing.Body)
+			assert.NoError(err)
+			assert.Equal("small", string(b))
+
+			replay, err := outgoing.GetBody()
+			if assert.NoError(err) {
+				b, err = io.ReadAll(replay)
+				assert.NoError(err)
+				assert.Equal("small", string(b))
+			}
+		})
+	})
+}
+
+func (suite *CompressorTestSuite) TestReadError() {
+	expectedErr := errors.New("expected")
+	testCases := []struct {
+		name string
+		body io.Reader
+	}{
+		{
+			name: "Prefix",
+			body: iotest.ErrReader(expectedErr),
+		},
+		{
+			name: "Remainder",
+			body: io.MultiReader(strings.NewReader(strings.Repeat("x", 2000)), iotest.ErrReader(expectedErr)),
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			body := &trackingBody{Reader: testCase.body}
+			request := suite.newRequest(body)
+
+			next := httpmock.NewRoundTripperSuite(suite)
+			response, err := NewCompressor(Config{})(next).RoundTrip(request) //nolint:bodyclose
+			suite.ErrorIs(err, expectedErr)
+			suite.Nil(response)
+			suite.True(body.closed)
+			next.AssertExpectations()
+		})
+	}
+}
+
+func (suite *CompressorTestSuite) TestCloseIdleConnections() {
+	next := &httpmock.CloseIdler{RoundTripper: httpmock.NewRoundTripperSuite(suite)}
+	next.OnCloseIdleConnections().Once()
+	roundtrip.CloseIdleConnections(NewCompressor(Config{})(next))
+	next.AssertExpectations()
+
+	suite.NotNil(NewCompressor(Config{})(nil))
+}
+
+func TestCompressor(t *testing.T) {
+	suite.Run(t, new(CompressorTestSuite))
+}
@@ -0,0 +1,147 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package compress
+
+import (
+	"compress/flate"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+const (
+	// Gzip is the gzip content coding.
+	Gzip = "gzip"
+
+	// Deflate is the deflate content coding, which is the zlib format.  Responses with
+	// raw deflate content, which some servers incorrectly send, are also decompressed.
+	Deflate = "deflate"
+
+	// DefaultMinSize is the smallest request body that is compressed when no
+	// positive minimum is configured.
+	DefaultMinSize int64 = 1024
+
+	// DefaultMaxDecompressedSize is the largest decompressed response body that is
+	// allowed when no positive maximum is configured.
+	DefaultMaxDecompressedSize int64 = 10 << 20
+
+	contentEncodingHeader = "Content-Encoding"
+	acceptEncodingHeader  = "Accept-Encoding"
+)
+
+// DecompressedSizeError is returned when reading a decompressed response body
+// that exceeds the configured maximum.
+type DecompressedSizeError struct {
+	// Limit is the maximum number of decompressed bytes allowed.
+	Limit int64
+}
+
+func (dse *DecompressedSizeError) Error() string {
+	var o strings.Builder
+	o.WriteString("decompressed response body exceeds the limit of ")
+	o.WriteString(strconv.FormatInt(dse.Limit, 10))
+	o.WriteString(" bytes")
+	return o.String()
+}
+
+// Encoding is the content coding used to compress request bodies.  Gzip and Deflate
+// are the supported values.
+type Encoding string
+
+// UnmarshalText validates that the text is a supported content coding.  Matching
+// is case-insensitive.
+func (e *Encoding) UnmarshalText(text []byte) error {
+	switch v := strings.ToLower(string(text)); v {
+	case "", Gzip, Deflate:
+		*e = Encoding(v)
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported compression encoding: %s", text)
+	}
+}
+
+// Level is a compression level, as defined by compress/flate.
+type Level int
+
+// validLevel tests if v is one of the compress/flate levels
+func validLevel(v int) bool {
+	return v >= flate.HuffmanOnly && v <= flate.BestCompression
+}
+
+// UnmarshalText validates that the text is a valid compression level.
+func (l *Level) UnmarshalText(text []byte) error {
+	v, err := strconv.Atoi(string(text))
+	switch {
+	case err != nil:
+		return fmt.Errorf("invalid compression level: %s", text)
+
+	case !validLevel(v):
+		return fmt.Errorf("invalid compression level: %d", v)
+
+	default:
+		*l = Level(v)
+		return nil
+	}
+}
+
+// UnmarshalJSON validates that a JSON number is a valid compression level.
+func (l *Level) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+
+	return l.UnmarshalText(b)
+}
+
+// Config is the set of configuration options for compression middleware.
+type Config struct {
+	// Encoding is the content coding used to compress request bodies, either Gzip or
+	// Deflate.  If unset, Gzip is used.  An unsupported value fails when unmarshaled,
+	// and is otherwise treated as Gzip.
+	Encoding Encoding `json:"encoding" yaml:"encoding"`
+
+	// Level is the compression level for request bodies, as defined by compress/flate.
+	// If zero, flate.DefaultCompression is used.  An invalid level fails when unmarshaled,
+	// and is otherwise treated as flate.DefaultCompression.
+	Level Level `json:"level" yaml:"level"`
+
+	// MinSize is the smallest request body that will be compressed.  Smaller bodies
+	// are sent as is.  If nonpositive, DefaultMinSize is used.
+	MinSize int64 `json:"minSize" yaml:"minSize"`
+
+	// MaxDecompressedSize is the largest decompressed response body allowed.  Reading
+	// beyond this limit returns a *DecompressedSizeError.  If nonpositive,
+	// DefaultMaxDecompressedSize is used.
+	MaxDecompressedSize int64 `json:"maxDecompressedSize" yaml:"maxDecompressedSize"`
+}
+
+func (cfg Config) encoding() string {
+	if strings.EqualFold(string(cfg.Encoding), Deflate) {
+		return Deflate
+	}
+
+	return Gzip
+}
+
+func (cfg Config) level() int {
+	if cfg.Level == 0 || !validLevel(int(cfg.Level)) {
+		return flate.DefaultCompression
+	}
+
+	return int(cfg.Level)
+}
+
+// NewRoundTripper creates a roundtrip.Constructor that both compresses requests and
+// decompresses responses.  This is equivalent to chaining NewCompressor and NewDecompressor.
+func NewRoundTripper(cfg Config) roundtrip.Constructor {
+	compressor := NewCompressor(cfg)
+	decompressor := NewDecompressor(cfg)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return compressor(decompressor(next))
+	}
+}
@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package compress
+
+import (
+	"compress/flate"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ConfigTestSuite) TestEncoding() {
+	suite.Equal(Gzip, Config{}.encoding())
+	suite.Equal(Gzip, Config{Encoding: "GZIP"}.encoding())
+	suite.Equal(Deflate, Config{Encoding: "deflate"}.encoding())
+	suite.Equal(Deflate, Config{Encoding: "Deflate"}.encoding())
+	suite.Equal(Gzip, Config{Encoding: "br"}.encoding())
+}
+
+func (suite *ConfigTestSuite) TestLevel() {
+	suite.Equal(flate.DefaultCompression, Config{}.level())
+	suite.Equal(flate.BestSpeed, Config{Level: flate.BestSpeed}.level())
+	suite.Equal(flate.HuffmanOnly, Config{Level: flate.HuffmanOnly}.level())
+	suite.Equal(flate.DefaultCompression, Config{Level: 10}.level())
+	suite.Equal(flate.DefaultCompression, Config{Level: -3}.level())
+}
+
+func (suite *ConfigTestSuite) TestUnmarshal() {
+	testCases := []struct {
+		json        string
+		expected    Config
+		expectedErr bool
+	}{
+		{
+			json:     `{}`,
+			expected: Config{},
+		},
+		{
+			json:     `{"encoding": "GZIP", "level": 9}`,
+			expected: Config{Encoding: Gzip, Level: flate.BestCompression},
+		},
+		{
+			json:     `{"encoding": "deflate", "level": -2}`,
+			expected: Config{Encoding: Deflate, Level: flate.HuffmanOnly},
+		},
+		{
+			json:     `{"encoding": "", "level": null}`,
+			expected: Config{},
+		},
+		{
+			json:        `{"encoding": "br"}`,
+			expectedErr: true,
+		},
+		{
+			json:        `{"level": 10}`,
+			expectedErr: true,
+		},
+		{
+			json:        `{"level": -3}`,
+			expectedErr: true,
+		},
+		{
+			json:        `{"level": 1.5}`,
+			expectedErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.json, func() {
+			var actual Config
+			err := json.Unmarshal([]byte(testCase.json), &actual)
+			if testCase.expectedErr {
+				suite.Error(err)
+			} else {
+				suite.NoError(err)
+				suite.Equal(testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func (suite *ConfigTestSuite) TestLevelUnmarshalText() {
+	var l Level
+	suite.NoError(l.UnmarshalText([]byte("5")))
+	suite.Equal(Level(5), l)
+	suite.Error(l.UnmarshalText([]byte("fast")))
+	suite.Error(l.UnmarshalText([]byte("11")))
+}
+
+func (suite *ConfigTestSuite) TestNoPanic() {
+	suite.NotPanics(func() {
+		NewRoundTripper(Config{Encoding: "br", Level: 42})(nil)
+	})
+}
+
+func (suite *ConfigTestSuite) TestDecompressedSizeError() {
+	err := &DecompressedSizeError{Limit: 123}
+	suite.Contains(err.Error(), "123")
+}
+
+func TestConfig(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
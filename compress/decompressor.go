@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package compress
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/xmidt-org/httpaux/internal/limit"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+// decompressor is the response decompression decorator
+type decompressor struct {
+	next    http.RoundTripper
+	maxSize int64
+}
+
+// NewDecompressor creates a roundtrip.Constructor that decompresses gzip and deflate responses
+// to requests with an explicit Accept-Encoding header.  The Content-Encoding and Content-Length
+// headers are removed from decompressed responses, and the response's Uncompressed field is set.
+//
+// Decompression happens as the response body is read.  Reading more than Config.MaxDecompressedSize
+// bytes returns a *DecompressedSizeError.
+//
+// If the decorated round tripper is nil, http.DefaultTransport is used.  The decorated
+// round tripper's CloseIdleConnections method is preserved.
+func NewDecompressor(cfg Config) roundtrip.Constructor {
+	maxSize := cfg.MaxDecompressedSize
+	if maxSize < 1 {
+		maxSize = DefaultMaxDecompressedSize
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		if next == nil {
+			next = http.DefaultTransport
+		}
+
+		return roundtrip.PreserveCloseIdler(
+			next,
+			&decompressor{
+				next:    next,
+				maxSize: maxSize,
+			},
+		)
+	}
+}
+
+// responseEncoding returns the single content coding of a response that this package can
+// decompress.  If the response has no content coding, multiple codings, or an unsupported
+// coding, this function returns the empty string.
+func responseEncoding(response *http.Response) string {
+	values := response.Header.Values(contentEncodingHeader)
+	if len(values) != 1 {
+		return ""
+	}
+
+	switch strings.ToLower(strings.TrimSpace(values[0])) {
+	case Gzip, "x-gzip":
+		return Gzip
+
+	case Deflate:
+		return Deflate
+
+	default:
+		return ""
+	}
+}
+
+func (d *decompressor) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := d.next.RoundTrip(request)
+	if err != nil || len(request.Header.Get(acceptEncodingHeader)) == 0 ||
+		request.Method == http.MethodHead || response.Body == nil || response.Body == http.NoBody {
+		return response, err
+	}
+
+	encoding := responseEncoding(response)
+	if len(encoding) == 0 {
+		return response, nil
+	}
+
+	response.Body = &decompressedBody{
+		encoding: encoding,
+		original: response.Body,
+		limit:    d.maxSize,
+	}
+
+	response.Header.Del(contentEncodingHeader)
+	response.Header.Del("Content-Length")
+	response.ContentLength = -1
+	response.Uncompressed = true
+	return response, nil
+}
+
+// isZlib tests if the given bytes are a valid zlib header using the deflate method.
+// See RFC 1950, section 2.2.
+func isZlib(header []byte) bool {
+	return len(header) == 2 &&
+		header[0]&0x0f == 8 &&
+		(uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
+
+// decompressedBody is a response body that decompresses the original body as it is
+// read.  The decompressing reader is created lazily, so that a malformed body produces
+// an error when read rather than from the round trip.
+type decompressedBody struct {
+	encoding string
+	original io.ReadCloser
+	limit    int64
+
+	reader  io.ReadCloser
+	limited *limit.Reader
+	err     error
+}
+
+func (db *decompressedBody) init() error {
+	var (
+		reader io.ReadCloser
+		err    error
+	)
+
+	if db.encoding == Gzip {
+		reader, err = gzip.NewReader(db.original)
+	} else {
+		br := bufio.NewReader(db.original)
+		if header, _ := br.Peek(2); isZlib(header) {
+			reader, err = zlib.NewReader(br)
+		} else {
+			// raw deflate, which some servers send
+			reader = flate.NewReader(br)
+		}
+	}
+
+	if err == nil {
+		db.reader = reader
+		db.limited = &limit.Reader{
+			R:     reader,
+			Limit: db.limit,
+			Err:   &DecompressedSizeError{Limit: db.limit},
+		}
+	}
+
+	return err
+}
+
+func (db *decompressedBody) Read(p []byte) (int, error) {
+	if db.err != nil {
+		return 0, db.err
+	}
+
+	if db.limited == nil {
+		if db.err = db.init(); db.err != nil {
+			return 0, db.err
+		}
+	}
+
+	return db.limited.Read(p)
+}
+
+func (db *decompressedBody) Close() error {
+	if db.reader != nil {
+		db.reader.Close()
+	}
+
+	return db.original.Close()
+}
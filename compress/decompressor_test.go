@@ -0,0 +1,288 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package compress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/httpmock"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+type DecompressorTestSuite struct {
+	suite.Suite
+}
+
+// compressed produces content in the given format: "gzip", "zlib", or "flate"
+func (suite *DecompressorTestSuite) compressed(format, content string) []byte {
+	var (
+		b bytes.Buffer
+		w io.WriteCloser
+	)
+
+	switch format {
+	case "gzip":
+		w = gzip.NewWriter(&b)
+
+	case "zlib":
+		w = zlib.NewWriter(&b)
+
+	default:
+		var err error
+		w, err = flate.NewWriter(&b, flate.DefaultCompression)
+		suite.Require().NoError(err)
+	}
+
+	_, err := io.WriteString(w, content)
+	suite.Require().NoError(err)
+	suite.Require().NoError(w.Close())
+	return b.Bytes()
+}
+
+// roundTrip sends a request through the decompressor with a mocked response
+func (suite *DecompressorTestSuite) roundTrip(cfg Config, request *http.Request, contentEncoding string, body []byte) (*http.Response, *httpmock.BodyReadCloser) {
+	original := httpmock.BodyBytes(body)
+	header := http.Header{
+		"Content-Length": {strconv.Itoa(len(body))},
+	}
+
+	if len(contentEncoding) > 0 {
+		header.Set(contentEncodingHeader, contentEncoding)
+	}
+
+	next := httpmock.NewRoundTripperSuite(suite)
+	next.OnAny().Return(
+		&http.Response{
+			StatusCode:    http.StatusOK,
+			Header:        header,
+			Body:          original,
+			ContentLength: int64(len(body)),
+		},
+		nil,
+	).Once()
+
+	response, err := NewDecompressor(cfg)(next).RoundTrip(request)
+	suite.Require().NoError(err)
+	next.AssertExpectations()
+	return response, original
+}
+
+func (suite *DecompressorTestSuite) newRequest(method, acceptEncoding string) *http.Request {
+	request, err := http.NewRequest(method, "http://example.com/", nil)
+	suite.Require().NoError(err)
+	if len(acceptEncoding) > 0 {
+		request.Header.Set(acceptEncodingHeader, acceptEncoding)
+	}
+
+	return request
+}
+
+func (suite *DecompressorTestSuite) TestDecompress() {
+	const content = "here is some lovely content"
+	testCases := []struct {
+		contentEncoding string
+		format          string
+	}{
+		{contentEncoding: "gzip", format: "gzip"},
+		{contentEncoding: "X-Gzip", format: "gzip"},
+		{contentEncoding: "deflate", format: "zlib"},
+		{contentEncoding: "deflate", format: "flate"},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.contentEncoding+"/"+testCase.format, func() {
+			response, original := suite.roundTrip(
+				Config{},
+				suite.newRequest("GET", "gzip, deflate"),
+				testCase.contentEncoding,
+				suite.compressed(testCase.format, content),
+			)
+
+			suite.Empty(response.Header.Get(contentEncodingHeader))
+			suite.Empty(response.Header.Get("Content-Length"))
+			suite.Equal(int64(-1), response.ContentLength)
+			suite.True(response.Uncompressed)
+
+			b, err := io.ReadAll(response.Body)
+			suite.NoError(err)
+			suite.Equal(content, string(b))
+
+			suite.NoError(response.Body.Close())
+			suite.True(original.Closed())
+		})
+	}
+}
+
+func (suite *DecompressorTestSuite) TestPassThrough() {
+	compressed := suite.compressed("gzip", "content")
+	testCases := []struct {
+		name            string
+		request         *http.Request
+		contentEncoding string
+	}{
+		{
+			name:            "NoAcceptEncoding",
+			request:         suite.newRequest("GET", ""),
+			contentEncoding: Gzip,
+		},
+		{
+			name:            "Head",
+			request:         suite.newRequest("HEAD", "gzip"),
+			contentEncoding: Gzip,
+		},
+		{
+			name:    "NotEncoded",
+			request: suite.newRequest("GET", "gzip"),
+		},
+		{
+			name:            "Unsupported",
+			request:         suite.newRequest("GET", "br"),
+			contentEncoding: "br",
+		},
+		{
+			name:            "MultipleCodings",
+			request:         suite.newRequest("GET", "gzip"),
+			contentEncoding: "gzip, gzip",
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			response, original := suite.roundTrip(Config{}, testCase.request, testCase.contentEncoding, compressed)
+			suite.Same(original, response.Body)
+			suite.Equal(testCase.contentEncoding, response.Header.Get(contentEncodingHeader))
+			suite.Equal(int64(len(compressed)), response.ContentLength)
+			suite.False(response.Uncompressed)
+			response.Body.Close()
+		})
+	}
+}
+
+func (suite *DecompressorTestSuite) TestMaxDecompressedSize() {
+	suite.Run("Exceeded", func() {
+		response, _ := suite.roundTrip(
+			Config{MaxDecompressedSize: 10},
+			suite.newRequest("GET", "gzip"),
+			Gzip,
+			suite.compressed("gzip", strings.Repeat("x", 1000)),
+		)
+
+		defer response.Body.Close()
+		b, err := io.ReadAll(response.Body)
+		suite.Equal(strings.Repeat("x", 10), string(b))
+
+		var dse *DecompressedSizeError
+		suite.Require().ErrorAs(err, &dse)
+		suite.Equal(int64(10), dse.Limit)
+
+		// subsequent reads continue to fail
+		_, err = response.Body.Read(make([]byte, 1))
+		suite.ErrorAs(err, &dse)
+	})
+
+	suite.Run("ExactlyAtLimit", func() {
+		response, _ := suite.roundTrip(
+			Config{MaxDecompressedSize: 10},
+			suite.newRequest("GET", "gzip"),
+			Gzip,
+			suite.compressed("gzip", strings.Repeat("x", 10)),
+		)
+
+		defer response.Body.Close()
+		b, err := io.ReadAll(response.Body)
+		suite.NoError(err)
+		suite.Equal(strings.Repeat("x", 10), string(b))
+	})
+
+	suite.Run("MaxInt64", func() {
+		response, _ := suite.roundTrip(
+			Config{MaxDecompressedSize: math.MaxInt64},
+			suite.newRequest("GET", "gzip"),
+			Gzip,
+			suite.compressed("gzip", "content"),
+		)
+
+		defer response.Body.Close()
+		b, err := io.ReadAll(response.Body)
+		suite.NoError(err)
+		suite.Equal("content", string(b))
+	})
+}
+
+func (suite *DecompressorTestSuite) TestMalformed() {
+	response, original := suite.roundTrip(
+		Config{},
+		suite.newRequest("GET", "gzip"),
+		Gzip,
+		[]byte("this is not gzip"),
+	)
+
+	_, err := io.ReadAll(response.Body)
+	suite.ErrorIs(err, gzip.ErrHeader)
+	suite.NoError(response.Body.Close())
+	suite.True(original.Closed())
+}
+
+func (suite *DecompressorTestSuite) TestError() {
+	expectedErr := errors.New("expected")
+	next := httpmock.NewRoundTripperSuite(suite)
+	next.OnAny().Return(nil, expectedErr).Once()
+
+	response, err := NewDecompressor(Config{})(next).RoundTrip(suite.newRequest("GET", "gzip")) //nolint:bodyclose
+	suite.ErrorIs(err, expectedErr)
+	suite.Nil(response)
+	next.AssertExpectations()
+}
+
+func (suite *DecompressorTestSuite) TestCloseIdleConnections() {
+	next := &httpmock.CloseIdler{RoundTripper: httpmock.NewRoundTripperSuite(suite)}
+	next.OnCloseIdleConnections().Once()
+	roundtrip.CloseIdleConnections(NewDecompressor(Config{})(next))
+	next.AssertExpectations()
+
+	suite.NotNil(NewDecompressor(Config{})(nil))
+}
+
+func (suite *DecompressorTestSuite) TestNewRoundTripper() {
+	const content = "round trip content that is long enough to be compressed"
+	next := httpmock.NewRoundTripperSuite(suite)
+	next.OnAny().AssertRequest(
+		httpmock.Header(contentEncodingHeader, Gzip),
+	).Return(
+		&http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{contentEncodingHeader: {Gzip}},
+			Body:       httpmock.BodyBytes(suite.compressed("gzip", content)),
+		},
+		nil,
+	).Once()
+
+	request, err := http.NewRequest("POST", "http://example.com/", strings.NewReader(content))
+	suite.Require().NoError(err)
+	request.Header.Set(acceptEncodingHeader, Gzip)
+
+	response, err := NewRoundTripper(Config{MinSize: 10})(next).RoundTrip(request)
+	suite.Require().NoError(err)
+	defer response.Body.Close()
+
+	b, err := io.ReadAll(response.Body)
+	suite.NoError(err)
+	suite.Equal(content, string(b))
+	next.AssertExpectations()
+}
+
+func TestDecompressor(t *testing.T) {
+	suite.Run(t, new(DecompressorTestSuite))
+}
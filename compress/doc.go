@@ -0,0 +1,21 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package compress provides round tripper middleware for compressed HTTP content.
+
+http.Transport only decompresses gzip responses, and only when it added the Accept-Encoding
+header itself.  NewDecompressor decompresses gzip and deflate responses to requests that set
+Accept-Encoding explicitly, enforcing a limit on the decompressed size to guard against
+decompression bombs.  NewCompressor compresses request bodies that are at least a minimum size:
+
+	rt := roundtrip.NewChain(
+	  compress.NewRoundTripper(compress.Config{
+	    Encoding: compress.Gzip,
+	    MinSize:  4096,
+	  }),
+	).Then(nil)
+
+NewRoundTripper applies both.
+*/
+package compress
@@ -82,6 +82,9 @@ type errorContext struct {
 	ctx context.Context
 	err error
 	rw  http.ResponseWriter
+
+	// fields are the extra fields produced by any ContextFielders
+	fields []interface{}
 }
 
 // errorEncoder represents an actual rule implementation that can
@@ -149,7 +152,18 @@ func (ie *isEncoder) newErrorEncoder(disableBody bool) errorEncoder {
 	body, _ := json.Marshal(ie.fields)
 	return func(ec errorContext) bool {
 		handled := ie.encodeNoBody(ec)
-		if handled {
+		switch {
+		case !handled:
+			// nothing to write
+
+		case len(ec.fields) > 0:
+			// the precomputed body can't be used, since there are fields from the context
+			fields := ie.fields.Clone()
+			fields.Add(ec.fields...)
+			b, _ := json.Marshal(fields)
+			ec.rw.Write(b)
+
+		default:
 			ec.rw.Write(body)
 		}
 
@@ -251,6 +265,7 @@ func (ae *asEncoder) newErrorEncoder(disableBody bool) errorEncoder {
 			}
 
 			fields.Add(fieldsFor(target)...)
+			fields.Add(ec.fields...)
 			body, _ := json.Marshal(fields)
 			ec.rw.Write(body)
 		}
@@ -336,8 +351,9 @@ func As(target interface{}) EncoderRule {
 // optional interfaces that custom errors may implement that can tailor parts of the
 // HTTP response.
 type Encoder struct {
-	encoders    []errorEncoder
-	disableBody bool
+	encoders        []errorEncoder
+	contextFielders []ContextFielder
+	disableBody     bool
 }
 
 // Body controls whether HTTP response bodies are rendered for any rules added
@@ -367,6 +383,21 @@ func (e Encoder) Add(rules ...EncoderRule) Encoder {
 	return e
 }
 
+// ContextFields adds ContextFielders that produce extra JSON fields from the context passed
+// to Encode.  Unlike Body, this applies to every rule, including rules added before
+// this method was called, as well as the default JSON representation.  Fields from the context
+// are added after all other fields, so they take precedence over fields with the same name.
+//
+// Errors rendered without a body are unaffected.
+func (e Encoder) ContextFields(cfs ...ContextFielder) Encoder {
+	e.contextFielders = append(
+		append([]ContextFielder(nil), e.contextFielders...),
+		cfs...,
+	)
+
+	return e
+}
+
 // Encode is a gokit-style error encoder.  Each rule in this encoder is tried in the order
 // they were added via Add.  If no rule can handle the error, a default JSON representation
 // is used.
@@ -400,9 +431,10 @@ func (e Encoder) Encode(ctx context.Context, err error, rw http.ResponseWriter)
 	rw.Header().Set("Content-Type", "application/json")
 
 	ec := errorContext{
-		ctx: ctx,
-		err: err,
-		rw:  rw,
+		ctx:    ctx,
+		err:    err,
+		rw:     rw,
+		fields: fieldsFromContext(ctx, e.contextFielders),
 	}
 
 	for _, rule := range e.encoders {
@@ -427,6 +459,7 @@ func (e Encoder) Encode(ctx context.Context, err error, rw http.ResponseWriter)
 		)
 
 		fields.Add(fieldsFor(err)...)
+		fields.Add(ec.fields...)
 		body, _ := json.Marshal(fields)
 		rw.Write(body)
 	}
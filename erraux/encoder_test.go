@@ -429,6 +429,84 @@ func (suite *EncoderTestSuite) TestFallthroughWithCustom() {
 	)
 }
 
+type contextFieldKey struct{}
+
+// contextFields is a ContextFielder that emits the value of contextFieldKey, if present
+func contextFields(ctx context.Context) []interface{} {
+	if v, ok := ctx.Value(contextFieldKey{}).(string); ok {
+		return []interface{}{"fromContext", v}
+	}
+
+	return nil
+}
+
+func (suite *EncoderTestSuite) TestContextFields() {
+	var (
+		isErr = errors.New("is error")
+		asErr = &Error{Err: errors.New("as error"), Code: 503}
+		ctx   = context.WithValue(context.Background(), contextFieldKey{}, "value")
+
+		e = Encoder{}.Add(
+			Is(isErr),
+			As((*Error)(nil)),
+		).ContextFields(contextFields)
+	)
+
+	testCases := []struct {
+		name         string
+		ctx          context.Context
+		err          error
+		expectedBody string
+	}{
+		{
+			name:         "Is",
+			ctx:          ctx,
+			err:          isErr,
+			expectedBody: `{"code": 500, "cause": "is error", "fromContext": "value"}`,
+		},
+		{
+			name:         "IsNoContextFields",
+			ctx:          context.Background(),
+			err:          isErr,
+			expectedBody: `{"code": 500, "cause": "is error"}`,
+		},
+		{
+			name:         "As",
+			ctx:          ctx,
+			err:          asErr,
+			expectedBody: `{"code": 503, "cause": "as error", "fromContext": "value"}`,
+		},
+		{
+			name:         "Fallback",
+			ctx:          ctx,
+			err:          errors.New("fallback error"),
+			expectedBody: `{"code": 500, "cause": "fallback error", "fromContext": "value"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			response := httptest.NewRecorder()
+			e.Encode(testCase.ctx, testCase.err, response)
+
+			_, _, body := suite.result(response)
+			suite.JSONEq(testCase.expectedBody, body)
+		})
+	}
+
+	suite.Run("Immutable", func() {
+		// adding to a copy must not affect the original
+		_ = e.ContextFields(func(context.Context) []interface{} {
+			return []interface{}{"other", true}
+		})
+
+		response := httptest.NewRecorder()
+		e.Encode(ctx, isErr, response)
+		_, _, body := suite.result(response)
+		suite.JSONEq(`{"code": 500, "cause": "is error", "fromContext": "value"}`, body)
+	})
+}
+
 func TestEncoder(t *testing.T) {
 	suite.Run(t, new(EncoderTestSuite))
 }
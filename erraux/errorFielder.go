@@ -4,6 +4,7 @@
 package erraux
 
 import (
+	"context"
 	"errors"
 	"net/http"
 )
@@ -33,6 +34,21 @@ func fieldsFor(err error) (fields []interface{}) {
 	return
 }
 
+// ContextFielder produces custom fields in the rendered JSON from the context passed
+// to Encoder.Encode.  The returned slice is in the same format as Fields.Add.  This allows
+// request-scoped information, such as a request identifier, to appear in every error body
+// regardless of the error.
+type ContextFielder func(context.Context) []interface{}
+
+// fieldsFromContext invokes each ContextFielder in order and concatenates the results.
+func fieldsFromContext(ctx context.Context, cfs []ContextFielder) (fields []interface{}) {
+	for _, cf := range cfs {
+		fields = append(fields, cf(ctx)...)
+	}
+
+	return
+}
+
 // Fields holds JSON fields for an error.
 type Fields map[string]interface{}
 
@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package limit enforces size limits on content read from untrusted sources.
+package limit
+
+import "io"
+
+// Reader is an io.Reader that fails once more than Limit bytes would have been read
+// from R.  Unlike io.LimitReader, which silently truncates, exceeding the limit returns
+// Err.  Content that is exactly Limit bytes long is read without error.
+//
+// Once R or the limit produces an error, that error is returned from all subsequent reads.
+type Reader struct {
+	// R is the underlying reader.
+	R io.Reader
+
+	// Limit is the maximum number of bytes that may be read.
+	Limit int64
+
+	// Err is the error returned once reading would exceed Limit.
+	Err error
+
+	read int64
+	err  error
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	// never read more than one byte past the limit.  remaining+1 is only computed when
+	// it is smaller than len(p), so this cannot overflow even when Limit is math.MaxInt64.
+	if remaining := r.Limit - r.read; int64(len(p))-1 > remaining {
+		p = p[:remaining+1]
+	}
+
+	n, err := r.R.Read(p)
+	r.read += int64(n)
+	if r.read > r.Limit {
+		n -= int(r.read - r.Limit)
+		r.read = r.Limit
+		err = r.Err
+	}
+
+	if err != nil {
+		r.err = err
+	}
+
+	return n, err
+}
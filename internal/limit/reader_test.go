@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package limit
+
+import (
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ReaderTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ReaderTestSuite) TestWithinLimit() {
+	for _, content := range []string{"", "short", "exact"} {
+		suite.Run(content, func() {
+			r := &Reader{R: strings.NewReader(content), Limit: 5, Err: errors.New("expected")}
+			b, err := io.ReadAll(r)
+			suite.NoError(err)
+			suite.Equal(content, string(b))
+		})
+	}
+}
+
+func (suite *ReaderTestSuite) TestExceedsLimit() {
+	expectedErr := errors.New("expected")
+	r := &Reader{R: strings.NewReader("this is too long"), Limit: 5, Err: expectedErr}
+	b, err := io.ReadAll(r)
+	suite.Equal("this ", string(b))
+	suite.Same(expectedErr, err)
+
+	// the error is sticky
+	n, err := r.Read(make([]byte, 10))
+	suite.Zero(n)
+	suite.Same(expectedErr, err)
+}
+
+func (suite *ReaderTestSuite) TestSmallReads() {
+	expectedErr := errors.New("expected")
+	r := &Reader{R: iotest.OneByteReader(strings.NewReader("abcdef")), Limit: 3, Err: expectedErr}
+	b, err := io.ReadAll(r)
+	suite.Equal("abc", string(b))
+	suite.Same(expectedErr, err)
+}
+
+func (suite *ReaderTestSuite) TestMaxLimit() {
+	r := &Reader{R: strings.NewReader("content"), Limit: math.MaxInt64, Err: errors.New("expected")}
+	b, err := io.ReadAll(r)
+	suite.NoError(err)
+	suite.Equal("content", string(b))
+}
+
+func (suite *ReaderTestSuite) TestReadError() {
+	expectedErr := errors.New("expected")
+	r := &Reader{R: iotest.ErrReader(expectedErr), Limit: 5, Err: errors.New("limit")}
+	_, err := r.Read(make([]byte, 10))
+	suite.Same(expectedErr, err)
+
+	_, err = r.Read(make([]byte, 10))
+	suite.Same(expectedErr, err)
+}
+
+func TestReader(t *testing.T) {
+	suite.Run(t, new(ReaderTestSuite))
+}
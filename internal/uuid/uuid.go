@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package uuid generates the random UUIDs used as request identifiers and
+// idempotency keys.
+package uuid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// NewV4 generates a random, version 4 UUID in its canonical textual form.
+func NewV4() string {
+	var u [16]byte
+	_, _ = rand.Read(u[:]) // never returns an error, see crypto/rand.Read
+
+	u[6] = (u[6] & 0x0f) | 0x40 // version 4
+	u[8] = (u[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	var text [36]byte
+	hex.Encode(text[0:8], u[0:4])
+	text[8] = '-'
+	hex.Encode(text[9:13], u[4:6])
+	text[13] = '-'
+	hex.Encode(text[14:18], u[6:8])
+	text[18] = '-'
+	hex.Encode(text[19:23], u[8:10])
+	text[23] = '-'
+	hex.Encode(text[24:], u[10:])
+
+	return string(text[:])
+}
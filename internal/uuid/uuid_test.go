@@ -0,0 +1,30 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package uuid
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type UUIDTestSuite struct {
+	suite.Suite
+}
+
+func (suite *UUIDTestSuite) TestNewV4() {
+	format := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		u := NewV4()
+		suite.Regexp(format, u)
+		suite.False(seen[u])
+		seen[u] = true
+	}
+}
+
+func TestUUID(t *testing.T) {
+	suite.Run(t, new(UUIDTestSuite))
+}
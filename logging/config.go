@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"log/slog"
+
+	"github.com/xmidt-org/httpaux/clock"
+)
+
+const (
+	// DefaultMessage is used when Config.Message is unset.
+	DefaultMessage = "request"
+
+	// Redacted is the value logged in place of sensitive information.
+	Redacted = "REDACTED"
+)
+
+// defaultRedactedHeaders are the headers that are always redacted
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// defaultRedactedQuery are the query parameters that are always redacted
+var defaultRedactedQuery = []string{
+	"access_token",
+	"api_key",
+	"apikey",
+	"key",
+	"password",
+	"secret",
+	"token",
+}
+
+// Config is the set of configuration options for logging middleware.
+type Config struct {
+	// Logger is the destination for log entries.  If unset, slog.Default() is used.
+	Logger *slog.Logger `json:"-" yaml:"-"`
+
+	// Message is the message for each log entry.  If unset, DefaultMessage is used.
+	Message string `json:"message" yaml:"message"`
+
+	// Level is the level for log entries of successful transactions.  Transactions that
+	// fail with an error or a 5xx status code are always logged at slog.LevelError.
+	Level slog.Level `json:"level" yaml:"level"`
+
+	// Headers are the request headers to include in each log entry.  By default, no
+	// headers are logged.  Any header that is redacted will have its value replaced
+	// with Redacted.
+	Headers []string `json:"headers" yaml:"headers"`
+
+	// RedactHeaders are additional headers to redact.  The Authorization, Proxy-Authorization,
+	// Cookie, Set-Cookie, and X-Api-Key headers are always redacted.
+	RedactHeaders []string `json:"redactHeaders" yaml:"redactHeaders"`
+
+	// RedactQuery are additional query parameters to redact from logged URLs.  The access_token,
+	// api_key, apikey, key, password, secret, and token parameters are always redacted.  Query
+	// parameter names are matched without regard to case.
+	RedactQuery []string `json:"redactQuery" yaml:"redactQuery"`
+
+	// SampleRate is the fraction of successful transactions that are logged.  Failed
+	// transactions are always logged.  If this value is not in the open range (0.0, 1.0),
+	// every transaction is logged.
+	SampleRate float64 `json:"sampleRate" yaml:"sampleRate"`
+
+	// Random is the optional source of random numbers in [0.0, 1.0) used for sampling.
+	// If unset, math/rand.Float64 is used.
+	Random func() float64 `json:"-" yaml:"-"`
+
+	// Clock is the optional source of time used to measure durations.  If unset,
+	// clock.System is used.
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
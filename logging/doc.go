@@ -0,0 +1,34 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package logging provides log/slog middleware for HTTP clients, round trippers, and servers.
+
+Each HTTP transaction produces one log entry with the method, the URL, the status code,
+the duration, the number of response bytes, and any error.  When a request is being retried
+by the retry package, the attempt number is logged as well.
+
+Sensitive information is redacted before anything is logged.  Authorization, cookie, and API key
+headers and common credential query parameters are always redacted, as are passwords in URLs.
+Additional headers and query parameters can be redacted through Config:
+
+	cfg := logging.Config{
+	  Logger: slog.Default(),
+	  Headers: []string{"User-Agent", "X-Tenant"},
+	  RedactHeaders: []string{"X-Tenant"},
+	  RedactQuery: []string{"session"},
+	  SampleRate: 0.1,
+	}
+
+	c := client.NewChain(logging.NewClient(cfg)).Then(new(http.Client))
+	rt := roundtrip.NewChain(logging.NewRoundTripper(cfg)).Then(http.DefaultTransport)
+	h := logging.NewServer(cfg)(myHandler)
+
+To log each retry attempt, place the logging middleware inside a retry.Client:
+
+	c := client.NewChain(
+	  retry.New(retryConfig, nil).Then,
+	  logging.NewClient(cfg),
+	).Then(new(http.Client))
+*/
+package logging
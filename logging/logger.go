@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/xmidt-org/httpaux/clock"
+	"github.com/xmidt-org/httpaux/retry"
+)
+
+// logger is the common implementation for all logging middleware
+type logger struct {
+	logger     *slog.Logger
+	message    string
+	level      slog.Level
+	sampleRate float64
+	random     func() float64
+	now        func() time.Time
+	redactor   redactor
+}
+
+func newLogger(cfg Config) *logger {
+	l := &logger{
+		logger:     cfg.Logger,
+		message:    cfg.Message,
+		level:      cfg.Level,
+		sampleRate: cfg.SampleRate,
+		random:     cfg.Random,
+		now:        clock.OrSystem(cfg.Clock).Now,
+		redactor:   newRedactor(cfg),
+	}
+
+	if l.logger == nil {
+		l.logger = slog.Default()
+	}
+
+	if len(l.message) == 0 {
+		l.message = DefaultMessage
+	}
+
+	if l.random == nil {
+		l.random = rand.Float64 //nolint:gosec
+	}
+
+	return l
+}
+
+// entry describes the outcome of a single HTTP transaction
+type entry struct {
+	request    *http.Request
+	statusCode int
+	bytes      int64
+	duration   time.Duration
+	err        error
+}
+
+// failed tests if this entry represents a failed transaction
+func (e entry) failed() bool {
+	return e.err != nil || e.statusCode >= 500
+}
+
+// log writes an entry, subject to sampling.
+func (l *logger) log(ctx context.Context, e entry) {
+	level := l.level
+	if e.failed() {
+		level = slog.LevelError
+	} else if l.sampleRate > 0.0 && l.sampleRate < 1.0 && l.random() >= l.sampleRate {
+		return
+	}
+
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := make([]slog.Attr, 0, 9)
+	attrs = append(attrs,
+		slog.String("method", e.request.Method),
+		slog.String("url", l.redactor.url(e.request.URL)),
+	)
+
+	if e.statusCode > 0 {
+		attrs = append(attrs, slog.Int("status", e.statusCode))
+	}
+
+	attrs = append(attrs, slog.Duration("duration", e.duration))
+	if e.bytes >= 0 {
+		attrs = append(attrs, slog.Int64("bytes", e.bytes))
+	}
+
+	if s := retry.GetState(e.request.Context()); s != nil {
+		attrs = append(attrs, slog.Int("attempt", s.Attempt()))
+	}
+
+	if h := l.redactor.header(e.request.Header); !h.Equal(slog.Attr{}) {
+		attrs = append(attrs, h)
+	}
+
+	if e.err != nil {
+		attrs = append(attrs, slog.String("error", e.err.Error()))
+	}
+
+	l.logger.LogAttrs(ctx, level, l.message, attrs...)
+}
+
+// client logs the result of a clientside transaction
+func (l *logger) client(request *http.Request, start time.Time, response *http.Response, err error) {
+	e := entry{
+		request:  request,
+		bytes:    -1,
+		duration: l.now().Sub(start),
+		err:      err,
+	}
+
+	if response != nil {
+		e.statusCode = response.StatusCode
+		e.bytes = response.ContentLength
+	}
+
+	l.log(request.Context(), e)
+}
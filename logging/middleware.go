@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"net/http"
+
+	"github.com/xmidt-org/httpaux"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/observe"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+// NewClient creates client middleware that logs each HTTP transaction.  The number
+// of bytes logged is the response's ContentLength, if known.
+//
+// If the decorated client is nil, http.DefaultClient is used.
+func NewClient(cfg Config) client.Constructor {
+	l := newLogger(cfg)
+	return func(next httpaux.Client) httpaux.Client {
+		if next == nil {
+			next = http.DefaultClient
+		}
+
+		return client.Func(func(request *http.Request) (*http.Response, error) {
+			start := l.now()
+			response, err := next.Do(request)
+			l.client(request, start, response, err)
+			return response, err
+		})
+	}
+}
+
+// NewRoundTripper creates round tripper middleware that logs each HTTP transaction.
+// The number of bytes logged is the response's ContentLength, if known.
+//
+// The decorated round tripper's CloseIdleConnections method is preserved.  If the
+// decorated round tripper is nil, http.DefaultTransport is used.
+func NewRoundTripper(cfg Config) roundtrip.Constructor {
+	l := newLogger(cfg)
+	return func(next http.RoundTripper) http.RoundTripper {
+		if next == nil {
+			next = http.DefaultTransport
+		}
+
+		return roundtrip.PreserveCloseIdler(
+			next,
+			roundtrip.Func(func(request *http.Request) (*http.Response, error) {
+				start := l.now()
+				response, err := next.RoundTrip(request)
+				l.client(request, start, response, err)
+				return response, err
+			}),
+		)
+	}
+}
+
+// NewServer creates serverside middleware that logs each HTTP request.  The number
+// of bytes logged is the number of bytes written to the response body.
+//
+// A request is logged even if the handler panics.  If no status code was written
+// before the panic, the request is logged as a 500, which is what recovery middleware
+// will typically write.  The panic itself is not recovered.
+func NewServer(cfg Config) func(http.Handler) http.Handler {
+	l := newLogger(cfg)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
+			var (
+				start     = l.now()
+				ow        = observe.New(rw)
+				completed bool
+			)
+
+			defer func() {
+				statusCode := observe.FinalStatusCode(ow)
+				if !completed && ow.StatusCode() == 0 {
+					statusCode = http.StatusInternalServerError
+				}
+
+				l.log(request.Context(), entry{
+					request:    request,
+					statusCode: statusCode,
+					bytes:      ow.ContentLength(),
+					duration:   l.now().Sub(start),
+				})
+			}()
+
+			next.ServeHTTP(ow, request)
+			completed = true
+		})
+	}
+}
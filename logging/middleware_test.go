@@ -0,0 +1,344 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/clock"
+	"github.com/xmidt-org/httpaux/httpmock"
+	"github.com/xmidt-org/httpaux/recovery"
+	"github.com/xmidt-org/httpaux/retry"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+
+	output bytes.Buffer
+	clock  *clock.Fake
+}
+
+func (suite *MiddlewareTestSuite) SetupTest() {
+	suite.output.Reset()
+	suite.clock = clock.NewFake(time.Now())
+}
+
+// config produces a Config that writes JSON to this suite's output
+func (suite *MiddlewareTestSuite) config() Config {
+	return Config{
+		Logger: slog.New(slog.NewJSONHandler(&suite.output, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+		})),
+		Clock: suite.clock,
+	}
+}
+
+// entries parses all log entries written so far
+func (suite *MiddlewareTestSuite) entries() (entries []map[string]any) {
+	decoder := json.NewDecoder(&suite.output)
+	for decoder.More() {
+		var e map[string]any
+		suite.Require().NoError(decoder.Decode(&e))
+		entries = append(entries, e)
+	}
+
+	return
+}
+
+// entry parses exactly one log entry
+func (suite *MiddlewareTestSuite) entry() map[string]any {
+	entries := suite.entries()
+	suite.Require().Len(entries, 1)
+	return entries[0]
+}
+
+// advance returns a round tripper that moves the clock forward before returning the given result
+func (suite *MiddlewareTestSuite) advance(d time.Duration, response *http.Response, err error) http.RoundTripper {
+	return roundtrip.Func(func(*http.Request) (*http.Response, error) {
+		suite.clock.Add(d)
+		return response, err
+	})
+}
+
+func (suite *MiddlewareTestSuite) TestNewClient() {
+	var (
+		cfg = suite.config()
+		c   = client.NewChain(NewClient(cfg)).Then(&http.Client{
+			Transport: suite.advance(
+				250*time.Millisecond,
+				&http.Response{StatusCode: http.StatusOK, ContentLength: 123, Body: httpmock.EmptyBody()},
+				nil,
+			),
+		})
+	)
+
+	request, err := http.NewRequest("GET", "http://example.com/test?api_key=secret", nil)
+	suite.Require().NoError(err)
+
+	response, err := c.Do(request)
+	suite.NoError(err)
+	suite.Require().NotNil(response)
+
+	e := suite.entry()
+	suite.Equal("INFO", e["level"])
+	suite.Equal(DefaultMessage, e["msg"])
+	suite.Equal("GET", e["method"])
+	suite.Equal("http://example.com/test?api_key=REDACTED", e["url"])
+	suite.Equal(float64(http.StatusOK), e["status"])
+	suite.Equal(float64(250*time.Millisecond), e["duration"])
+	suite.Equal(float64(123), e["bytes"])
+	suite.NotContains(e, "attempt")
+	suite.NotContains(e, "error")
+}
+
+func (suite *MiddlewareTestSuite) TestNewClientDefault() {
+	suite.NotNil(NewClient(Config{})(nil))
+}
+
+func (suite *MiddlewareTestSuite) TestNewRoundTripper() {
+	var (
+		expectedErr = errors.New("expected")
+		cfg         = suite.config()
+		next        = &httpmock.CloseIdler{
+			RoundTripper: httpmock.NewRoundTripperSuite(suite),
+		}
+	)
+
+	cfg.Message = "outbound"
+	cfg.Headers = []string{"Authorization", "X-Custom"}
+	rt := roundtrip.NewChain(NewRoundTripper(cfg)).Then(next)
+
+	next.OnAny().Return(nil, expectedErr).Once()
+	request, err := http.NewRequest("POST", "http://example.com/test", nil)
+	suite.Require().NoError(err)
+	request.Header.Set("Authorization", "Bearer secret")
+	request.Header.Set("X-Custom", "value")
+
+	response, err := rt.RoundTrip(request)
+	suite.Nil(response)
+	suite.ErrorIs(err, expectedErr)
+
+	e := suite.entry()
+	suite.Equal("ERROR", e["level"])
+	suite.Equal("outbound", e["msg"])
+	suite.Equal("POST", e["method"])
+	suite.Equal("expected", e["error"])
+	suite.NotContains(e, "status")
+	suite.NotContains(e, "bytes")
+	suite.Equal(
+		map[string]any{"Authorization": Redacted, "X-Custom": "value"},
+		e["headers"],
+	)
+
+	next.OnCloseIdleConnections().Once()
+	roundtrip.CloseIdleConnections(rt)
+	next.AssertExpectations()
+}
+
+func (suite *MiddlewareTestSuite) TestNewRoundTripperDefault() {
+	suite.NotNil(NewRoundTripper(Config{})(nil))
+}
+
+func (suite *MiddlewareTestSuite) TestRetryAttempt() {
+	var (
+		attempts = 0
+		c        = client.NewChain(
+			retry.New(
+				retry.Config{
+					Retries: 1,
+					Check: func(r *http.Response, _ error) bool {
+						return r.StatusCode != http.StatusOK
+					},
+					Timer: func(time.Duration) (<-chan time.Time, func() bool) {
+						tc := make(chan time.Time)
+						close(tc)
+						return tc, func() bool { return true }
+					},
+				},
+				nil,
+			).Then,
+			NewClient(suite.config()),
+		).Then(client.Func(func(*http.Request) (*http.Response, error) {
+			attempts++
+			status := http.StatusServiceUnavailable
+			if attempts > 1 {
+				status = http.StatusOK
+			}
+
+			return &http.Response{StatusCode: status, Body: httpmock.EmptyBody()}, nil
+		}))
+	)
+
+	request, err := http.NewRequest("GET", "http://example.com/test", nil)
+	suite.Require().NoError(err)
+
+	response, err := c.Do(request)
+	suite.NoError(err)
+	suite.Require().NotNil(response)
+
+	entries := suite.entries()
+	suite.Require().Len(entries, 2)
+	suite.Equal("ERROR", entries[0]["level"])
+	suite.Equal(float64(0), entries[0]["attempt"])
+	suite.Equal("INFO", entries[1]["level"])
+	suite.Equal(float64(1), entries[1]["attempt"])
+}
+
+func (suite *MiddlewareTestSuite) TestSampling() {
+	var (
+		random = []float64{0.05, 0.5, 0.5}
+		cfg    = suite.config()
+	)
+
+	cfg.Level = slog.LevelDebug
+	cfg.SampleRate = 0.1
+	cfg.Random = func() float64 {
+		r := random[0]
+		random = random[1:]
+		return r
+	}
+
+	c := client.NewChain(NewClient(cfg)).Then(client.Func(func(r *http.Request) (*http.Response, error) {
+		status := http.StatusOK
+		if r.URL.Path == "/fail" {
+			status = http.StatusInternalServerError
+		}
+
+		return &http.Response{StatusCode: status, Body: httpmock.EmptyBody()}, nil
+	}))
+
+	for _, path := range []string{"/sampled", "/skipped", "/fail"} {
+		request, err := http.NewRequest("GET", "http://example.com"+path, nil)
+		suite.Require().NoError(err)
+		_, err = c.Do(request)
+		suite.NoError(err)
+	}
+
+	entries := suite.entries()
+	suite.Require().Len(entries, 2)
+	suite.Equal("DEBUG", entries[0]["level"])
+	suite.Equal("http://example.com/sampled", entries[0]["url"])
+
+	// failures are always logged, without consuming a random number
+	suite.Equal("ERROR", entries[1]["level"])
+	suite.Equal("http://example.com/fail", entries[1]["url"])
+	suite.Len(random, 1)
+}
+
+func (suite *MiddlewareTestSuite) TestDisabledLevel() {
+	cfg := suite.config()
+	cfg.Level = slog.LevelDebug - 1
+	c := client.NewChain(NewClient(cfg)).Then(client.Func(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: httpmock.EmptyBody()}, nil
+	}))
+
+	request, err := http.NewRequest("GET", "http://example.com/test", nil)
+	suite.Require().NoError(err)
+	_, err = c.Do(request)
+	suite.NoError(err)
+	suite.Zero(suite.output.Len())
+}
+
+func (suite *MiddlewareTestSuite) TestNewServer() {
+	suite.Run("Written", func() {
+		suite.output.Reset()
+		h := NewServer(suite.config())(
+			http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				suite.clock.Add(time.Second)
+				rw.WriteHeader(http.StatusAccepted)
+				rw.Write([]byte("hello"))
+			}),
+		)
+
+		response := httptest.NewRecorder()
+		h.ServeHTTP(response, httptest.NewRequest("PUT", "/test?password=secret", nil))
+		suite.Equal(http.StatusAccepted, response.Code)
+
+		e := suite.entry()
+		suite.Equal("INFO", e["level"])
+		suite.Equal("PUT", e["method"])
+		suite.Equal("/test?password=REDACTED", e["url"])
+		suite.Equal(float64(http.StatusAccepted), e["status"])
+		suite.Equal(float64(5), e["bytes"])
+		suite.Equal(float64(time.Second), e["duration"])
+	})
+
+	suite.Run("NothingWritten", func() {
+		suite.output.Reset()
+		h := NewServer(suite.config())(
+			http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+		)
+
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+
+		e := suite.entry()
+		suite.Equal(float64(http.StatusOK), e["status"])
+		suite.Equal(float64(0), e["bytes"])
+	})
+
+	suite.Run("ServerError", func() {
+		suite.output.Reset()
+		h := NewServer(suite.config())(
+			http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+			}),
+		)
+
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+		suite.Equal("ERROR", suite.entry()["level"])
+	})
+
+	suite.Run("Panic", func() {
+		suite.output.Reset()
+		h := NewServer(suite.config())(
+			http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+				suite.clock.Add(time.Second)
+				panic("expected")
+			}),
+		)
+
+		suite.PanicsWithValue("expected", func() {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/test", nil))
+		})
+
+		e := suite.entry()
+		suite.Equal("ERROR", e["level"])
+		suite.Equal("POST", e["method"])
+		suite.Equal(float64(http.StatusInternalServerError), e["status"])
+		suite.Equal(float64(time.Second), e["duration"])
+	})
+
+	suite.Run("PanicAfterWrite", func() {
+		suite.output.Reset()
+		h := recovery.Middleware()(
+			NewServer(suite.config())(
+				http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+					rw.WriteHeader(http.StatusAccepted)
+					rw.Write([]byte("partial"))
+					panic("expected")
+				}),
+			),
+		)
+
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/test", nil))
+
+		e := suite.entry()
+		suite.Equal(float64(http.StatusAccepted), e["status"])
+		suite.Equal(float64(7), e["bytes"])
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// redactor removes sensitive information before it is logged
+type redactor struct {
+	// headers are the canonicalized headers to log
+	headers []string
+
+	// redactHeaders is the set of canonicalized headers to redact
+	redactHeaders map[string]bool
+
+	// redactQuery is the set of lowercased query parameters to redact
+	redactQuery map[string]bool
+}
+
+func newRedactor(cfg Config) (r redactor) {
+	r.redactHeaders = make(map[string]bool)
+	for _, h := range append(append([]string(nil), defaultRedactedHeaders...), cfg.RedactHeaders...) {
+		r.redactHeaders[http.CanonicalHeaderKey(h)] = true
+	}
+
+	r.redactQuery = make(map[string]bool)
+	for _, q := range append(append([]string(nil), defaultRedactedQuery...), cfg.RedactQuery...) {
+		r.redactQuery[strings.ToLower(q)] = true
+	}
+
+	for _, h := range cfg.Headers {
+		r.headers = append(r.headers, http.CanonicalHeaderKey(h))
+	}
+
+	return
+}
+
+// url produces the loggable form of a URL
+func (r redactor) url(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	redacted := *u
+	if _, hasPassword := u.User.Password(); hasPassword {
+		redacted.User = url.UserPassword(u.User.Username(), Redacted)
+	}
+
+	if len(u.RawQuery) > 0 {
+		values, err := url.ParseQuery(u.RawQuery)
+		if err != nil {
+			// don't risk logging something that couldn't be parsed
+			redacted.RawQuery = Redacted
+		} else if r.redactValues(values) {
+			redacted.RawQuery = values.Encode()
+		}
+	}
+
+	return redacted.String()
+}
+
+// redactValues replaces sensitive query values, returning true if anything was replaced.
+func (r redactor) redactValues(values url.Values) (changed bool) {
+	for name, v := range values {
+		if r.redactQuery[strings.ToLower(name)] {
+			for i := range v {
+				v[i] = Redacted
+			}
+
+			changed = true
+		}
+	}
+
+	return
+}
+
+// header produces a log attribute for the configured headers, or an empty attribute
+// if there are no headers to log.
+func (r redactor) header(h http.Header) slog.Attr {
+	var attrs []any
+	for _, name := range r.headers {
+		values := h.Values(name)
+		switch {
+		case len(values) == 0:
+			continue
+
+		case r.redactHeaders[name]:
+			attrs = append(attrs, slog.String(name, Redacted))
+
+		default:
+			attrs = append(attrs, slog.String(name, strings.Join(values, ",")))
+		}
+	}
+
+	if len(attrs) == 0 {
+		return slog.Attr{}
+	}
+
+	return slog.Group("headers", attrs...)
+}
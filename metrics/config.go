@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"github.com/xmidt-org/httpaux/clock"
+)
+
+const (
+	// DefaultClientPrefix is the metric name prefix used by NewClient and NewRoundTripper
+	// when Config.Prefix is unset.
+	DefaultClientPrefix = "http_client"
+
+	// DefaultServerPrefix is the metric name prefix used by NewServer when Config.Prefix is unset.
+	DefaultServerPrefix = "http_server"
+
+	// MethodLabel is the label for the HTTP method.
+	MethodLabel = "method"
+
+	// CodeLabel is the label for the HTTP status code.
+	CodeLabel = "code"
+
+	// ErrorCode is the value of CodeLabel when a client transaction fails with an error.
+	ErrorCode = "error"
+)
+
+// DefaultDurationBuckets returns the histogram buckets, in seconds, used for request
+// durations when Config.DurationBuckets is unset.
+func DefaultDurationBuckets() []float64 {
+	return []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+}
+
+// DefaultSizeBuckets returns the histogram buckets, in bytes, used for response sizes
+// when Config.SizeBuckets is unset.
+func DefaultSizeBuckets() []float64 {
+	return []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+}
+
+// Config is the set of configuration options for metrics middleware.
+type Config struct {
+	// Provider creates the metrics.  If unset, no metrics are recorded.
+	Provider Provider `json:"-" yaml:"-"`
+
+	// Prefix is the prefix for all metric names.  If unset, DefaultClientPrefix or
+	// DefaultServerPrefix is used.
+	Prefix string `json:"prefix" yaml:"prefix"`
+
+	// DurationBuckets are the histogram buckets for request durations, in seconds.
+	// If unset, DefaultDurationBuckets is used.
+	DurationBuckets []float64 `json:"durationBuckets" yaml:"durationBuckets"`
+
+	// SizeBuckets are the histogram buckets for response sizes, in bytes.
+	// If unset, DefaultSizeBuckets is used.
+	SizeBuckets []float64 `json:"sizeBuckets" yaml:"sizeBuckets"`
+
+	// Clock is the optional source of time used to measure durations.  If unset,
+	// clock.System is used.
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
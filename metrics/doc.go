@@ -0,0 +1,29 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package metrics provides a small, pluggable metrics abstraction along with instrumentation
+middleware for HTTP clients, round trippers, and servers.
+
+A Provider creates Counters, Gauges, and Histograms.  This package does not depend on any
+particular metrics library.  Applications supply an adapter for their library of choice, while
+tests can use the in-memory implementation returned by NewMemory:
+
+	m := metrics.NewMemory()
+	cfg := metrics.Config{Provider: m}
+
+	c := client.NewChain(metrics.NewClient(cfg)).Then(new(http.Client))
+	h := metrics.NewServer(cfg)(myHandler)
+
+The middleware records the following metrics, where the prefix defaults to http_client
+or http_server:
+
+  - <prefix>_requests_total, a counter labeled by method and code
+  - <prefix>_in_flight_requests, a gauge labeled by method
+  - <prefix>_request_duration_seconds, a histogram labeled by method and code
+  - <prefix>_response_size_bytes, a histogram labeled by method and code
+
+For clients, a transaction that fails with an error has a code of "error".  A transaction
+with a response body is recorded when that body is closed.
+*/
+package metrics
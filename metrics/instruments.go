@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/xmidt-org/httpaux/clock"
+)
+
+// instruments is the set of metrics recorded by all middleware in this package
+type instruments struct {
+	requests  Counter
+	inFlight  Gauge
+	durations Histogram
+	sizes     Histogram
+	now       func() time.Time
+}
+
+func newInstruments(cfg Config, defaultPrefix string) *instruments {
+	provider := cfg.Provider
+	if provider == nil {
+		provider = nopProvider{}
+	}
+
+	prefix := cfg.Prefix
+	if len(prefix) == 0 {
+		prefix = defaultPrefix
+	}
+
+	durationBuckets := cfg.DurationBuckets
+	if len(durationBuckets) == 0 {
+		durationBuckets = DefaultDurationBuckets()
+	}
+
+	sizeBuckets := cfg.SizeBuckets
+	if len(sizeBuckets) == 0 {
+		sizeBuckets = DefaultSizeBuckets()
+	}
+
+	return &instruments{
+		requests: provider.NewCounter(Options{
+			Name:       prefix + "_requests_total",
+			Help:       "The total number of HTTP requests",
+			LabelNames: []string{MethodLabel, CodeLabel},
+		}),
+		inFlight: provider.NewGauge(Options{
+			Name:       prefix + "_in_flight_requests",
+			Help:       "The number of HTTP requests currently being processed",
+			LabelNames: []string{MethodLabel},
+		}),
+		durations: provider.NewHistogram(Options{
+			Name:       prefix + "_request_duration_seconds",
+			Help:       "The duration of HTTP requests, in seconds",
+			LabelNames: []string{MethodLabel, CodeLabel},
+			Buckets:    durationBuckets,
+		}),
+		sizes: provider.NewHistogram(Options{
+			Name:       prefix + "_response_size_bytes",
+			Help:       "The size of HTTP response bodies, in bytes",
+			LabelNames: []string{MethodLabel, CodeLabel},
+			Buckets:    sizeBuckets,
+		}),
+		now: clock.OrSystem(cfg.Clock).Now,
+	}
+}
+
+// start records the beginning of a request, returning the function that records its end.
+// The returned function takes the status code, or ErrorCode, and the response size.  A
+// negative size means the size is unknown, and no size is recorded.
+func (i *instruments) start(method string) func(code string, size int64) {
+	var (
+		begin        = i.now()
+		methodLabels = Labels{MethodLabel: method}
+	)
+
+	i.inFlight.Add(methodLabels, 1.0)
+	return func(code string, size int64) {
+		i.inFlight.Add(methodLabels, -1.0)
+
+		labels := Labels{MethodLabel: method, CodeLabel: code}
+		i.requests.Add(labels, 1.0)
+		i.durations.Observe(labels, i.now().Sub(begin).Seconds())
+		if size >= 0 {
+			i.sizes.Observe(labels, float64(size))
+		}
+	}
+}
+
+// endBody records the end of a clientside transaction when the response body is closed
+type endBody struct {
+	io.ReadCloser
+	once sync.Once
+	end  func()
+}
+
+func (eb *endBody) Close() error {
+	defer eb.once.Do(eb.end)
+	return eb.ReadCloser.Close()
+}
+
+// clientResult records the end of a clientside transaction.  A transaction is not over
+// until its response body is closed, so when there is a body the end is recorded at that time.
+// This keeps the in-flight gauge and durations accurate for callers that stream responses.
+//
+// The body of a 101 Switching Protocols response is never wrapped, since it is an
+// io.ReadWriteCloser that callers need to see.
+func clientResult(end func(string, int64), response *http.Response, err error) {
+	switch {
+	case err != nil || response == nil:
+		end(ErrorCode, -1)
+
+	case response.Body == nil || response.Body == http.NoBody || response.StatusCode == http.StatusSwitchingProtocols:
+		end(strconv.Itoa(response.StatusCode), response.ContentLength)
+
+	default:
+		var (
+			code = strconv.Itoa(response.StatusCode)
+			size = response.ContentLength
+		)
+
+		response.Body = &endBody{
+			ReadCloser: response.Body,
+			end: func() {
+				end(code, size)
+			},
+		}
+	}
+}
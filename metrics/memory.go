@@ -0,0 +1,166 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// labelKey produces a canonical string for a set of labels
+func labelKey(labels Labels) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var o strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			o.WriteRune(',')
+		}
+
+		o.WriteString(name)
+		o.WriteRune('=')
+		o.WriteString(labels[name])
+	}
+
+	return o.String()
+}
+
+// Memory is an in-memory Provider, primarily intended for tests.  Metrics created by
+// a Memory can be examined with its Counter, Gauge, and Histogram methods.
+//
+// A Memory is safe for concurrent use.
+type Memory struct {
+	lock sync.Mutex
+
+	// values holds counters and gauges, keyed by metric name then by label key
+	values map[string]map[string]float64
+
+	// observations holds histogram values, keyed by metric name then by label key
+	observations map[string]map[string][]float64
+
+	// options holds the options each metric was created with
+	options map[string]Options
+}
+
+var _ Provider = (*Memory)(nil)
+
+// NewMemory creates an empty, in-memory Provider.
+func NewMemory() *Memory {
+	return &Memory{
+		values:       make(map[string]map[string]float64),
+		observations: make(map[string]map[string][]float64),
+		options:      make(map[string]Options),
+	}
+}
+
+// register records the options for a metric.  the lock must be held.
+func (m *Memory) register(o Options) {
+	if _, exists := m.options[o.Name]; !exists {
+		m.options[o.Name] = o
+	}
+}
+
+// NewCounter returns a Counter stored in this Memory.
+func (m *Memory) NewCounter(o Options) Counter {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.register(o)
+	return memoryValue{memory: m, name: o.Name}
+}
+
+// NewGauge returns a Gauge stored in this Memory.
+func (m *Memory) NewGauge(o Options) Gauge {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.register(o)
+	return memoryValue{memory: m, name: o.Name}
+}
+
+// NewHistogram returns a Histogram stored in this Memory.
+func (m *Memory) NewHistogram(o Options) Histogram {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.register(o)
+	return memoryHistogram{memory: m, name: o.Name}
+}
+
+// Options returns the options the named metric was created with, along with
+// whether that metric has been created.
+func (m *Memory) Options(name string) (Options, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	o, ok := m.options[name]
+	return o, ok
+}
+
+// Value returns the current value of the named counter or gauge with the given labels.
+// If nothing has been recorded, this method returns zero.
+func (m *Memory) Value(name string, labels Labels) float64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.values[name][labelKey(labels)]
+}
+
+// Observations returns a copy of the values observed by the named histogram with the
+// given labels, in the order they were observed.
+func (m *Memory) Observations(name string, labels Labels) []float64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]float64(nil), m.observations[name][labelKey(labels)]...)
+}
+
+// update applies a function to the value of a counter or gauge.
+func (m *Memory) update(name string, labels Labels, f func(float64) float64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	series := m.values[name]
+	if series == nil {
+		series = make(map[string]float64)
+		m.values[name] = series
+	}
+
+	key := labelKey(labels)
+	series[key] = f(series[key])
+}
+
+// memoryValue is both a Counter and a Gauge backed by a Memory
+type memoryValue struct {
+	memory *Memory
+	name   string
+}
+
+func (mv memoryValue) Add(labels Labels, delta float64) {
+	mv.memory.update(mv.name, labels, func(v float64) float64 { return v + delta })
+}
+
+func (mv memoryValue) Set(labels Labels, value float64) {
+	mv.memory.update(mv.name, labels, func(float64) float64 { return value })
+}
+
+// memoryHistogram is a Histogram backed by a Memory
+type memoryHistogram struct {
+	memory *Memory
+	name   string
+}
+
+func (mh memoryHistogram) Observe(labels Labels, value float64) {
+	mh.memory.lock.Lock()
+	defer mh.memory.lock.Unlock()
+
+	series := mh.memory.observations[mh.name]
+	if series == nil {
+		series = make(map[string][]float64)
+		mh.memory.observations[mh.name] = series
+	}
+
+	key := labelKey(labels)
+	series[key] = append(series[key], value)
+}
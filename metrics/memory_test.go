@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MemoryTestSuite struct {
+	suite.Suite
+}
+
+func (suite *MemoryTestSuite) TestLabelKey() {
+	suite.Empty(labelKey(nil))
+	suite.Equal(
+		labelKey(Labels{"b": "2", "a": "1"}),
+		labelKey(Labels{"a": "1", "b": "2"}),
+	)
+
+	suite.NotEqual(
+		labelKey(Labels{"a": "1"}),
+		labelKey(Labels{"a": "2"}),
+	)
+}
+
+func (suite *MemoryTestSuite) TestCounter() {
+	var (
+		m = NewMemory()
+		o = Options{Name: "test_total", Help: "test", LabelNames: []string{"a"}}
+		c = m.NewCounter(o)
+	)
+
+	actual, ok := m.Options("test_total")
+	suite.True(ok)
+	suite.Equal(o, actual)
+
+	_, ok = m.Options("missing")
+	suite.False(ok)
+
+	c.Add(Labels{"a": "1"}, 1.0)
+	c.Add(Labels{"a": "1"}, 2.0)
+	c.Add(Labels{"a": "2"}, 5.0)
+
+	// the same metric may be requested again
+	m.NewCounter(o).Add(Labels{"a": "1"}, 1.0)
+
+	suite.Equal(4.0, m.Value("test_total", Labels{"a": "1"}))
+	suite.Equal(5.0, m.Value("test_total", Labels{"a": "2"}))
+	suite.Zero(m.Value("test_total", Labels{"a": "3"}))
+	suite.Zero(m.Value("missing", nil))
+}
+
+func (suite *MemoryTestSuite) TestGauge() {
+	var (
+		m = NewMemory()
+		g = m.NewGauge(Options{Name: "test"})
+	)
+
+	g.Add(nil, 3.0)
+	g.Add(nil, -1.0)
+	suite.Equal(2.0, m.Value("test", nil))
+
+	g.Set(nil, 10.0)
+	suite.Equal(10.0, m.Value("test", nil))
+}
+
+func (suite *MemoryTestSuite) TestHistogram() {
+	var (
+		m = NewMemory()
+		h = m.NewHistogram(Options{Name: "test", Buckets: []float64{1, 2}})
+	)
+
+	h.Observe(Labels{"a": "1"}, 0.5)
+	h.Observe(Labels{"a": "1"}, 1.5)
+	h.Observe(Labels{"a": "2"}, 3.0)
+
+	observations := m.Observations("test", Labels{"a": "1"})
+	suite.Equal([]float64{0.5, 1.5}, observations)
+
+	// a copy is returned
+	observations[0] = 100.0
+	suite.Equal([]float64{0.5, 1.5}, m.Observations("test", Labels{"a": "1"}))
+	suite.Equal([]float64{3.0}, m.Observations("test", Labels{"a": "2"}))
+	suite.Empty(m.Observations("missing", nil))
+}
+
+func (suite *MemoryTestSuite) TestConcurrency() {
+	var (
+		m  = NewMemory()
+		c  = m.NewCounter(Options{Name: "test"})
+		wg sync.WaitGroup
+	)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Add(nil, 1.0)
+			}
+		}()
+	}
+
+	wg.Wait()
+	suite.Equal(1000.0, m.Value("test", nil))
+}
+
+func TestMemory(t *testing.T) {
+	suite.Run(t, new(MemoryTestSuite))
+}
@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+// Labels is a set of label names and values that identify a single time series
+// within a metric.
+type Labels map[string]string
+
+// Options describes a metric to be created by a Provider.
+type Options struct {
+	// Name is the fully qualified name of the metric.
+	Name string
+
+	// Help is the human-readable description of the metric.
+	Help string
+
+	// LabelNames are the names of the labels used with this metric.  Every
+	// Labels passed to the metric will have exactly these names.
+	LabelNames []string
+
+	// Buckets are the upper bounds of histogram buckets.  This field is only
+	// used for histograms.
+	Buckets []float64
+}
+
+// Counter is a metric whose value only increases.
+type Counter interface {
+	// Add increases the counter identified by labels by delta, which must not be negative.
+	Add(labels Labels, delta float64)
+}
+
+// Gauge is a metric whose value can go up and down.
+type Gauge interface {
+	// Add changes the gauge identified by labels by delta, which may be negative.
+	Add(labels Labels, delta float64)
+
+	// Set replaces the value of the gauge identified by labels.
+	Set(labels Labels, value float64)
+}
+
+// Histogram is a metric that records the distribution of observed values.
+type Histogram interface {
+	// Observe records a value in the histogram identified by labels.
+	Observe(labels Labels, value float64)
+}
+
+// Provider is a factory for metrics.  Adapters for particular metrics libraries
+// implement this interface.
+//
+// Implementations must tolerate being asked to create the same metric more than once,
+// e.g. by returning the previously created metric.  This happens whenever middleware
+// in this package is constructed more than once with the same Config.
+type Provider interface {
+	// NewCounter returns the counter described by the given options.
+	NewCounter(Options) Counter
+
+	// NewGauge returns the gauge described by the given options.
+	NewGauge(Options) Gauge
+
+	// NewHistogram returns the histogram described by the given options.
+	NewHistogram(Options) Histogram
+}
+
+// nopProvider is the Provider used when none is configured.  Its metrics discard everything.
+type nopProvider struct{}
+
+func (nopProvider) NewCounter(Options) Counter     { return nopMetric{} }
+func (nopProvider) NewGauge(Options) Gauge         { return nopMetric{} }
+func (nopProvider) NewHistogram(Options) Histogram { return nopMetric{} }
+
+// nopMetric is a Counter, Gauge, and Histogram that does nothing
+type nopMetric struct{}
+
+func (nopMetric) Add(Labels, float64)     {}
+func (nopMetric) Set(Labels, float64)     {}
+func (nopMetric) Observe(Labels, float64) {}
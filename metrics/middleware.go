@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/xmidt-org/httpaux"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/observe"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+// NewClient creates client middleware that instruments each HTTP transaction.  The
+// response size is the response's ContentLength, and is only recorded if known.
+//
+// A transaction with a response body remains in flight until that body is closed, and its
+// duration includes the time spent reading the body.  Callers must close response bodies,
+// as net/http already requires.
+//
+// If the decorated client is nil, http.DefaultClient is used.
+func NewClient(cfg Config) client.Constructor {
+	i := newInstruments(cfg, DefaultClientPrefix)
+	return func(next httpaux.Client) httpaux.Client {
+		if next == nil {
+			next = http.DefaultClient
+		}
+
+		return client.Func(func(request *http.Request) (*http.Response, error) {
+			end := i.start(request.Method)
+			response, err := next.Do(request)
+			clientResult(end, response, err)
+			return response, err
+		})
+	}
+}
+
+// NewRoundTripper creates round tripper middleware that instruments each HTTP transaction
+// just as NewClient does.
+//
+// If the decorated round tripper is nil, http.DefaultTransport is used.
+func NewRoundTripper(cfg Config) roundtrip.Constructor {
+	i := newInstruments(cfg, DefaultClientPrefix)
+	return func(next http.RoundTripper) http.RoundTripper {
+		if next == nil {
+			next = http.DefaultTransport
+		}
+
+		return roundtrip.PreserveCloseIdler(
+			next,
+			roundtrip.Func(func(request *http.Request) (*http.Response, error) {
+				end := i.start(request.Method)
+				response, err := next.RoundTrip(request)
+				clientResult(end, response, err)
+				return response, err
+			}),
+		)
+	}
+}
+
+// serverResult records the end of a serverside request.  If the handler panicked
+// before writing a status code, the request is recorded as a 500, which is what
+// recovery middleware will typically write.
+func serverResult(end func(string, int64), ow observe.Writer, completed bool) {
+	statusCode := observe.FinalStatusCode(ow)
+	if !completed && ow.StatusCode() == 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	end(strconv.Itoa(statusCode), ow.ContentLength())
+}
+
+// NewServer creates serverside middleware that instruments each HTTP request.  The
+// status code and response size come from an observe.Writer.
+//
+// A request is recorded even if the handler panics, so that the in-flight gauge
+// remains accurate when the panic is recovered further out.
+func NewServer(cfg Config) func(http.Handler) http.Handler {
+	i := newInstruments(cfg, DefaultServerPrefix)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
+			var (
+				end       = i.start(request.Method)
+				ow        = observe.New(rw)
+				completed bool
+			)
+
+			defer func() {
+				serverResult(end, ow, completed)
+			}()
+
+			next.ServeHTTP(ow, request)
+			completed = true
+		})
+	}
+}
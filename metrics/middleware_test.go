@@ -0,0 +1,276 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/clock"
+	"github.com/xmidt-org/httpaux/httpmock"
+	"github.com/xmidt-org/httpaux/recovery"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+
+	memory *Memory
+	clock  *clock.Fake
+}
+
+func (suite *MiddlewareTestSuite) SetupTest() {
+	suite.memory = NewMemory()
+	suite.clock = clock.NewFake(time.Now())
+}
+
+func (suite *MiddlewareTestSuite) config() Config {
+	return Config{
+		Provider: suite.memory,
+		Clock:    suite.clock,
+	}
+}
+
+func (suite *MiddlewareTestSuite) newRequest(method string) *http.Request {
+	request, err := http.NewRequest(method, "/test", nil)
+	suite.Require().NoError(err)
+	return request
+}
+
+// assertInFlight returns a mock Run function that verifies the in-flight gauge and
+// then advances the clock
+func (suite *MiddlewareTestSuite) assertInFlight(prefix, method string, d time.Duration) func(mock.Arguments) {
+	return func(mock.Arguments) {
+		suite.Equal(1.0, suite.memory.Value(prefix+"_in_flight_requests", Labels{MethodLabel: method}))
+		suite.clock.Add(d)
+	}
+}
+
+// assertRecorded verifies the metrics for a single, completed transaction
+func (suite *MiddlewareTestSuite) assertRecorded(prefix, method, code string, duration time.Duration, sizes ...float64) {
+	labels := Labels{MethodLabel: method, CodeLabel: code}
+	suite.Zero(suite.memory.Value(prefix+"_in_flight_requests", Labels{MethodLabel: method}))
+	suite.Equal(1.0, suite.memory.Value(prefix+"_requests_total", labels))
+	suite.Equal([]float64{duration.Seconds()}, suite.memory.Observations(prefix+"_request_duration_seconds", labels))
+	suite.Equal(sizes, suite.memory.Observations(prefix+"_response_size_bytes", labels))
+}
+
+func (suite *MiddlewareTestSuite) TestDefaults() {
+	NewClient(suite.config())
+	NewServer(Config{Provider: suite.memory, Prefix: "custom"})
+
+	o, ok := suite.memory.Options("http_client_request_duration_seconds")
+	suite.True(ok)
+	suite.Equal(DefaultDurationBuckets(), o.Buckets)
+	suite.Equal([]string{MethodLabel, CodeLabel}, o.LabelNames)
+
+	o, ok = suite.memory.Options("custom_response_size_bytes")
+	suite.True(ok)
+	suite.Equal(DefaultSizeBuckets(), o.Buckets)
+
+	_, ok = suite.memory.Options("http_client_in_flight_requests")
+	suite.True(ok)
+	_, ok = suite.memory.Options("custom_requests_total")
+	suite.True(ok)
+}
+
+func (suite *MiddlewareTestSuite) TestNoProvider() {
+	c := NewClient(Config{})(client.Func(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: httpmock.EmptyBody()}, nil
+	}))
+
+	response, err := c.Do(suite.newRequest("GET"))
+	suite.NoError(err)
+	suite.Require().NotNil(response)
+	response.Body.Close()
+
+	h := NewServer(Config{})(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+}
+
+func (suite *MiddlewareTestSuite) TestBuckets() {
+	cfg := suite.config()
+	cfg.DurationBuckets = []float64{1, 2}
+	cfg.SizeBuckets = []float64{3, 4}
+	NewRoundTripper(cfg)
+
+	o, _ := suite.memory.Options("http_client_request_duration_seconds")
+	suite.Equal([]float64{1, 2}, o.Buckets)
+	o, _ = suite.memory.Options("http_client_response_size_bytes")
+	suite.Equal([]float64{3, 4}, o.Buckets)
+}
+
+func (suite *MiddlewareTestSuite) TestNewClient() {
+	var (
+		rt = httpmock.NewRoundTripperSuite(suite)
+		c  = client.NewChain(NewClient(suite.config())).Then(&http.Client{Transport: rt})
+	)
+
+	rt.OnAny().
+		Run(suite.assertInFlight(DefaultClientPrefix, "GET", 2*time.Second)).
+		Return(&http.Response{StatusCode: http.StatusOK, ContentLength: 123, Body: httpmock.EmptyBody()}, nil).
+		Once()
+
+	response, err := c.Do(suite.newRequest("GET"))
+	suite.NoError(err)
+	suite.Require().NotNil(response)
+
+	// the transaction is in flight until the body is closed
+	suite.Equal(1.0, suite.memory.Value("http_client_in_flight_requests", Labels{MethodLabel: "GET"}))
+	suite.clock.Add(time.Second)
+	response.Body.Close()
+	response.Body.Close() // idempotent
+	suite.assertRecorded(DefaultClientPrefix, "GET", "200", 3*time.Second, 123)
+	rt.AssertExpectations()
+}
+
+func (suite *MiddlewareTestSuite) TestNewClientUnknownSize() {
+	c := client.NewChain(NewClient(suite.config())).Then(client.Func(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusNotFound, ContentLength: -1, Body: httpmock.EmptyBody()}, nil
+	}))
+
+	response, err := c.Do(suite.newRequest("GET"))
+	suite.NoError(err)
+	suite.Require().NotNil(response)
+	response.Body.Close()
+	suite.assertRecorded(DefaultClientPrefix, "GET", "404", 0)
+}
+
+func (suite *MiddlewareTestSuite) TestNewClientUnwrapped() {
+	testCases := []struct {
+		name     string
+		response *http.Response
+	}{
+		{
+			name:     "NilBody",
+			response: &http.Response{StatusCode: http.StatusNoContent},
+		},
+		{
+			name:     "NoBody",
+			response: &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody},
+		},
+		{
+			name:     "SwitchingProtocols",
+			response: &http.Response{StatusCode: http.StatusSwitchingProtocols, Body: httpmock.EmptyBody()},
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			suite.SetupTest()
+			body := testCase.response.Body
+			c := NewClient(suite.config())(client.Func(func(*http.Request) (*http.Response, error) {
+				return testCase.response, nil
+			}))
+
+			response, err := c.Do(suite.newRequest("GET"))
+			suite.NoError(err)
+			suite.Require().NotNil(response)
+			suite.True(body == response.Body, "the response body should not be wrapped")
+			suite.assertRecorded(DefaultClientPrefix, "GET", strconv.Itoa(testCase.response.StatusCode), 0, 0)
+		})
+	}
+}
+
+func (suite *MiddlewareTestSuite) TestNewClientDefault() {
+	suite.NotNil(NewClient(suite.config())(nil))
+}
+
+func (suite *MiddlewareTestSuite) TestNewRoundTripper() {
+	var (
+		expectedErr = errors.New("expected")
+		next        = &httpmock.CloseIdler{
+			RoundTripper: httpmock.NewRoundTripperSuite(suite),
+		}
+
+		rt = roundtrip.NewChain(NewRoundTripper(suite.config())).Then(next)
+	)
+
+	next.OnAny().
+		Run(suite.assertInFlight(DefaultClientPrefix, "POST", time.Second)).
+		Return(nil, expectedErr).
+		Once()
+
+	response, err := rt.RoundTrip(suite.newRequest("POST"))
+	suite.Nil(response)
+	suite.ErrorIs(err, expectedErr)
+	suite.assertRecorded(DefaultClientPrefix, "POST", ErrorCode, time.Second)
+
+	next.OnCloseIdleConnections().Once()
+	roundtrip.CloseIdleConnections(rt)
+	next.AssertExpectations()
+}
+
+func (suite *MiddlewareTestSuite) TestNewRoundTripperDefault() {
+	suite.NotNil(NewRoundTripper(suite.config())(nil))
+}
+
+func (suite *MiddlewareTestSuite) TestNewServer() {
+	suite.Run("Written", func() {
+		suite.SetupTest()
+		h := NewServer(suite.config())(
+			http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				suite.Equal(1.0, suite.memory.Value("http_server_in_flight_requests", Labels{MethodLabel: "PUT"}))
+				suite.clock.Add(500 * time.Millisecond)
+				rw.WriteHeader(http.StatusCreated)
+				rw.Write([]byte("hello"))
+			}),
+		)
+
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PUT", "/test", nil))
+		suite.assertRecorded(DefaultServerPrefix, "PUT", "201", 500*time.Millisecond, 5)
+	})
+
+	suite.Run("NothingWritten", func() {
+		suite.SetupTest()
+		h := NewServer(suite.config())(
+			http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+		)
+
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+		suite.assertRecorded(DefaultServerPrefix, "GET", "200", 0, 0)
+	})
+
+	suite.Run("Panic", func() {
+		suite.SetupTest()
+		h := recovery.Middleware()(
+			NewServer(suite.config())(
+				http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+					suite.clock.Add(100 * time.Millisecond)
+					panic("expected")
+				}),
+			),
+		)
+
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/test", nil))
+		suite.assertRecorded(DefaultServerPrefix, "POST", "500", 100*time.Millisecond, 0)
+	})
+
+	suite.Run("PanicAfterWrite", func() {
+		suite.SetupTest()
+		h := recovery.Middleware()(
+			NewServer(suite.config())(
+				http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+					rw.WriteHeader(http.StatusAccepted)
+					rw.Write([]byte("partial"))
+					panic("expected")
+				}),
+			),
+		)
+
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/test", nil))
+		suite.assertRecorded(DefaultServerPrefix, "POST", "202", 0, 7)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
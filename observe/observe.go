@@ -29,6 +29,17 @@ type StatusCoder interface {
 	StatusCode() int
 }
 
+// FinalStatusCode returns the status code sent for a response once its handler has
+// returned.  If the handler never wrote a status code, net/http writes http.StatusOK
+// on its behalf, so that is what this function returns.
+func FinalStatusCode(sc StatusCoder) int {
+	if statusCode := sc.StatusCode(); statusCode != 0 {
+		return statusCode
+	}
+
+	return http.StatusOK
+}
+
 // ResponseBody is the interface implemented by an observable http.ResponseWriter
 type ResponseBody interface {
 	// ContentLength returns the count of bytes actually written with Write.  It does
@@ -416,6 +416,14 @@ func (suite *NewTestSuite) TestNoDecoration() {
 	suite.True(decorated == again)
 }
 
+func (suite *NewTestSuite) TestFinalStatusCode() {
+	decorated := New(httptest.NewRecorder())
+	suite.Equal(http.StatusOK, FinalStatusCode(decorated))
+
+	decorated.WriteHeader(http.StatusNotFound)
+	suite.Equal(http.StatusNotFound, FinalStatusCode(decorated))
+}
+
 func (suite *NewTestSuite) TestWriteWithoutWriteHeader() {
 	var (
 		response = httptest.NewRecorder()
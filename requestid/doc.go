@@ -0,0 +1,21 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package requestid provides middleware that attaches a single identifier to a request as it
+passes through servers, clients, and error responses.
+
+Server middleware reads the identifier from a request header, generating a new one if the
+header is missing or invalid.  The identifier is stored in the request context and echoed on
+the response.  Client middleware copies the identifier from the context onto outgoing requests:
+
+	cfg := requestid.Config{} // X-Request-ID by default
+
+	h := requestid.NewServer(cfg)(myHandler)
+	c := client.NewChain(requestid.NewClient(cfg)).Then(new(http.Client))
+
+Error bodies rendered by an erraux.Encoder can include the identifier as well:
+
+	e := erraux.Encoder{}.ContextFields(requestid.ErrorFields)
+*/
+package requestid
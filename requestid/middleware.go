@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package requestid
+
+import (
+	"net/http"
+
+	"github.com/xmidt-org/httpaux"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+// Config is the set of configuration options for request identifier middleware.
+type Config struct {
+	// Header is the HTTP header that carries the request identifier.  If unset,
+	// DefaultHeader is used.
+	Header string `json:"header" yaml:"header"`
+
+	// MaxLength is the longest identifier that server middleware will accept from a request.
+	// Longer identifiers are replaced with generated ones.  If nonpositive, DefaultMaxLength is used.
+	MaxLength int `json:"maxLength" yaml:"maxLength"`
+
+	// Generate is the optional strategy for creating new request identifiers.
+	// If unset, NewID is used.
+	Generate func() string `json:"-" yaml:"-"`
+}
+
+func (cfg Config) header() string {
+	if len(cfg.Header) > 0 {
+		return http.CanonicalHeaderKey(cfg.Header)
+	}
+
+	return DefaultHeader
+}
+
+// NewServer creates serverside middleware that reads the request identifier from the configured
+// header, generating one if the header is missing or invalid.  The identifier is available to
+// handlers via FromContext and is written to the same header on the response.
+func NewServer(cfg Config) func(http.Handler) http.Handler {
+	var (
+		header    = cfg.header()
+		maxLength = cfg.MaxLength
+		generate  = cfg.Generate
+	)
+
+	if maxLength < 1 {
+		maxLength = DefaultMaxLength
+	}
+
+	if generate == nil {
+		generate = NewID
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
+			id := request.Header.Get(header)
+			if !valid(id, maxLength) {
+				id = generate()
+			}
+
+			// set the response header first, so that it is present however the handler writes
+			rw.Header().Set(header, id)
+			next.ServeHTTP(rw, request.WithContext(
+				WithID(request.Context(), id),
+			))
+		})
+	}
+}
+
+// outgoing produces the request to send.  If the request's context has an identifier and the
+// request doesn't already have the header, a clone with the header set is returned.  Otherwise,
+// the original request is returned as is.
+func outgoing(header string, request *http.Request) *http.Request {
+	id, ok := FromContext(request.Context())
+	if !ok || len(request.Header.Get(header)) > 0 {
+		return request
+	}
+
+	clone := request.Clone(request.Context())
+	clone.Header.Set(header, id)
+	return clone
+}
+
+// NewClient creates client middleware that copies the request identifier from each request's
+// context onto the configured header.  Requests without an identifier in their context, or that
+// already have the header, are sent unchanged.
+//
+// If the decorated client is nil, http.DefaultClient is used.
+func NewClient(cfg Config) client.Constructor {
+	header := cfg.header()
+	return func(next httpaux.Client) httpaux.Client {
+		if next == nil {
+			next = http.DefaultClient
+		}
+
+		return client.Func(func(request *http.Request) (*http.Response, error) {
+			return next.Do(outgoing(header, request))
+		})
+	}
+}
+
+// NewRoundTripper creates round tripper middleware that propagates request identifiers
+// just as NewClient does.
+//
+// If the decorated round tripper is nil, http.DefaultTransport is used.
+func NewRoundTripper(cfg Config) roundtrip.Constructor {
+	header := cfg.header()
+	return func(next http.RoundTripper) http.RoundTripper {
+		if next == nil {
+			next = http.DefaultTransport
+		}
+
+		return roundtrip.PreserveCloseIdler(
+			next,
+			roundtrip.Func(func(request *http.Request) (*http.Response, error) {
+				return next.RoundTrip(outgoing(header, request))
+			}),
+		)
+	}
+}
@@ -0,0 +1,216 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package requestid
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/erraux"
+	"github.com/xmidt-org/httpaux/httpmock"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+}
+
+// serve runs a request through server middleware, returning the identifier
+// seen by the handler and the response.
+func (suite *MiddlewareTestSuite) serve(cfg Config, request *http.Request) (handled string, response *http.Response) {
+	h := NewServer(cfg)(
+		http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
+			var ok bool
+			handled, ok = FromContext(request.Context())
+			suite.True(ok)
+			rw.WriteHeader(http.StatusNoContent)
+		}),
+	)
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, request)
+	response = rw.Result()
+	response.Body.Close()
+	return
+}
+
+func (suite *MiddlewareTestSuite) TestServer() {
+	generate := func() string { return "generated" }
+
+	suite.Run("FromRequest", func() {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set(DefaultHeader, "from-request")
+		handled, response := suite.serve(Config{Generate: generate}, request)
+		suite.Equal("from-request", handled)
+		suite.Equal("from-request", response.Header.Get(DefaultHeader))
+	})
+
+	suite.Run("Missing", func() {
+		handled, response := suite.serve(Config{Generate: generate}, httptest.NewRequest("GET", "/", nil))
+		suite.Equal("generated", handled)
+		suite.Equal("generated", response.Header.Get(DefaultHeader))
+	})
+
+	suite.Run("Invalid", func() {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set(DefaultHeader, "not valid")
+		handled, response := suite.serve(Config{Generate: generate}, request)
+		suite.Equal("generated", handled)
+		suite.Equal("generated", response.Header.Get(DefaultHeader))
+	})
+
+	suite.Run("TooLong", func() {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set(DefaultHeader, strings.Repeat("x", 9))
+		handled, _ := suite.serve(Config{MaxLength: 8, Generate: generate}, request)
+		suite.Equal("generated", handled)
+	})
+
+	suite.Run("CustomHeader", func() {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("X-Correlation-Id", "from-request")
+		handled, response := suite.serve(Config{Header: "x-correlation-id"}, request)
+		suite.Equal("from-request", handled)
+		suite.Equal("from-request", response.Header.Get("X-Correlation-Id"))
+		suite.Empty(response.Header.Get(DefaultHeader))
+	})
+
+	suite.Run("Default", func() {
+		handled, response := suite.serve(Config{}, httptest.NewRequest("GET", "/", nil))
+		suite.Len(handled, 36)
+		suite.Equal(handled, response.Header.Get(DefaultHeader))
+	})
+}
+
+func (suite *MiddlewareTestSuite) TestErrorFields() {
+	h := NewServer(Config{Generate: func() string { return "generated" }})(
+		http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
+			erraux.Encoder{}.ContextFields(ErrorFields).Encode(
+				request.Context(),
+				errors.New("expected"),
+				rw,
+			)
+		}),
+	)
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/", nil))
+	response := rw.Result()
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	suite.Require().NoError(err)
+	suite.Equal("generated", response.Header.Get(DefaultHeader))
+	suite.JSONEq(`{"code": 500, "cause": "expected", "requestId": "generated"}`, string(body))
+}
+
+func (suite *MiddlewareTestSuite) TestOutgoing() {
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		existing string
+		expected string
+	}{
+		{
+			name:     "FromContext",
+			ctx:      WithID(context.Background(), "expected"),
+			expected: "expected",
+		},
+		{
+			name: "NoID",
+			ctx:  context.Background(),
+		},
+		{
+			name:     "ExistingHeader",
+			ctx:      WithID(context.Background(), "from-context"),
+			existing: "existing",
+			expected: "existing",
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			request, err := http.NewRequestWithContext(testCase.ctx, "GET", "http://example.com/", nil)
+			suite.Require().NoError(err)
+			if len(testCase.existing) > 0 {
+				request.Header.Set(DefaultHeader, testCase.existing)
+			}
+
+			sent := outgoing(DefaultHeader, request)
+			suite.Require().NotNil(sent)
+			suite.Equal(testCase.expected, sent.Header.Get(DefaultHeader))
+
+			// the original request must never be modified
+			suite.Equal(testCase.existing, request.Header.Get(DefaultHeader))
+		})
+	}
+}
+
+// expectID returns a mock transport that expects the given identifier in the given header
+func (suite *MiddlewareTestSuite) expectID(header, expected string) *httpmock.RoundTripper {
+	next := httpmock.NewRoundTripperSuite(suite)
+	next.OnAny().AssertRequest(
+		httpmock.RequestAsserterFunc(func(assert *assert.Assertions, r *http.Request) {
+			assert.Equal(expected, r.Header.Get(header))
+		}),
+	).Return(&http.Response{StatusCode: http.StatusOK, Body: httpmock.EmptyBody()}, nil).Once()
+
+	return next
+}
+
+func (suite *MiddlewareTestSuite) TestNewClient() {
+	var (
+		next = suite.expectID(DefaultHeader, "expected")
+		c    = client.NewChain(NewClient(Config{})).Then(&http.Client{Transport: next})
+	)
+
+	request, err := http.NewRequestWithContext(WithID(context.Background(), "expected"), "GET", "http://example.com/", nil)
+	suite.Require().NoError(err)
+
+	response, err := c.Do(request)
+	suite.Require().NoError(err)
+	defer response.Body.Close()
+	next.AssertExpectations()
+}
+
+func (suite *MiddlewareTestSuite) TestNewClientDefault() {
+	suite.NotNil(NewClient(Config{})(nil))
+}
+
+func (suite *MiddlewareTestSuite) TestNewRoundTripper() {
+	var (
+		next = &httpmock.CloseIdler{
+			RoundTripper: suite.expectID("X-Correlation-ID", "expected"),
+		}
+
+		rt = roundtrip.NewChain(NewRoundTripper(Config{Header: "X-Correlation-ID"})).Then(next)
+	)
+
+	request, err := http.NewRequestWithContext(WithID(context.Background(), "expected"), "GET", "http://example.com/", nil)
+	suite.Require().NoError(err)
+
+	response, err := rt.RoundTrip(request)
+	suite.Require().NoError(err)
+	defer response.Body.Close()
+
+	next.OnCloseIdleConnections().Once()
+	roundtrip.CloseIdleConnections(rt)
+	next.AssertExpectations()
+}
+
+func (suite *MiddlewareTestSuite) TestNewRoundTripperDefault() {
+	suite.NotNil(NewRoundTripper(Config{})(nil))
+}
+
+func TestMiddleware(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
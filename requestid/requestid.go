@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package requestid
+
+import (
+	"context"
+
+	"github.com/xmidt-org/httpaux/internal/uuid"
+)
+
+const (
+	// DefaultHeader is the header used to transmit request identifiers when
+	// no header is configured.
+	DefaultHeader = "X-Request-ID"
+
+	// DefaultMaxLength is the longest request identifier accepted from a request
+	// when no maximum is configured.
+	DefaultMaxLength = 128
+
+	// ErrorField is the JSON field used by ErrorFields.
+	ErrorField = "requestId"
+)
+
+// contextKey is the internal context.Context key that stores the request identifier
+type contextKey struct{}
+
+// WithID returns a subcontext that carries the given request identifier.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request identifier carried by the given context.
+// If there is no identifier, this function returns the empty string and false.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok
+}
+
+// ErrorFields is an erraux.ContextFielder that adds the request identifier, if any,
+// to error bodies using the ErrorField name.
+func ErrorFields(ctx context.Context) []interface{} {
+	if id, ok := FromContext(ctx); ok {
+		return []interface{}{ErrorField, id}
+	}
+
+	return nil
+}
+
+// NewID generates a random, version 4 UUID.  This is the default strategy
+// for generating request identifiers.
+func NewID() string {
+	return uuid.NewV4()
+}
+
+// valid tests if an identifier from a request is acceptable.  Only nonempty,
+// visible ASCII identifiers no longer than maxLength are accepted, which keeps
+// untrusted input safe to echo in headers and to write to logs.
+func valid(id string, maxLength int) bool {
+	if len(id) == 0 || len(id) > maxLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
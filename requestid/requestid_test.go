@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package requestid
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RequestIDTestSuite struct {
+	suite.Suite
+}
+
+func (suite *RequestIDTestSuite) TestFromContext() {
+	id, ok := FromContext(context.Background())
+	suite.False(ok)
+	suite.Empty(id)
+
+	id, ok = FromContext(WithID(context.Background(), "expected"))
+	suite.True(ok)
+	suite.Equal("expected", id)
+}
+
+func (suite *RequestIDTestSuite) TestErrorFields() {
+	suite.Empty(ErrorFields(context.Background()))
+	suite.Equal(
+		[]interface{}{ErrorField, "expected"},
+		ErrorFields(WithID(context.Background(), "expected")),
+	)
+}
+
+func (suite *RequestIDTestSuite) TestNewID() {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := NewID()
+		suite.Regexp(uuid, id)
+		suite.False(seen[id])
+		seen[id] = true
+	}
+}
+
+func (suite *RequestIDTestSuite) TestValid() {
+	suite.True(valid("abc-123", 10))
+	suite.True(valid(strings.Repeat("x", 10), 10))
+	suite.False(valid("", 10))
+	suite.False(valid(strings.Repeat("x", 11), 10))
+	suite.False(valid("has space", 10))
+	suite.False(valid("crlf\r\n", 10))
+	suite.False(valid("café", 10))
+}
+
+func TestRequestID(t *testing.T) {
+	suite.Run(t, new(RequestIDTestSuite))
+}
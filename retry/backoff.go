@@ -0,0 +1,212 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Backoff is a strategy for computing how long to wait before each retry.
+type Backoff interface {
+	// Next returns the time to wait before the given 0-based retry.  The previous
+	// parameter is the value this method returned for the prior retry, or zero for the
+	// first retry.  The Random is the source of randomness for jitter.
+	Next(retry int, previous time.Duration, r Random) time.Duration
+}
+
+// BackoffFunc is a closure type that implements Backoff.
+type BackoffFunc func(int, time.Duration, Random) time.Duration
+
+// Next satisfies the Backoff interface.
+func (bf BackoffFunc) Next(retry int, previous time.Duration, r Random) time.Duration {
+	return bf(retry, previous, r)
+}
+
+// BackoffType identifies one of the built-in Backoff strategies.
+type BackoffType string
+
+const (
+	// BackoffExponential is the default strategy.  Each retry waits Interval*(Multiplier^n),
+	// multiplied by a random number in the range [1-Jitter,1+Jitter].  The empty
+	// string also selects this strategy.
+	BackoffExponential BackoffType = "exponential"
+
+	// BackoffFullJitter waits a random duration in the range [0, Interval*(Multiplier^n)].
+	BackoffFullJitter BackoffType = "fullJitter"
+
+	// BackoffEqualJitter waits half of Interval*(Multiplier^n) plus a random duration
+	// in the range [0, Interval*(Multiplier^n)/2].
+	BackoffEqualJitter BackoffType = "equalJitter"
+
+	// BackoffDecorrelatedJitter waits a random duration in the range [Interval, previous*3],
+	// where previous is the prior wait.  Multiplier and Jitter are ignored.
+	BackoffDecorrelatedJitter BackoffType = "decorrelatedJitter"
+
+	// BackoffConstant always waits Interval.  Multiplier and Jitter are ignored.
+	BackoffConstant BackoffType = "constant"
+
+	// BackoffList waits each duration in Config.Intervals in turn.  If there are more
+	// retries than intervals, the last interval is reused.  Multiplier and Jitter are ignored.
+	BackoffList BackoffType = "list"
+)
+
+// UnmarshalText validates that the text is one of the built-in backoff types.
+func (bt *BackoffType) UnmarshalText(text []byte) error {
+	switch v := BackoffType(text); v {
+	case "", BackoffExponential, BackoffFullJitter, BackoffEqualJitter, BackoffDecorrelatedJitter, BackoffConstant, BackoffList:
+		*bt = v
+		return nil
+
+	default:
+		return fmt.Errorf("invalid backoff type: %s", text)
+	}
+}
+
+// NewBackoff creates the Backoff strategy described by the given configuration.
+// If cfg.CustomBackoff is set, it is returned as is.  Any unrecognized cfg.Backoff
+// is treated as BackoffExponential.  If cfg.MaxInterval is positive, the returned
+// strategy will never wait longer than that value.
+func NewBackoff(cfg Config) Backoff {
+	if cfg.CustomBackoff != nil {
+		return cfg.CustomBackoff
+	}
+
+	var (
+		b    Backoff
+		base = cfg.Interval
+	)
+
+	if base <= 0 {
+		base = DefaultInterval
+	}
+
+	switch cfg.Backoff {
+	case BackoffFullJitter:
+		b = fullJitter{growth: newGrowth(base, cfg.Multiplier)}
+
+	case BackoffEqualJitter:
+		b = equalJitter{growth: newGrowth(base, cfg.Multiplier)}
+
+	case BackoffDecorrelatedJitter:
+		b = decorrelatedJitter{base: base}
+
+	case BackoffConstant:
+		b = constant(base)
+
+	case BackoffList:
+		if len(cfg.Intervals) > 0 {
+			b = list(append([]time.Duration{}, cfg.Intervals...))
+		} else {
+			b = constant(base)
+		}
+
+	default:
+		b = newIntervals(cfg)
+	}
+
+	if cfg.MaxInterval > 0 {
+		b = capped{next: b, max: cfg.MaxInterval}
+	}
+
+	return b
+}
+
+// growth computes the un-jittered, exponential duration for a retry
+type growth struct {
+	base       time.Duration
+	multiplier float64
+}
+
+func newGrowth(base time.Duration, multiplier float64) growth {
+	if multiplier <= 0.0 {
+		multiplier = 1.0
+	}
+
+	return growth{
+		base:       base,
+		multiplier: multiplier,
+	}
+}
+
+func (g growth) duration(retry int) time.Duration {
+	d := float64(g.base) * math.Pow(g.multiplier, float64(retry))
+	if d >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return time.Duration(math.Round(d))
+}
+
+// randomBetween returns a random duration in the closed range [lo, hi].
+func randomBetween(r Random, lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+
+	return lo + time.Duration(r.Int63n(int64(hi-lo)+1))
+}
+
+type fullJitter struct {
+	growth
+}
+
+func (fj fullJitter) Next(retry int, _ time.Duration, r Random) time.Duration {
+	return randomBetween(r, 0, fj.duration(retry))
+}
+
+type equalJitter struct {
+	growth
+}
+
+func (ej equalJitter) Next(retry int, _ time.Duration, r Random) time.Duration {
+	half := ej.duration(retry) / 2
+	return randomBetween(r, half, 2*half)
+}
+
+type decorrelatedJitter struct {
+	base time.Duration
+}
+
+func (dj decorrelatedJitter) Next(_ int, previous time.Duration, r Random) time.Duration {
+	hi := dj.base
+	if previous > 0 && previous < math.MaxInt64/3 {
+		hi = 3 * previous
+	} else if previous > 0 {
+		hi = time.Duration(math.MaxInt64)
+	}
+
+	return randomBetween(r, dj.base, hi)
+}
+
+type constant time.Duration
+
+func (c constant) Next(int, time.Duration, Random) time.Duration {
+	return time.Duration(c)
+}
+
+type list []time.Duration
+
+func (l list) Next(retry int, _ time.Duration, _ Random) time.Duration {
+	if retry < len(l) {
+		return l[retry]
+	}
+
+	return l[len(l)-1]
+}
+
+// capped decorates a Backoff so that it never exceeds a maximum
+type capped struct {
+	next Backoff
+	max  time.Duration
+}
+
+func (c capped) Next(retry int, previous time.Duration, r Random) time.Duration {
+	if d := c.next.Next(retry, previous, r); d < c.max {
+		return d
+	}
+
+	return c.max
+}
@@ -0,0 +1,240 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// fixedRandom is a Random that always returns the same fraction of its argument
+type fixedRandom float64
+
+func (fr fixedRandom) Int63n(v int64) int64 {
+	return int64(float64(v-1) * float64(fr))
+}
+
+type BackoffTestSuite struct {
+	suite.Suite
+}
+
+// sequence computes a sequence of waits from a Backoff
+func (suite *BackoffTestSuite) sequence(b Backoff, r Random, n int) []time.Duration {
+	var (
+		previous time.Duration
+		waits    = make([]time.Duration, 0, n)
+	)
+
+	for i := 0; i < n; i++ {
+		previous = b.Next(i, previous, r)
+		waits = append(waits, previous)
+	}
+
+	return waits
+}
+
+func (suite *BackoffTestSuite) TestBackoffFunc() {
+	var called bool
+	bf := BackoffFunc(func(retry int, previous time.Duration, r Random) time.Duration {
+		called = true
+		suite.Equal(3, retry)
+		suite.Equal(time.Second, previous)
+		return time.Minute
+	})
+
+	suite.Equal(time.Minute, bf.Next(3, time.Second, nil))
+	suite.True(called)
+}
+
+func (suite *BackoffTestSuite) TestUnmarshalText() {
+	for _, valid := range []string{"", "exponential", "fullJitter", "equalJitter", "decorrelatedJitter", "constant", "list"} {
+		suite.Run(valid, func() {
+			var cfg Config
+			suite.NoError(
+				json.Unmarshal([]byte(`{"backoff": "`+valid+`"}`), &cfg),
+			)
+
+			suite.Equal(BackoffType(valid), cfg.Backoff)
+		})
+	}
+
+	suite.Run("Invalid", func() {
+		var cfg Config
+		suite.Error(
+			json.Unmarshal([]byte(`{"backoff": "nosuch"}`), &cfg),
+		)
+	})
+}
+
+func (suite *BackoffTestSuite) TestExponential() {
+	testCases := []struct {
+		cfg      Config
+		random   Random
+		expected []time.Duration
+	}{
+		{
+			cfg:      Config{Retries: 3},
+			random:   fixedRandom(0),
+			expected: []time.Duration{DefaultInterval, DefaultInterval, DefaultInterval, DefaultInterval},
+		},
+		{
+			cfg:      Config{Retries: 3, Interval: time.Second, Multiplier: 2.0},
+			random:   fixedRandom(0),
+			expected: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second},
+		},
+		{
+			cfg:      Config{Backoff: BackoffExponential, Retries: 3, Interval: time.Second, Multiplier: 2.0, MaxInterval: 3 * time.Second},
+			random:   fixedRandom(0),
+			expected: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second},
+		},
+		{
+			cfg:      Config{Backoff: "unrecognized", Retries: 2, Interval: 10 * time.Second, Jitter: 0.5},
+			random:   fixedRandom(1),
+			expected: []time.Duration{15 * time.Second, 15 * time.Second},
+		},
+	}
+
+	for i, testCase := range testCases {
+		suite.Run(strconv.Itoa(i), func() {
+			b := NewBackoff(testCase.cfg)
+			suite.Equal(testCase.expected, suite.sequence(b, testCase.random, len(testCase.expected)))
+		})
+	}
+
+	suite.Run("NoRetries", func() {
+		b := NewBackoff(Config{})
+		suite.Equal(DefaultInterval, b.Next(0, 0, fixedRandom(0)))
+	})
+}
+
+func (suite *BackoffTestSuite) TestFullJitter() {
+	cfg := Config{
+		Backoff:    BackoffFullJitter,
+		Interval:   time.Second,
+		Multiplier: 2.0,
+	}
+
+	b := NewBackoff(cfg)
+	suite.Equal(
+		[]time.Duration{0, 0, 0},
+		suite.sequence(b, fixedRandom(0), 3),
+	)
+
+	suite.Equal(
+		[]time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		suite.sequence(b, fixedRandom(1), 3),
+	)
+
+	cfg.MaxInterval = 3 * time.Second
+	suite.Equal(
+		[]time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+		suite.sequence(NewBackoff(cfg), fixedRandom(1), 3),
+	)
+}
+
+func (suite *BackoffTestSuite) TestEqualJitter() {
+	b := NewBackoff(Config{
+		Backoff:    BackoffEqualJitter,
+		Interval:   2 * time.Second,
+		Multiplier: 2.0,
+	})
+
+	suite.Equal(
+		[]time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		suite.sequence(b, fixedRandom(0), 3),
+	)
+
+	suite.Equal(
+		[]time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second},
+		suite.sequence(b, fixedRandom(1), 3),
+	)
+}
+
+func (suite *BackoffTestSuite) TestDecorrelatedJitter() {
+	b := NewBackoff(Config{
+		Backoff:  BackoffDecorrelatedJitter,
+		Interval: time.Second,
+	})
+
+	suite.Equal(
+		[]time.Duration{time.Second, time.Second, time.Second},
+		suite.sequence(b, fixedRandom(0), 3),
+	)
+
+	suite.Equal(
+		[]time.Duration{time.Second, 3 * time.Second, 9 * time.Second},
+		suite.sequence(b, fixedRandom(1), 3),
+	)
+
+	// no overflow
+	suite.Greater(
+		b.Next(1, time.Duration(math.MaxInt64/2), fixedRandom(1)),
+		time.Duration(math.MaxInt64/2),
+	)
+
+	capped := NewBackoff(Config{
+		Backoff:     BackoffDecorrelatedJitter,
+		Interval:    time.Second,
+		MaxInterval: 5 * time.Second,
+	})
+
+	suite.Equal(
+		[]time.Duration{time.Second, 3 * time.Second, 5 * time.Second, 5 * time.Second},
+		suite.sequence(capped, fixedRandom(1), 4),
+	)
+}
+
+func (suite *BackoffTestSuite) TestConstant() {
+	suite.Equal(
+		[]time.Duration{DefaultInterval, DefaultInterval},
+		suite.sequence(NewBackoff(Config{Backoff: BackoffConstant}), fixedRandom(1), 2),
+	)
+
+	suite.Equal(
+		[]time.Duration{time.Minute, time.Minute},
+		suite.sequence(NewBackoff(Config{Backoff: BackoffConstant, Interval: time.Minute, Multiplier: 2.0, Jitter: 0.5}), fixedRandom(1), 2),
+	)
+}
+
+func (suite *BackoffTestSuite) TestList() {
+	cfg := Config{
+		Backoff:   BackoffList,
+		Intervals: []time.Duration{time.Second, 5 * time.Second, 3 * time.Second},
+	}
+
+	b := NewBackoff(cfg)
+	cfg.Intervals[0] = time.Hour // the backoff should have made a copy
+	suite.Equal(
+		[]time.Duration{time.Second, 5 * time.Second, 3 * time.Second, 3 * time.Second},
+		suite.sequence(b, fixedRandom(1), 4),
+	)
+
+	suite.Equal(
+		[]time.Duration{time.Minute, time.Minute},
+		suite.sequence(NewBackoff(Config{Backoff: BackoffList, Interval: time.Minute}), fixedRandom(1), 2),
+	)
+}
+
+func (suite *BackoffTestSuite) TestCustom() {
+	custom := BackoffFunc(func(int, time.Duration, Random) time.Duration {
+		return time.Hour
+	})
+
+	b := NewBackoff(Config{
+		Backoff:       BackoffConstant,
+		MaxInterval:   time.Second,
+		CustomBackoff: custom,
+	})
+
+	suite.Equal(time.Hour, b.Next(0, 0, fixedRandom(0)))
+}
+
+func TestBackoff(t *testing.T) {
+	suite.Run(t, new(BackoffTestSuite))
+}
@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"sync"
+	"time"
+
+	"github.com/xmidt-org/httpaux/clock"
+)
+
+const (
+	// DefaultBudgetRatio is used when BudgetConfig.Ratio is nonpositive.
+	DefaultBudgetRatio float64 = 0.1
+
+	// DefaultBudgetWindow is used when BudgetConfig.Window is nonpositive.
+	DefaultBudgetWindow time.Duration = 10 * time.Second
+
+	// budgetBuckets is the number of slices a budget window is divided into.
+	budgetBuckets = 10
+)
+
+// BudgetConfig is the set of configuration options for a retry Budget.
+type BudgetConfig struct {
+	// Ratio is the maximum ratio of retries to initial requests within the Window.
+	// For example, a Ratio of 0.1 allows retries to add at most 10% more traffic.
+	// If nonpositive, DefaultBudgetRatio is used.
+	Ratio float64 `json:"ratio" yaml:"ratio"`
+
+	// MinPerSecond is the number of retries per second that are always allowed,
+	// regardless of Ratio.  This allows retries to happen when there is very little
+	// traffic.  If nonpositive, there is no minimum.
+	MinPerSecond int `json:"minPerSecond" yaml:"minPerSecond"`
+
+	// Window is the rolling time period over which requests and retries are counted.
+	// If nonpositive, DefaultBudgetWindow is used.
+	Window time.Duration `json:"window" yaml:"window"`
+
+	// Clock is the optional source of time used to divide requests and retries
+	// into windows.  If unset, clock.System is used.
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+// budgetBucket is a slice of time within a budget's window
+type budgetBucket struct {
+	// epoch is the absolute index of this bucket, used to detect stale buckets
+	epoch    int64
+	requests int64
+	retries  int64
+}
+
+// Budget limits retries across any number of Clients so that an outage does not
+// multiply traffic.  Retries are allowed as long as they stay under a ratio of recent
+// initial requests, plus a minimum number of retries per second.
+//
+// A Budget is safe for concurrent use.  Typically, a single Budget is created with
+// NewBudget and then shared via Config.Budget among all Clients that talk to the same
+// service.
+type Budget struct {
+	lock sync.Mutex
+
+	ratio       float64
+	minRetries  float64
+	bucketWidth int64
+	buckets     [budgetBuckets]budgetBucket
+
+	// now is the source of the current time
+	now func() time.Time
+}
+
+// NewBudget creates a Budget from a configuration.
+func NewBudget(cfg BudgetConfig) *Budget {
+	b := &Budget{
+		ratio: cfg.Ratio,
+		now:   clock.OrSystem(cfg.Clock).Now,
+	}
+
+	if b.ratio <= 0.0 {
+		b.ratio = DefaultBudgetRatio
+	}
+
+	window := cfg.Window
+	if window <= 0 {
+		window = DefaultBudgetWindow
+	}
+
+	b.bucketWidth = int64(window) / budgetBuckets
+	if b.bucketWidth < 1 {
+		b.bucketWidth = 1
+	}
+
+	if cfg.MinPerSecond > 0 {
+		b.minRetries = float64(cfg.MinPerSecond) * window.Seconds()
+	}
+
+	return b
+}
+
+// current returns the bucket for the current time, resetting it if stale.
+// the absolute epoch of the current bucket is also returned.
+//
+// this method must be called under the lock.
+func (b *Budget) current() (*budgetBucket, int64) {
+	epoch := b.now().UnixNano() / b.bucketWidth
+	bucket := &b.buckets[epoch%budgetBuckets]
+	if bucket.epoch != epoch {
+		*bucket = budgetBucket{epoch: epoch}
+	}
+
+	return bucket, epoch
+}
+
+// request records an initial attempt
+func (b *Budget) request() {
+	defer b.lock.Unlock()
+	b.lock.Lock()
+
+	bucket, _ := b.current()
+	bucket.requests++
+}
+
+// tryRetry attempts to withdraw a retry from this budget.  If this method
+// returns false, the budget has been exhausted and no retry should be made.
+func (b *Budget) tryRetry() bool {
+	defer b.lock.Unlock()
+	b.lock.Lock()
+
+	bucket, epoch := b.current()
+
+	var requests, retries int64
+	for _, bb := range b.buckets {
+		if bb.epoch > epoch-budgetBuckets {
+			requests += bb.requests
+			retries += bb.retries
+		}
+	}
+
+	if float64(retries) >= b.minRetries+b.ratio*float64(requests) {
+		return false
+	}
+
+	bucket.retries++
+	return true
+}
@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/clock"
+)
+
+type BudgetTestSuite struct {
+	suite.Suite
+
+	clock *clock.Fake
+}
+
+func (suite *BudgetTestSuite) SetupTest() {
+	suite.clock = clock.NewFake(time.Date(2021, time.March, 4, 10, 30, 0, 0, time.UTC))
+}
+
+func (suite *BudgetTestSuite) newBudget(cfg BudgetConfig) *Budget {
+	cfg.Clock = suite.clock
+	b := NewBudget(cfg)
+	suite.Require().NotNil(b)
+	return b
+}
+
+func (suite *BudgetTestSuite) requests(b *Budget, n int) {
+	for i := 0; i < n; i++ {
+		b.request()
+	}
+}
+
+func (suite *BudgetTestSuite) TestDefaults() {
+	b := suite.newBudget(BudgetConfig{})
+	suite.Equal(DefaultBudgetRatio, b.ratio)
+	suite.Zero(b.minRetries)
+	suite.Equal(int64(DefaultBudgetWindow)/budgetBuckets, b.bucketWidth)
+
+	suite.False(b.tryRetry(), "no requests means no retries")
+	suite.requests(b, 10)
+	suite.True(b.tryRetry())
+	suite.False(b.tryRetry())
+}
+
+func (suite *BudgetTestSuite) TestRatio() {
+	b := suite.newBudget(BudgetConfig{
+		Ratio: 0.5,
+	})
+
+	suite.requests(b, 10)
+	for i := 0; i < 5; i++ {
+		suite.True(b.tryRetry())
+	}
+
+	suite.False(b.tryRetry())
+
+	b.request()
+	b.request()
+	suite.True(b.tryRetry())
+	suite.False(b.tryRetry())
+}
+
+func (suite *BudgetTestSuite) TestMinPerSecond() {
+	b := suite.newBudget(BudgetConfig{
+		MinPerSecond: 2,
+		Window:       time.Second,
+	})
+
+	suite.True(b.tryRetry())
+	suite.True(b.tryRetry())
+	suite.False(b.tryRetry())
+}
+
+func (suite *BudgetTestSuite) TestWindow() {
+	b := suite.newBudget(BudgetConfig{
+		Ratio:  1.0,
+		Window: 10 * time.Second,
+	})
+
+	b.request()
+	suite.True(b.tryRetry())
+	suite.False(b.tryRetry())
+
+	// still within the window
+	suite.clock.Add(5 * time.Second)
+	suite.False(b.tryRetry())
+
+	// everything has expired
+	suite.clock.Add(10 * time.Second)
+	suite.False(b.tryRetry())
+	b.request()
+	suite.True(b.tryRetry())
+}
+
+func TestBudget(t *testing.T) {
+	suite.Run(t, new(BudgetTestSuite))
+}
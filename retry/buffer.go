@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultMemoryBufferSize is used when Config.MemoryBufferSize is nonpositive
+	DefaultMemoryBufferSize int64 = 1 << 20
+)
+
+// BodyTooLargeError indicates that a request body could not be buffered for retries
+// because it exceeded Config.MaxBufferedBody.  No attempts are made in this case.
+type BodyTooLargeError struct {
+	// Limit is the maximum number of bytes that would have been buffered
+	Limit int64
+}
+
+// Error fulfills the error interface.
+func (err *BodyTooLargeError) Error() string {
+	var o strings.Builder
+	o.WriteString("request body exceeds the retry buffer limit of ")
+	o.WriteString(strconv.FormatInt(err.Limit, 10))
+	o.WriteString(" bytes")
+	return o.String()
+}
+
+// bodyBuffer makes request bodies replayable by reading them up front and
+// installing a GetBody function.
+type bodyBuffer struct {
+	// max is the maximum body size.  if nonpositive, buffering is disabled.
+	max int64
+
+	// memory is the number of bytes held in memory before spilling to a temp file
+	memory int64
+
+	// tempDir is the directory for temp files
+	tempDir string
+}
+
+func newBodyBuffer(cfg Config) bodyBuffer {
+	bb := bodyBuffer{
+		max:     cfg.MaxBufferedBody,
+		memory:  cfg.MemoryBufferSize,
+		tempDir: cfg.TempDir,
+	}
+
+	if bb.memory <= 0 {
+		bb.memory = DefaultMemoryBufferSize
+	}
+
+	bb.memory = min(bb.memory, bb.max)
+	return bb
+}
+
+// needed tests if buffering is enabled and the given request has a body
+// that cannot be replayed.
+func (bb bodyBuffer) needed(r *http.Request) bool {
+	return bb.max > 0 && r.Body != nil && r.Body != http.NoBody && r.GetBody == nil
+}
+
+// buffer consumes and closes the original request's body, returning a shallow copy
+// of the request whose body can be replayed via GetBody.  The returned release function
+// frees any resources, such as a temp file, and must be called once the request is no
+// longer in use.  A body held in memory has nothing to release, so release is nil.
+func (bb bodyBuffer) buffer(original *http.Request) (*http.Request, func(), error) {
+	defer original.Body.Close()
+	if original.ContentLength > bb.max {
+		return nil, nil, &BodyTooLargeError{Limit: bb.max}
+	}
+
+	var head bytes.Buffer
+	n, err := head.ReadFrom(io.LimitReader(original.Body, bb.memory+1))
+	switch {
+	case err != nil:
+		return nil, nil, err
+
+	case n > bb.max:
+		return nil, nil, &BodyTooLargeError{Limit: bb.max}
+
+	case n <= bb.memory:
+		b := head.Bytes()
+		return replayable(original, n, func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(b)), nil
+		}), nil, nil
+	}
+
+	// the body is too large to keep in memory
+	f, err := os.CreateTemp(bb.tempDir, "retry-body-*")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	release := func() {
+		f.Close()
+		os.Remove(f.Name())
+	}
+
+	size, err := io.Copy(f, io.LimitReader(io.MultiReader(&head, original.Body), bb.max+1))
+	switch {
+	case err != nil:
+		release()
+		return nil, nil, err
+
+	case size > bb.max:
+		release()
+		return nil, nil, &BodyTooLargeError{Limit: bb.max}
+	}
+
+	// each body reads independently from the file, so attempts can overlap
+	return replayable(original, size, func() (io.ReadCloser, error) {
+		return io.NopCloser(io.NewSectionReader(f, 0, size)), nil
+	}), release, nil
+}
+
+// replayable creates a shallow copy of the original request with the given GetBody
+// and a known content length.
+func replayable(original *http.Request, size int64, getBody func() (io.ReadCloser, error)) *http.Request {
+	r := original.WithContext(original.Context())
+	r.ContentLength = size
+	if size == 0 {
+		r.Body = http.NoBody
+		r.GetBody = func() (io.ReadCloser, error) {
+			return http.NoBody, nil
+		}
+	} else {
+		r.Body, _ = getBody()
+		r.GetBody = getBody
+	}
+
+	return r
+}
+
+// releaseOnCancel produces a context.CancelFunc that also releases other resources.
+// Either function may be nil.  If both are nil, this function returns nil.
+func releaseOnCancel(cancel context.CancelFunc, release func()) context.CancelFunc {
+	switch {
+	case release == nil:
+		return cancel
+
+	case cancel == nil:
+		return release
+	}
+
+	return func() {
+		defer release()
+		cancel()
+	}
+}
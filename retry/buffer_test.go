@@ -0,0 +1,190 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BufferTestSuite struct {
+	suite.Suite
+}
+
+// newRequest creates a request whose body cannot be replayed
+func (suite *BufferTestSuite) newRequest(body io.Reader) *http.Request {
+	r, err := http.NewRequest("POST", "/test", io.NopCloser(body))
+	suite.Require().NoError(err)
+	suite.Require().Nil(r.GetBody)
+	return r
+}
+
+// assertReplayable verifies that GetBody can be invoked repeatedly
+func (suite *BufferTestSuite) assertReplayable(r *http.Request, expected string) {
+	suite.Equal(int64(len(expected)), r.ContentLength)
+
+	b, err := io.ReadAll(r.Body)
+	suite.NoError(err)
+	suite.Equal(expected, string(b))
+
+	suite.Require().NotNil(r.GetBody)
+	for range 2 {
+		body, err := r.GetBody()
+		suite.Require().NoError(err)
+		b, err := io.ReadAll(body)
+		suite.NoError(err)
+		suite.Equal(expected, string(b))
+		suite.NoError(body.Close())
+	}
+}
+
+func (suite *BufferTestSuite) TestNeeded() {
+	suite.Run("Disabled", func() {
+		bb := newBodyBuffer(Config{})
+		suite.False(bb.needed(suite.newRequest(strings.NewReader("test"))))
+	})
+
+	suite.Run("Enabled", func() {
+		bb := newBodyBuffer(Config{MaxBufferedBody: 100})
+		suite.True(bb.needed(suite.newRequest(strings.NewReader("test"))))
+
+		r, err := http.NewRequest("POST", "/test", strings.NewReader("test"))
+		suite.Require().NoError(err)
+		suite.False(bb.needed(r)) // already has GetBody
+
+		r, err = http.NewRequest("GET", "/test", nil)
+		suite.Require().NoError(err)
+		suite.False(bb.needed(r))
+	})
+}
+
+func (suite *BufferTestSuite) TestMemory() {
+	bb := newBodyBuffer(Config{MaxBufferedBody: 100})
+	original := suite.newRequest(strings.NewReader("test"))
+	r, release, err := bb.buffer(original)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(r)
+	suite.Nil(release, "a body in memory has nothing to release")
+
+	suite.False(original == r)
+	suite.Nil(original.GetBody)
+	suite.assertReplayable(r, "test")
+}
+
+func (suite *BufferTestSuite) TestEmpty() {
+	bb := newBodyBuffer(Config{MaxBufferedBody: 100})
+	r, release, err := bb.buffer(suite.newRequest(strings.NewReader("")))
+	suite.Require().NoError(err)
+	suite.Nil(release)
+
+	suite.Equal(http.NoBody, r.Body)
+	suite.assertReplayable(r, "")
+}
+
+func (suite *BufferTestSuite) TestTempFile() {
+	var (
+		tempDir = suite.T().TempDir()
+		bb      = newBodyBuffer(Config{
+			MaxBufferedBody:  100,
+			MemoryBufferSize: 4,
+			TempDir:          tempDir,
+		})
+	)
+
+	r, release, err := bb.buffer(suite.newRequest(strings.NewReader("this body spills")))
+	suite.Require().NoError(err)
+	suite.assertReplayable(r, "this body spills")
+
+	entries, err := os.ReadDir(tempDir)
+	suite.NoError(err)
+	suite.Len(entries, 1)
+
+	release()
+	entries, err = os.ReadDir(tempDir)
+	suite.NoError(err)
+	suite.Empty(entries)
+}
+
+func (suite *BufferTestSuite) TestTooLarge() {
+	testCases := []struct {
+		name string
+		cfg  Config
+	}{
+		{
+			name: "Memory",
+			cfg:  Config{MaxBufferedBody: 4},
+		},
+		{
+			name: "TempFile",
+			cfg:  Config{MaxBufferedBody: 8, MemoryBufferSize: 2},
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			testCase.cfg.TempDir = suite.T().TempDir()
+			bb := newBodyBuffer(testCase.cfg)
+			r, release, err := bb.buffer(suite.newRequest(strings.NewReader("this body is too large")))
+			suite.Nil(r)
+			suite.Nil(release)
+
+			var tooLarge *BodyTooLargeError
+			suite.Require().ErrorAs(err, &tooLarge)
+			suite.Equal(testCase.cfg.MaxBufferedBody, tooLarge.Limit)
+			suite.Contains(tooLarge.Error(), "limit")
+
+			entries, err := os.ReadDir(testCase.cfg.TempDir)
+			suite.NoError(err)
+			suite.Empty(entries)
+		})
+	}
+
+	suite.Run("ContentLength", func() {
+		bb := newBodyBuffer(Config{MaxBufferedBody: 4})
+		original := suite.newRequest(iotest.ErrReader(errors.New("should not be read")))
+		original.ContentLength = 5
+		_, _, err := bb.buffer(original)
+
+		var tooLarge *BodyTooLargeError
+		suite.ErrorAs(err, &tooLarge)
+	})
+}
+
+func (suite *BufferTestSuite) TestReadError() {
+	expectedErr := errors.New("expected")
+	bb := newBodyBuffer(Config{MaxBufferedBody: 100})
+	r, release, err := bb.buffer(suite.newRequest(iotest.ErrReader(expectedErr)))
+	suite.Nil(r)
+	suite.Nil(release)
+	suite.ErrorIs(err, expectedErr)
+}
+
+func (suite *BufferTestSuite) TestReleaseOnCancel() {
+	suite.Nil(releaseOnCancel(nil, nil))
+
+	var canceled, released int
+	cancel := func() { canceled++ }
+	release := func() { released++ }
+
+	releaseOnCancel(cancel, nil)()
+	suite.Equal(1, canceled)
+
+	releaseOnCancel(nil, release)()
+	suite.Equal(1, released)
+
+	releaseOnCancel(cancel, release)()
+	suite.Equal(2, canceled)
+	suite.Equal(2, released)
+}
+
+func TestBuffer(t *testing.T) {
+	suite.Run(t, new(BufferTestSuite))
+}
@@ -0,0 +1,222 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/xmidt-org/httpaux/erraux"
+)
+
+// StatusRange is an inclusive range of HTTP status codes.  In configuration, a StatusRange
+// is written as a single code ("503"), a range ("500-599"), or a class ("5xx").
+type StatusRange struct {
+	// Min is the smallest status code in this range
+	Min int
+
+	// Max is the largest status code in this range
+	Max int
+}
+
+// Contains tests if the given status code lies within this range.
+func (sr StatusRange) Contains(statusCode int) bool {
+	return sr.Min <= statusCode && statusCode <= sr.Max
+}
+
+// MarshalText produces the textual form of this range.
+func (sr StatusRange) MarshalText() ([]byte, error) {
+	if sr.Min == sr.Max {
+		return []byte(strconv.Itoa(sr.Min)), nil
+	}
+
+	return []byte(strconv.Itoa(sr.Min) + "-" + strconv.Itoa(sr.Max)), nil
+}
+
+// UnmarshalText parses the textual form of a range.
+func (sr *StatusRange) UnmarshalText(text []byte) (err error) {
+	var (
+		v      = strings.TrimSpace(string(text))
+		lo, hi string
+	)
+
+	switch {
+	case len(v) == 3 && strings.HasSuffix(strings.ToLower(v), "xx"):
+		lo, hi = v[:1]+"00", v[:1]+"99"
+
+	default:
+		var found bool
+		lo, hi, found = strings.Cut(v, "-")
+		if !found {
+			hi = lo
+		}
+	}
+
+	var parsed StatusRange
+	parsed.Min, err = strconv.Atoi(strings.TrimSpace(lo))
+	if err == nil {
+		parsed.Max, err = strconv.Atoi(strings.TrimSpace(hi))
+	}
+
+	switch {
+	case err != nil:
+		return fmt.Errorf("invalid status range [%s]: %w", v, err)
+
+	case parsed.Min < 100 || parsed.Max > 999 || parsed.Min > parsed.Max:
+		return fmt.Errorf("invalid status range [%s]", v)
+
+	default:
+		*sr = parsed
+		return nil
+	}
+}
+
+// CheckConfig is a declarative, serializable description of a Check.  A result is
+// retryable if it matches any of the criteria given in this configuration.  Use NewCheck
+// to produce the Check.
+type CheckConfig struct {
+	// Statuses are the HTTP status codes that are retryable.
+	Statuses []StatusRange `json:"statuses" yaml:"statuses"`
+
+	// ConnectionReset indicates that errors caused by the connection being reset or
+	// broken by the peer are retryable.
+	ConnectionReset bool `json:"connectionReset" yaml:"connectionReset"`
+
+	// Timeout indicates that network timeouts are retryable.  An expired request context,
+	// i.e. context.DeadlineExceeded, is never retried.
+	Timeout bool `json:"timeout" yaml:"timeout"`
+
+	// DNS indicates that DNS lookup failures are retryable.  Lookups for hosts that
+	// do not exist are never retried.
+	DNS bool `json:"dns" yaml:"dns"`
+
+	// Temporary indicates that any error with a Temporary() method that returns true
+	// is retryable.  This is the same behavior for errors as DefaultCheck.
+	Temporary bool `json:"temporary" yaml:"temporary"`
+
+	// Methods restricts retries to requests with these HTTP methods.  If unset, requests
+	// with any method may be retried.
+	//
+	// Methods are not part of the Check produced by NewCheck, since a Check does not
+	// have access to the request.  Instead, a Client enforces this restriction in the
+	// same way as Config.IdempotentOnly.
+	Methods []string `json:"methods" yaml:"methods"`
+}
+
+// NewCheck compiles this configuration into a Check.  The returned Check can be
+// combined with other Check functions via CheckAny and CheckAll.
+func (cc CheckConfig) NewCheck() Check {
+	statuses := append([]StatusRange(nil), cc.Statuses...)
+	return func(r *http.Response, err error) bool {
+		if r != nil {
+			for _, sr := range statuses {
+				if sr.Contains(r.StatusCode) {
+					return true
+				}
+			}
+		}
+
+		switch {
+		case err == nil:
+			return false
+
+		case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled):
+			return false
+
+		case cc.ConnectionReset && isConnectionReset(err):
+			return true
+
+		case cc.Timeout && isTimeout(err):
+			return true
+
+		case cc.DNS && isDNSFailure(err):
+			return true
+
+		case cc.Temporary && erraux.IsTemporary(err):
+			return true
+
+		default:
+			return false
+		}
+	}
+}
+
+// allowsMethod tests if this configuration permits retries for the given method.
+func (cc CheckConfig) allowsMethod(method string) bool {
+	if len(cc.Methods) == 0 {
+		return true
+	}
+
+	if len(method) == 0 {
+		method = http.MethodGet
+	}
+
+	for _, m := range cc.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isConnectionReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE)
+}
+
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+func isDNSFailure(err error) bool {
+	var de *net.DNSError
+	return errors.As(err, &de) && !de.IsNotFound
+}
+
+// CheckAny produces a Check that retries if any of the given checks returns true.
+// Nil checks are skipped.
+func CheckAny(checks ...Check) Check {
+	checks = compactChecks(checks)
+	return func(r *http.Response, err error) bool {
+		for _, c := range checks {
+			if c(r, err) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// CheckAll produces a Check that retries only if all of the given checks return true.
+// Nil checks are skipped.  If there are no checks, the returned Check never retries.
+func CheckAll(checks ...Check) Check {
+	checks = compactChecks(checks)
+	return func(r *http.Response, err error) bool {
+		for _, c := range checks {
+			if !c(r, err) {
+				return false
+			}
+		}
+
+		return len(checks) > 0
+	}
+}
+
+func compactChecks(checks []Check) (compacted []Check) {
+	for _, c := range checks {
+		if c != nil {
+			compacted = append(compacted, c)
+		}
+	}
+
+	return
+}
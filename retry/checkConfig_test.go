@@ -0,0 +1,243 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StatusRangeTestSuite struct {
+	suite.Suite
+}
+
+func (suite *StatusRangeTestSuite) TestUnmarshalText() {
+	testCases := []struct {
+		text     string
+		expected StatusRange
+	}{
+		{text: "503", expected: StatusRange{Min: 503, Max: 503}},
+		{text: " 500-599 ", expected: StatusRange{Min: 500, Max: 599}},
+		{text: "500 - 504", expected: StatusRange{Min: 500, Max: 504}},
+		{text: "5xx", expected: StatusRange{Min: 500, Max: 599}},
+		{text: "4XX", expected: StatusRange{Min: 400, Max: 499}},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.text, func() {
+			var actual StatusRange
+			suite.Require().NoError(actual.UnmarshalText([]byte(testCase.text)))
+			suite.Equal(testCase.expected, actual)
+		})
+	}
+}
+
+func (suite *StatusRangeTestSuite) TestUnmarshalTextInvalid() {
+	for _, text := range []string{"", "abc", "5xy", "599-500", "99", "1000", "500-"} {
+		suite.Run(text, func() {
+			var actual StatusRange
+			suite.Error(actual.UnmarshalText([]byte(text)))
+			suite.Zero(actual)
+		})
+	}
+}
+
+func (suite *StatusRangeTestSuite) TestMarshalText() {
+	text, err := StatusRange{Min: 429, Max: 429}.MarshalText()
+	suite.NoError(err)
+	suite.Equal("429", string(text))
+
+	text, err = StatusRange{Min: 500, Max: 599}.MarshalText()
+	suite.NoError(err)
+	suite.Equal("500-599", string(text))
+}
+
+func (suite *StatusRangeTestSuite) TestContains() {
+	sr := StatusRange{Min: 500, Max: 502}
+	suite.False(sr.Contains(499))
+	suite.True(sr.Contains(500))
+	suite.True(sr.Contains(502))
+	suite.False(sr.Contains(503))
+}
+
+func TestStatusRange(t *testing.T) {
+	suite.Run(t, new(StatusRangeTestSuite))
+}
+
+type CheckConfigTestSuite struct {
+	suite.Suite
+}
+
+func (suite *CheckConfigTestSuite) TestUnmarshalJSON() {
+	var cfg Config
+	err := json.Unmarshal(
+		[]byte(`{
+			"retries": 2,
+			"check": {
+				"statuses": ["429", "5xx"],
+				"connectionReset": true,
+				"timeout": true,
+				"methods": ["GET", "PUT"]
+			}
+		}`),
+		&cfg,
+	)
+
+	suite.Require().NoError(err)
+	suite.Require().NotNil(cfg.CheckConfig)
+	suite.Equal(
+		CheckConfig{
+			Statuses:        []StatusRange{{Min: 429, Max: 429}, {Min: 500, Max: 599}},
+			ConnectionReset: true,
+			Timeout:         true,
+			Methods:         []string{"GET", "PUT"},
+		},
+		*cfg.CheckConfig,
+	)
+}
+
+func (suite *CheckConfigTestSuite) TestNewCheck() {
+	var (
+		timeoutErr = &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}
+		resetErr   = &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+		dnsErr     = &net.DNSError{Err: "server misbehaving"}
+		notFound   = &net.DNSError{Err: "no such host", IsNotFound: true}
+		tempErr    = &net.DNSError{IsTemporary: true, IsNotFound: true}
+	)
+
+	testCases := []struct {
+		name     string
+		cfg      CheckConfig
+		response *http.Response
+		err      error
+		expected bool
+	}{
+		{
+			name:     "Empty",
+			response: &http.Response{StatusCode: http.StatusServiceUnavailable},
+			err:      timeoutErr,
+			expected: false,
+		},
+		{
+			name:     "Status",
+			cfg:      CheckConfig{Statuses: []StatusRange{{Min: 500, Max: 599}}},
+			response: &http.Response{StatusCode: http.StatusServiceUnavailable},
+			expected: true,
+		},
+		{
+			name:     "StatusNoMatch",
+			cfg:      CheckConfig{Statuses: []StatusRange{{Min: 500, Max: 599}}},
+			response: &http.Response{StatusCode: http.StatusOK},
+			expected: false,
+		},
+		{
+			name:     "ConnectionReset",
+			cfg:      CheckConfig{ConnectionReset: true},
+			err:      resetErr,
+			expected: true,
+		},
+		{
+			name:     "ConnectionResetDisabled",
+			cfg:      CheckConfig{Timeout: true},
+			err:      resetErr,
+			expected: false,
+		},
+		{
+			name:     "Timeout",
+			cfg:      CheckConfig{Timeout: true},
+			err:      fmt.Errorf("wrapped: %w", timeoutErr),
+			expected: true,
+		},
+		{
+			name:     "ContextDeadline",
+			cfg:      CheckConfig{Timeout: true, Temporary: true},
+			err:      context.DeadlineExceeded,
+			expected: false,
+		},
+		{
+			name:     "ContextCanceled",
+			cfg:      CheckConfig{Timeout: true, Temporary: true},
+			err:      context.Canceled,
+			expected: false,
+		},
+		{
+			name:     "DNS",
+			cfg:      CheckConfig{DNS: true},
+			err:      dnsErr,
+			expected: true,
+		},
+		{
+			name:     "DNSNotFound",
+			cfg:      CheckConfig{DNS: true},
+			err:      notFound,
+			expected: false,
+		},
+		{
+			name:     "Temporary",
+			cfg:      CheckConfig{Temporary: true},
+			err:      tempErr,
+			expected: true,
+		},
+		{
+			name:     "OtherError",
+			cfg:      CheckConfig{ConnectionReset: true, Timeout: true, DNS: true, Temporary: true},
+			err:      errors.New("not retryable"),
+			expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			check := testCase.cfg.NewCheck()
+			suite.Require().NotNil(check)
+			suite.Equal(testCase.expected, check(testCase.response, testCase.err))
+		})
+	}
+}
+
+func (suite *CheckConfigTestSuite) TestAllowsMethod() {
+	suite.True(CheckConfig{}.allowsMethod("POST"))
+
+	cc := CheckConfig{Methods: []string{"get", "PUT"}}
+	suite.True(cc.allowsMethod(""))
+	suite.True(cc.allowsMethod("GET"))
+	suite.True(cc.allowsMethod("PUT"))
+	suite.False(cc.allowsMethod("POST"))
+}
+
+func (suite *CheckConfigTestSuite) TestCheckAny() {
+	var (
+		yes Check = func(*http.Response, error) bool { return true }
+		no  Check = func(*http.Response, error) bool { return false }
+	)
+
+	suite.False(CheckAny()(nil, nil))
+	suite.False(CheckAny(nil, no)(nil, nil))
+	suite.True(CheckAny(no, nil, yes)(nil, nil))
+}
+
+func (suite *CheckConfigTestSuite) TestCheckAll() {
+	var (
+		yes Check = func(*http.Response, error) bool { return true }
+		no  Check = func(*http.Response, error) bool { return false }
+	)
+
+	suite.False(CheckAll()(nil, nil))
+	suite.False(CheckAll(nil)(nil, nil))
+	suite.True(CheckAll(yes, nil, yes)(nil, nil))
+	suite.False(CheckAll(yes, no)(nil, nil))
+}
+
+func TestCheckConfig(t *testing.T) {
+	suite.Run(t, new(CheckConfigTestSuite))
+}
@@ -5,12 +5,15 @@ package retry
 
 import (
 	"context"
+	"errors"
+	"io"
 	"math/rand"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/xmidt-org/httpaux"
+	"github.com/xmidt-org/httpaux/clock"
 )
 
 // GetBodyError indicates that http.Request.GetBody returned an error.  Retries
@@ -37,6 +40,41 @@ func (err *GetBodyError) Error() string {
 	return o.String()
 }
 
+// cancelBody ties the cancellation of a context to the closing of
+// a response body.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (cb cancelBody) Close() error {
+	defer cb.cancel()
+	return cb.ReadCloser.Close()
+}
+
+// cancelOnClose arranges for cancel to be invoked when the response's body
+// is closed.  If cancel is nil, there is nothing to release and the response
+// is left alone.  If there is no response body, cancel is invoked immediately.
+//
+// The body of a 101 Switching Protocols response is never wrapped, since it is
+// an io.ReadWriteCloser that callers need to see.  The upgraded connection no
+// longer depends on the request's context, so cancel is invoked immediately.
+func cancelOnClose(response *http.Response, cancel context.CancelFunc) {
+	switch {
+	case cancel == nil:
+		// nothing to release
+
+	case response == nil || response.Body == nil || response.StatusCode == http.StatusSwitchingProtocols:
+		cancel()
+
+	default:
+		response.Body = cancelBody{
+			ReadCloser: response.Body,
+			cancel:     cancel,
+		}
+	}
+}
+
 // Client is an httpaux.Client that retries HTTP transactions.  Instances can be used
 // in (2) ways:
 //
@@ -47,11 +85,18 @@ func (err *GetBodyError) Error() string {
 // (2) Client.Then can be used as client middleware to attach retry semantics
 // to other HTTP clients.
 type Client struct {
+	// cfg is the configuration used to create this Client.  this is
+	// retained so that a Backoff can be computed for per-request overrides.
+	cfg Config
+
 	// next is the decorated client used to execute HTTP transactions
 	next httpaux.Client
 
-	// intervals is the precomputed retry intervals, including jitter
-	intervals intervals
+	// retries is the maximum number of retries, not including the initial attempt
+	retries int
+
+	// backoff is the strategy for computing the wait before each retry
+	backoff Backoff
 
 	// random is the source of randomness
 	random Random
@@ -61,6 +106,36 @@ type Client struct {
 
 	// check is the Check strategy for determining if a request should be retried
 	check Check
+
+	// maxElapsedTime is the optional limit on the total time for all attempts
+	maxElapsedTime time.Duration
+
+	// perAttemptTimeout is the optional limit on each individual attempt
+	perAttemptTimeout time.Duration
+
+	// idempotentOnly restricts retries to idempotent requests
+	idempotentOnly bool
+
+	// allowsMethod restricts retries by HTTP method
+	allowsMethod func(string) bool
+
+	// budget is the optional retry budget shared with other clients
+	budget *Budget
+
+	// hooks are the lifecycle callbacks
+	hooks hooks
+
+	// buffer is the strategy for making request bodies replayable
+	buffer bodyBuffer
+
+	// failover is the optional list of alternate hosts for retries
+	failover failover
+
+	// retryAfter is the strategy for honoring Retry-After headers
+	retryAfter retryAfter
+
+	// now is the source of the current time
+	now func() time.Time
 }
 
 // New constructs a Client from a configuration.  If cfg.Retries
@@ -70,11 +145,23 @@ type Client struct {
 // is nil, http.DefaultClient is used.
 func New(cfg Config, next httpaux.Client) (c *Client) {
 	c = &Client{
-		next:      next,
-		intervals: newIntervals(cfg),
-		random:    cfg.Random,
-		timer:     cfg.Timer,
-		check:     cfg.Check,
+		cfg:               cfg,
+		next:              next,
+		retries:           max(cfg.Retries, 0),
+		backoff:           NewBackoff(cfg),
+		random:            cfg.Random,
+		timer:             cfg.Timer,
+		check:             cfg.Check,
+		idempotentOnly:    cfg.IdempotentOnly,
+		allowsMethod:      func(string) bool { return true },
+		maxElapsedTime:    cfg.MaxElapsedTime,
+		perAttemptTimeout: cfg.PerAttemptTimeout,
+		budget:            cfg.Budget,
+		hooks:             newHooks(cfg),
+		buffer:            newBodyBuffer(cfg),
+		failover:          newFailover(cfg),
+		retryAfter:        newRetryAfter(cfg),
+		now:               clock.OrSystem(cfg.Clock).Now,
 	}
 
 	if c.next == nil {
@@ -88,10 +175,21 @@ func New(cfg Config, next httpaux.Client) (c *Client) {
 		)
 	}
 
-	if c.timer == nil {
+	if c.timer == nil && cfg.Clock != nil {
+		c.timer = ClockTimer(cfg.Clock)
+	} else if c.timer == nil {
 		c.timer = DefaultTimer
 	}
 
+	if cfg.CheckConfig != nil {
+		c.allowsMethod = cfg.CheckConfig.allowsMethod
+		if c.check != nil {
+			c.check = CheckAny(c.check, cfg.CheckConfig.NewCheck())
+		} else {
+			c.check = cfg.CheckConfig.NewCheck()
+		}
+	}
+
 	if c.check == nil {
 		c.check = DefaultCheck
 	}
@@ -105,17 +203,97 @@ func New(cfg Config, next httpaux.Client) (c *Client) {
 //
 // The State instance returned by GetState will reflect this same value.
 func (c *Client) Retries() int {
-	return c.intervals.Len()
+	return c.retries
+}
+
+// policy is the effective retry semantics for a single request
+type policy struct {
+	retries int
+	backoff Backoff
+	check   Check
+}
+
+// policyFor determines the retry semantics for a request, taking into
+// account any overrides in the request's context.
+func (c *Client) policyFor(request *http.Request) (p policy) {
+	p.retries = c.retries
+	p.backoff = c.backoff
+	p.check = c.check
+
+	o := getOverrides(request.Context())
+	switch {
+	case !o.hasRetries && c.idempotentOnly && !IsIdempotent(request):
+		p.retries = 0
+
+	case !o.hasRetries && !c.allowsMethod(request.Method):
+		p.retries = 0
+
+	case !o.hasRetries:
+		// no change
+
+	case o.retries <= p.retries:
+		// optimization: the backoff can be reused
+		p.retries = max(o.retries, 0)
+
+	default:
+		// some backoffs, such as the default, precompute their waits
+		cfg := c.cfg
+		cfg.Retries = o.retries
+		p.retries = o.retries
+		p.backoff = NewBackoff(cfg)
+	}
+
+	if o.check != nil {
+		p.check = o.check
+	}
+
+	return
 }
 
-// initialize sets up a series of retry attempts for a given request
-func (c *Client) initialize(original *http.Request) (*State, context.Context) {
+// initialize sets up a series of retry attempts for a given request.  The returned
+// context carries the State and is bounded by any MaxElapsedTime.  The returned
+// cancel function, if not nil, must be called to release that context.
+func (c *Client) initialize(original *http.Request) (*State, policy, context.Context, context.CancelFunc) {
+	p := c.policyFor(original)
 	s := &State{
-		retries: c.intervals.Len(),
+		retries: p.retries,
 	}
 
-	retryCtx := withState(original.Context(), s)
-	return s, retryCtx
+	track(original.Context(), s)
+
+	var (
+		retryCtx = withState(original.Context(), s)
+		cancel   context.CancelFunc
+	)
+
+	if c.maxElapsedTime > 0 {
+		retryCtx, cancel = context.WithTimeout(retryCtx, c.maxElapsedTime)
+	}
+
+	return s, p, retryCtx, cancel
+}
+
+// attempt makes a single attempt to execute the given request, applying any per-attempt
+// timeout.  The timedOut flag indicates that the per-attempt timeout elapsed while the
+// request's own context remained active.
+func (c *Client) attempt(request *http.Request) (response *http.Response, timedOut bool, err error) {
+	if c.perAttemptTimeout <= 0 {
+		response, err = c.next.Do(request)
+		return
+	}
+
+	attemptCtx, cancel := context.WithTimeout(request.Context(), c.perAttemptTimeout)
+	response, err = c.next.Do(request.WithContext(attemptCtx))
+	timedOut = request.Context().Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded)
+	cancelOnClose(response, cancel)
+	return
+}
+
+// exceedsDeadline tests if waiting the given duration would pass the context's deadline.
+// Context deadlines are wall clock times, so the configured Clock is not used here.
+func exceedsDeadline(ctx context.Context, wait time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && time.Now().Add(wait).After(deadline)
 }
 
 // Do takes in an original *http.Request and makes an initial attempt plus
@@ -124,25 +302,88 @@ func (c *Client) initialize(original *http.Request) (*State, context.Context) {
 // For each request, a State instance is put into the request's context that
 // allows decorated clients to access information about the current attempt.
 // Decorated code can obtain this State with a call to GetState.
+//
+// The number of retries and the Check strategy can be overridden for individual
+// requests.  See WithRetries, WithoutRetries, and WithCheck.
+//
+// If the request's context has a deadline, either from the caller or from
+// Config.MaxElapsedTime, a retry whose wait would pass that deadline is not attempted.
+// Instead, the most recent result is returned.
+//
+// If Config.MaxBufferedBody is set, a request body without GetBody is buffered before
+// the initial attempt.  A body that is too large results in a *BodyTooLargeError.
 func (c *Client) Do(original *http.Request) (*http.Response, error) {
-	state, retryCtx := c.initialize(original)
+	state, p, retryCtx, cancel := c.initialize(original)
+	if p.retries > 0 && c.buffer.needed(original) {
+		buffered, release, err := c.buffer.buffer(original)
+		if err != nil {
+			if cancel != nil {
+				cancel()
+			}
+
+			return nil, err
+		}
+
+		original = buffered
+		cancel = releaseOnCancel(cancel, release)
+	}
+
+	response, err := c.do(original, state, p, retryCtx)
+	if state.gaveUp {
+		c.hooks.onGiveUp(state, response, err)
+	}
+
+	// the overall context must live until the caller is finished with the response
+	cancelOnClose(response, cancel)
+	return response, err
+}
+
+// do is the retry loop for Do.
+func (c *Client) do(original *http.Request, state *State, p policy, retryCtx context.Context) (*http.Response, error) {
+	if c.budget != nil {
+		c.budget.request()
+	}
 
 	// make the initial attempt
 	// if no retries were configured, we won't even bother to invoke the check
-	response, err := c.next.Do(original.WithContext(retryCtx))
-	if len(c.intervals) == 0 || !c.check(response, err) {
+	state.hosts = append(state.hosts, requestHost(original))
+	c.hooks.onAttempt(state)
+	response, timedOut, err := c.attempt(original.WithContext(retryCtx))
+	if p.retries == 0 || (!timedOut && !p.check(response, err)) {
 		// NOTE: leave this response's Body alone, so callers can see it
 		return response, err
 	}
 
-	getBody := original.GetBody
-	for i := 0; i < c.Retries(); i++ {
-		wait := c.intervals.duration(c.random, i)
+	// from here on, any return other than a successful attempt means giving up
+	state.gaveUp = true
+
+	var (
+		getBody  = original.GetBody
+		previous time.Duration
+	)
+
+	for i := 0; i < p.retries; i++ {
+		previous = p.backoff.Next(i, previous, c.random)
+		wait := c.retryAfter.adjust(previous, response, c.now())
+		if exceedsDeadline(retryCtx, wait) {
+			// there's no point in waiting only to fail
+			// NOTE: leave this response's Body alone, so callers can see it
+			return response, err
+		}
+
+		if c.budget != nil && !c.budget.tryRetry() {
+			state.budgetExhausted = true
+
+			// NOTE: leave this response's Body alone, so callers can see it
+			return response, err
+		}
+
 		tc, stop := c.timer(wait)
 
 		// call this here, so that the response is cleaned up
 		// while we wait for the next retry
 		state.prepareNext(response, err)
+		c.hooks.onRetry(state.attempt, wait, state.previous, state.previousErr)
 
 		select {
 		case <-retryCtx.Done():
@@ -150,23 +391,31 @@ func (c *Client) Do(original *http.Request) (*http.Response, error) {
 			return nil, retryCtx.Err()
 
 		case <-tc:
-			// continue
+			state.waited += wait
 		}
 
+		// NOTE: never modify the original request, as this client
+		// may be used as an http.RoundTripper
+		request := original.WithContext(retryCtx)
 		if getBody != nil {
 			body, err := getBody()
 			if err != nil {
 				return nil, &GetBodyError{Err: err}
 			}
 
-			original.Body = body
+			request.Body = body
 		}
 
-		response, err = c.next.Do(original.WithContext(retryCtx))
+		c.failover.apply(request, state.attempt)
+		state.hosts = append(state.hosts, requestHost(request))
+		c.hooks.onAttempt(state)
+		response, timedOut, err = c.attempt(request)
 		if retryCtx.Err() != nil {
 			httpaux.Cleanup(response) // just in case we have a misbehaving next client
 			return nil, retryCtx.Err()
-		} else if !c.check(response, err) {
+		} else if !timedOut && !p.check(response, err) {
+			state.gaveUp = false
+
 			// NOTE: leave this response's Body alone, so callers can see it
 			return response, err
 		}
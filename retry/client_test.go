@@ -18,6 +18,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/clock"
 	"github.com/xmidt-org/httpaux/httpmock"
 )
 
@@ -723,6 +725,973 @@ func (suite *ClientTestSuite) TestGetBodyError() {
 	suite.ErrorIs(getBodyErr, expectedErr)
 }
 
+func (suite *ClientTestSuite) TestRetryAfter() {
+	var (
+		timer = make(chan time.Time, 1)
+		waits []time.Duration
+
+		rt     = httpmock.NewRoundTripperSuite(suite)
+		client = New(
+			Config{
+				Retries:    1,
+				Interval:   5 * time.Second,
+				RetryAfter: RetryAfterOverride,
+				Check: func(r *http.Response, _ error) bool {
+					return r.StatusCode == http.StatusTooManyRequests
+				},
+				Timer: func(d time.Duration) (<-chan time.Time, func() bool) {
+					waits = append(waits, d)
+					timer <- time.Time{} // no waiting
+					return timer, func() bool { return true }
+				},
+			},
+			&http.Client{
+				Transport: rt,
+			},
+		)
+	)
+
+	rt.OnAny().Return(
+		&http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{RetryAfterHeader: {"17"}},
+			Body:       httpmock.EmptyBody(),
+		},
+		nil,
+	).Once()
+
+	rt.OnAny().Return(
+		&http.Response{
+			StatusCode: http.StatusOK,
+			Body:       httpmock.EmptyBody(),
+		},
+		nil,
+	).Once()
+
+	request, err := http.NewRequest("GET", "/test", nil)
+	suite.Require().NoError(err)
+
+	response, err := client.Do(request)
+	suite.NoError(err)
+	suite.Require().NotNil(response)
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Equal([]time.Duration{17 * time.Second}, waits)
+	rt.AssertExpectations()
+}
+
+func (suite *ClientTestSuite) TestBudgetExhausted() {
+	var (
+		state *State
+
+		budget = NewBudget(BudgetConfig{
+			Ratio: 1.0,
+		})
+
+		rt     = httpmock.NewRoundTripperSuite(suite)
+		client = New(
+			Config{
+				Retries: 5,
+				Budget:  budget,
+				Check: func(*http.Response, error) bool {
+					return true
+				},
+				Timer: func(d time.Duration) (<-chan time.Time, func() bool) {
+					tc := make(chan time.Time)
+					close(tc)
+					return tc, func() bool { return true }
+				},
+			},
+			&http.Client{
+				Transport: rt,
+			},
+		)
+	)
+
+	// the initial attempt deposits enough for exactly (1) retry
+	rt.OnAny().Return(new(http.Response), nil).
+		Run(func(args mock.Arguments) {
+			state = GetState(args.Get(0).(*http.Request).Context())
+		}).
+		Times(2)
+
+	request, err := http.NewRequest("GET", "/test", nil)
+	suite.Require().NoError(err)
+
+	response, err := client.Do(request)
+	suite.NoError(err)
+	suite.NotNil(response)
+	rt.AssertExpectations()
+
+	suite.Require().NotNil(state)
+	suite.Equal(1, state.Attempt())
+	suite.True(state.BudgetExhausted())
+}
+
+func (suite *ClientTestSuite) TestOverrides() {
+	alwaysRetry := func(*http.Response, error) bool {
+		return true
+	}
+
+	noWait := func(time.Duration) (<-chan time.Time, func() bool) {
+		tc := make(chan time.Time)
+		close(tc)
+		return tc, func() bool { return true }
+	}
+
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		expected int
+		retries  int
+	}{
+		{
+			name:     "None",
+			ctx:      context.Background(),
+			expected: 2,
+			retries:  2,
+		},
+		{
+			name:     "WithoutRetries",
+			ctx:      WithoutRetries(context.Background()),
+			expected: 0,
+		},
+		{
+			name:     "NegativeRetries",
+			ctx:      WithRetries(context.Background(), -1),
+			expected: 0,
+		},
+		{
+			name:     "FewerRetries",
+			ctx:      WithRetries(context.Background(), 1),
+			expected: 1,
+			retries:  1,
+		},
+		{
+			name:     "MoreRetries",
+			ctx:      WithRetries(context.Background(), 4),
+			expected: 4,
+			retries:  4,
+		},
+		{
+			name: "WithCheck",
+			ctx: WithCheck(context.Background(), func(*http.Response, error) bool {
+				return false
+			}),
+			expected: 0,
+			retries:  2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			var (
+				rt     = httpmock.NewRoundTripperSuite(suite)
+				client = New(
+					Config{
+						Retries: 2,
+						Check:   alwaysRetry,
+						Timer:   noWait,
+					},
+					&http.Client{
+						Transport: rt,
+					},
+				)
+			)
+
+			for x := 0; x <= testCase.expected; x++ {
+				rt.OnAny().AssertRequest(suite.stateAsserter(x, testCase.retries)).
+					Return(new(http.Response), nil).Once()
+			}
+
+			request, err := http.NewRequestWithContext(testCase.ctx, "GET", "/test", nil)
+			suite.Require().NoError(err)
+
+			response, err := client.Do(request)
+			suite.NoError(err)
+			suite.NotNil(response)
+			suite.Equal(2, client.Retries())
+			rt.AssertExpectations()
+		})
+	}
+}
+
+func (suite *ClientTestSuite) TestIdempotentOnly() {
+	testCases := []struct {
+		name     string
+		method   string
+		key      string
+		ctx      context.Context
+		expected int
+	}{
+		{
+			name:     "GET",
+			method:   "GET",
+			ctx:      context.Background(),
+			expected: 2,
+		},
+		{
+			name:     "POST",
+			method:   "POST",
+			ctx:      context.Background(),
+			expected: 0,
+		},
+		{
+			name:     "POSTWithKey",
+			method:   "POST",
+			key:      "1234",
+			ctx:      context.Background(),
+			expected: 2,
+		},
+		{
+			name:     "POSTWithOverride",
+			method:   "POST",
+			ctx:      WithRetries(context.Background(), 1),
+			expected: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			var (
+				rt     = httpmock.NewRoundTripperSuite(suite)
+				client = New(
+					Config{
+						Retries:        2,
+						IdempotentOnly: true,
+						Check: func(*http.Response, error) bool {
+							return true
+						},
+						Timer: func(time.Duration) (<-chan time.Time, func() bool) {
+							tc := make(chan time.Time)
+							close(tc)
+							return tc, func() bool { return true }
+						},
+					},
+					&http.Client{
+						Transport: rt,
+					},
+				)
+			)
+
+			for x := 0; x <= testCase.expected; x++ {
+				rt.OnAny().AssertRequest(suite.stateAsserter(x, testCase.expected)).
+					Return(new(http.Response), nil).Once()
+			}
+
+			request, err := http.NewRequestWithContext(testCase.ctx, testCase.method, "/test", nil)
+			suite.Require().NoError(err)
+			if len(testCase.key) > 0 {
+				request.Header.Set(IdempotencyKeyHeader, testCase.key)
+			}
+
+			response, err := client.Do(request)
+			suite.NoError(err)
+			suite.NotNil(response)
+			rt.AssertExpectations()
+		})
+	}
+}
+
+func (suite *ClientTestSuite) TestBackoff() {
+	var (
+		waits  []time.Duration
+		rt     = httpmock.NewRoundTripperSuite(suite)
+		client = New(
+			Config{
+				Retries:   3,
+				Backoff:   BackoffList,
+				Intervals: []time.Duration{time.Second, 7 * time.Second},
+				Check: func(*http.Response, error) bool {
+					return true
+				},
+				Timer: func(d time.Duration) (<-chan time.Time, func() bool) {
+					waits = append(waits, d)
+					tc := make(chan time.Time)
+					close(tc)
+					return tc, func() bool { return true }
+				},
+			},
+			&http.Client{
+				Transport: rt,
+			},
+		)
+	)
+
+	rt.OnAny().Return(new(http.Response), nil).Times(4)
+	request, err := http.NewRequest("GET", "/test", nil)
+	suite.Require().NoError(err)
+
+	response, err := client.Do(request)
+	suite.NoError(err)
+	suite.NotNil(response)
+	suite.Equal([]time.Duration{time.Second, 7 * time.Second, 7 * time.Second}, waits)
+	rt.AssertExpectations()
+}
+
+func (suite *ClientTestSuite) TestPerAttemptTimeout() {
+	var (
+		attempts    []context.Context
+		retryClient = New(
+			Config{
+				Retries:           2,
+				PerAttemptTimeout: 50 * time.Millisecond,
+				Check: func(*http.Response, error) bool {
+					return false // only timeouts should be retried
+				},
+				Timer: func(time.Duration) (<-chan time.Time, func() bool) {
+					tc := make(chan time.Time)
+					close(tc)
+					return tc, func() bool { return true }
+				},
+			},
+			client.Func(func(r *http.Request) (*http.Response, error) {
+				attempts = append(attempts, r.Context())
+				if len(attempts) == 1 {
+					// the first attempt hangs until it times out
+					<-r.Context().Done()
+					return nil, r.Context().Err()
+				}
+
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       httpmock.EmptyBody(),
+				}, nil
+			}),
+		)
+	)
+
+	request, err := http.NewRequest("GET", "/test", nil)
+	suite.Require().NoError(err)
+
+	response, err := retryClient.Do(request)
+	suite.NoError(err)
+	suite.Require().NotNil(response)
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Require().Len(attempts, 2)
+	suite.ErrorIs(attempts[0].Err(), context.DeadlineExceeded)
+
+	// the final attempt's context should be released when the body is closed
+	suite.NoError(attempts[1].Err())
+	response.Body.Close()
+	suite.ErrorIs(attempts[1].Err(), context.Canceled)
+}
+
+func (suite *ClientTestSuite) TestMaxElapsedTime() {
+	suite.Run("Deadline", func() {
+		var (
+			attemptCtx  context.Context
+			retryClient = New(
+				Config{
+					MaxElapsedTime: time.Hour,
+				},
+				client.Func(func(r *http.Request) (*http.Response, error) {
+					attemptCtx = r.Context()
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       httpmock.EmptyBody(),
+					}, nil
+				}),
+			)
+		)
+
+		request, err := http.NewRequest("GET", "/test", nil)
+		suite.Require().NoError(err)
+
+		response, err := retryClient.Do(request)
+		suite.NoError(err)
+		suite.Require().NotNil(response)
+		suite.Require().NotNil(attemptCtx)
+
+		_, ok := attemptCtx.Deadline()
+		suite.True(ok)
+		suite.NoError(attemptCtx.Err())
+		response.Body.Close()
+		suite.Error(attemptCtx.Err())
+	})
+
+	suite.Run("SkipRetry", func() {
+		var (
+			rt     = httpmock.NewRoundTripperSuite(suite)
+			client = New(
+				Config{
+					Retries:        2,
+					Interval:       2 * time.Hour,
+					MaxElapsedTime: time.Hour,
+					Check: func(*http.Response, error) bool {
+						return true
+					},
+					Timer: suite.timerNeverCalled,
+				},
+				&http.Client{
+					Transport: rt,
+				},
+			)
+		)
+
+		expected := &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Body:       httpmock.BodyString("expected"),
+		}
+
+		rt.OnAny().Return(expected, nil).Once()
+		request, err := http.NewRequest("GET", "/test", nil)
+		suite.Require().NoError(err)
+
+		response, err := client.Do(request)
+		suite.NoError(err)
+		suite.Require().NotNil(response)
+		suite.Equal(http.StatusServiceUnavailable, response.StatusCode)
+
+		b, err := io.ReadAll(response.Body)
+		suite.NoError(err)
+		suite.Equal("expected", string(b))
+		rt.AssertExpectations()
+	})
+
+	suite.Run("CallerDeadline", func() {
+		var (
+			rt     = httpmock.NewRoundTripperSuite(suite)
+			client = New(
+				Config{
+					Retries:  2,
+					Interval: 2 * time.Hour,
+					Check: func(*http.Response, error) bool {
+						return true
+					},
+					Timer: suite.timerNeverCalled,
+				},
+				&http.Client{
+					Transport: rt,
+				},
+			)
+		)
+
+		rt.OnAny().Return(new(http.Response), nil).Once()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		request, err := http.NewRequestWithContext(ctx, "GET", "/test", nil)
+		suite.Require().NoError(err)
+
+		response, err := client.Do(request)
+		suite.NoError(err)
+		suite.NotNil(response)
+		rt.AssertExpectations()
+	})
+}
+
+// hookRecorder captures the lifecycle events from a Client
+type hookRecorder struct {
+	attempts []int
+	retries  []int
+	waits    []time.Duration
+	previous []int
+	gaveUp   []*State
+}
+
+func (hr *hookRecorder) configure(cfg Config) Config {
+	cfg.OnAttempt = func(s *State) {
+		hr.attempts = append(hr.attempts, s.Attempt())
+	}
+
+	cfg.OnRetry = func(attempt int, wait time.Duration, previous *http.Response, _ error) {
+		hr.retries = append(hr.retries, attempt)
+		hr.waits = append(hr.waits, wait)
+		if previous != nil {
+			hr.previous = append(hr.previous, previous.StatusCode)
+		}
+	}
+
+	cfg.OnGiveUp = func(s *State, _ *http.Response, _ error) {
+		hr.gaveUp = append(hr.gaveUp, s)
+	}
+
+	return cfg
+}
+
+func (suite *ClientTestSuite) TestHooks() {
+	newConfig := func() Config {
+		return Config{
+			Retries:  2,
+			Interval: 5 * time.Second,
+			Check: func(r *http.Response, err error) bool {
+				return err != nil || r.StatusCode != http.StatusOK
+			},
+			Timer: func(time.Duration) (<-chan time.Time, func() bool) {
+				tc := make(chan time.Time)
+				close(tc)
+				return tc, func() bool { return true }
+			},
+		}
+	}
+
+	suite.Run("Success", func() {
+		var (
+			hr     hookRecorder
+			rt     = httpmock.NewRoundTripperSuite(suite)
+			client = New(
+				hr.configure(newConfig()),
+				&http.Client{
+					Transport: rt,
+				},
+			)
+		)
+
+		rt.OnAny().Return(&http.Response{StatusCode: http.StatusServiceUnavailable, Body: httpmock.EmptyBody()}, nil).Once()
+		rt.OnAny().Return(&http.Response{StatusCode: http.StatusOK, Body: httpmock.EmptyBody()}, nil).Once()
+
+		ctx, finalState := TrackState(context.Background())
+		request, err := http.NewRequestWithContext(ctx, "GET", "/test", nil)
+		suite.Require().NoError(err)
+
+		response, err := client.Do(request)
+		suite.NoError(err)
+		suite.Require().NotNil(response)
+		suite.Equal(http.StatusOK, response.StatusCode)
+		rt.AssertExpectations()
+
+		suite.Equal([]int{0, 1}, hr.attempts)
+		suite.Equal([]int{1}, hr.retries)
+		suite.Equal([]time.Duration{5 * time.Second}, hr.waits)
+		suite.Equal([]int{http.StatusServiceUnavailable}, hr.previous)
+		suite.Empty(hr.gaveUp)
+
+		s := finalState()
+		suite.Require().NotNil(s)
+		suite.Equal(2, s.Attempts())
+		suite.Equal(5*time.Second, s.Waited())
+	})
+
+	suite.Run("GiveUp", func() {
+		var (
+			hr     hookRecorder
+			rt     = httpmock.NewRoundTripperSuite(suite)
+			client = New(
+				hr.configure(newConfig()),
+				&http.Client{
+					Transport: rt,
+				},
+			)
+		)
+
+		rt.OnAny().Return(&http.Response{StatusCode: http.StatusServiceUnavailable, Body: httpmock.EmptyBody()}, nil).Times(3)
+
+		ctx, finalState := TrackState(context.Background())
+		request, err := http.NewRequestWithContext(ctx, "GET", "/test", nil)
+		suite.Require().NoError(err)
+
+		response, err := client.Do(request)
+		suite.NoError(err)
+		suite.Require().NotNil(response)
+		suite.Equal(http.StatusServiceUnavailable, response.StatusCode)
+		rt.AssertExpectations()
+
+		suite.Equal([]int{0, 1, 2}, hr.attempts)
+		suite.Equal([]int{1, 2}, hr.retries)
+		suite.Equal([]time.Duration{5 * time.Second, 5 * time.Second}, hr.waits)
+		suite.Require().Len(hr.gaveUp, 1)
+		suite.True(finalState() == hr.gaveUp[0])
+		suite.Equal(3, finalState().Attempts())
+		suite.Equal(10*time.Second, finalState().Waited())
+	})
+
+	suite.Run("NoRetries", func() {
+		var (
+			hr     hookRecorder
+			rt     = httpmock.NewRoundTripperSuite(suite)
+			cfg    = newConfig()
+			client *Client
+		)
+
+		cfg.Retries = 0
+		cfg.Timer = suite.timerNeverCalled
+		client = New(
+			hr.configure(cfg),
+			&http.Client{
+				Transport: rt,
+			},
+		)
+
+		rt.OnAny().Return(&http.Response{StatusCode: http.StatusServiceUnavailable, Body: httpmock.EmptyBody()}, nil).Once()
+
+		request, err := http.NewRequest("GET", "/test", nil)
+		suite.Require().NoError(err)
+
+		response, err := client.Do(request)
+		suite.NoError(err)
+		suite.NotNil(response)
+		rt.AssertExpectations()
+
+		suite.Equal([]int{0}, hr.attempts)
+		suite.Empty(hr.retries)
+		suite.Empty(hr.gaveUp)
+	})
+}
+
+func (suite *ClientTestSuite) TestBufferBody() {
+	suite.Run("Replayed", func() {
+		var (
+			bodies      []string
+			retryClient = New(
+				Config{
+					Retries:         2,
+					MaxBufferedBody: 100,
+					Check: func(r *http.Response, _ error) bool {
+						return r.StatusCode != http.StatusOK
+					},
+					Timer: func(time.Duration) (<-chan time.Time, func() bool) {
+						tc := make(chan time.Time)
+						close(tc)
+						return tc, func() bool { return true }
+					},
+				},
+				client.Func(func(r *http.Request) (*http.Response, error) {
+					b, err := io.ReadAll(r.Body)
+					suite.NoError(err)
+					bodies = append(bodies, string(b))
+
+					status := http.StatusServiceUnavailable
+					if len(bodies) == 3 {
+						status = http.StatusOK
+					}
+
+					return &http.Response{
+						StatusCode: status,
+						Body:       httpmock.EmptyBody(),
+					}, nil
+				}),
+			)
+		)
+
+		body := httpmock.BodyString("payload")
+		request, err := http.NewRequest("POST", "/test", body)
+		suite.Require().NoError(err)
+		suite.Require().Nil(request.GetBody)
+
+		response, err := retryClient.Do(request)
+		suite.NoError(err)
+		suite.Require().NotNil(response)
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal([]string{"payload", "payload", "payload"}, bodies)
+		suite.True(body.Closed())
+		suite.Nil(request.GetBody)
+	})
+
+	suite.Run("TooLarge", func() {
+		var (
+			rt     = httpmock.NewRoundTripperSuite(suite)
+			client = New(
+				Config{
+					Retries:         2,
+					MaxBufferedBody: 4,
+					Timer:           suite.timerNeverCalled,
+					Check:           suite.checkNeverCalled,
+				},
+				&http.Client{
+					Transport: rt,
+				},
+			)
+		)
+
+		request, err := http.NewRequest("POST", "/test", httpmock.BodyString("payload"))
+		suite.Require().NoError(err)
+
+		response, err := client.Do(request)
+		suite.Nil(response)
+
+		var tooLarge *BodyTooLargeError
+		suite.ErrorAs(err, &tooLarge)
+		rt.AssertExpectations()
+	})
+}
+
+func (suite *ClientTestSuite) TestCheckConfig() {
+	newClient := func(cfg Config, rt http.RoundTripper) *Client {
+		cfg.Retries = 1
+		cfg.Timer = func(time.Duration) (<-chan time.Time, func() bool) {
+			tc := make(chan time.Time)
+			close(tc)
+			return tc, func() bool { return true }
+		}
+
+		return New(cfg, &http.Client{Transport: rt})
+	}
+
+	unavailable := func() *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Body:       httpmock.EmptyBody(),
+		}
+	}
+
+	suite.Run("Statuses", func() {
+		rt := httpmock.NewRoundTripperSuite(suite)
+		client := newClient(
+			Config{
+				CheckConfig: &CheckConfig{
+					Statuses: []StatusRange{{Min: 500, Max: 599}},
+				},
+			},
+			rt,
+		)
+
+		rt.OnAny().Return(unavailable(), nil).Times(2)
+		request, err := http.NewRequest("GET", "/test", nil)
+		suite.Require().NoError(err)
+
+		response, err := client.Do(request)
+		suite.NoError(err)
+		suite.NotNil(response)
+		rt.AssertExpectations()
+	})
+
+	suite.Run("Combined", func() {
+		rt := httpmock.NewRoundTripperSuite(suite)
+		client := newClient(
+			Config{
+				Check: func(r *http.Response, _ error) bool {
+					return r.StatusCode == http.StatusServiceUnavailable
+				},
+				CheckConfig: &CheckConfig{
+					Statuses: []StatusRange{{Min: 429, Max: 429}},
+				},
+			},
+			rt,
+		)
+
+		rt.OnAny().Return(unavailable(), nil).Once()
+		rt.OnAny().Return(&http.Response{StatusCode: http.StatusTooManyRequests, Body: httpmock.EmptyBody()}, nil).Once()
+		request, err := http.NewRequest("GET", "/test", nil)
+		suite.Require().NoError(err)
+
+		response, err := client.Do(request)
+		suite.NoError(err)
+		suite.NotNil(response)
+		rt.AssertExpectations()
+	})
+
+	suite.Run("Methods", func() {
+		rt := httpmock.NewRoundTripperSuite(suite)
+		client := newClient(
+			Config{
+				CheckConfig: &CheckConfig{
+					Statuses: []StatusRange{{Min: 500, Max: 599}},
+					Methods:  []string{"GET"},
+				},
+			},
+			rt,
+		)
+
+		rt.OnAny().Return(unavailable(), nil).Once()
+		request, err := http.NewRequest("POST", "/test", nil)
+		suite.Require().NoError(err)
+
+		response, err := client.Do(request)
+		suite.NoError(err)
+		suite.NotNil(response)
+		rt.AssertExpectations()
+
+		// an explicit override still wins
+		rt.OnAny().Return(unavailable(), nil).Times(2)
+		response, err = client.Do(request.WithContext(WithRetries(request.Context(), 1)))
+		suite.NoError(err)
+		suite.NotNil(response)
+		rt.AssertExpectations()
+	})
+}
+
+func (suite *ClientTestSuite) TestFailover() {
+	var (
+		hosts       []string
+		retryClient = New(
+			Config{
+				Retries:       3,
+				FailoverHosts: []string{"backup1", "backup2"},
+				Check: func(r *http.Response, _ error) bool {
+					return r.StatusCode != http.StatusOK
+				},
+				Timer: func(time.Duration) (<-chan time.Time, func() bool) {
+					tc := make(chan time.Time)
+					close(tc)
+					return tc, func() bool { return true }
+				},
+			},
+			client.Func(func(r *http.Request) (*http.Response, error) {
+				hosts = append(hosts, r.URL.Host)
+				suite.Equal(r.URL.Host, r.Host)
+				suite.Equal(GetState(r.Context()).Hosts(), hosts)
+
+				status := http.StatusServiceUnavailable
+				if r.URL.Host == "backup2" {
+					status = http.StatusOK
+				}
+
+				return &http.Response{
+					StatusCode: status,
+					Body:       httpmock.EmptyBody(),
+				}, nil
+			}),
+		)
+	)
+
+	ctx, finalState := TrackState(context.Background())
+	request, err := http.NewRequestWithContext(ctx, "GET", "http://primary/test", nil)
+	suite.Require().NoError(err)
+
+	response, err := retryClient.Do(request)
+	suite.NoError(err)
+	suite.Require().NotNil(response)
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Equal([]string{"primary", "backup1", "backup2"}, hosts)
+	suite.Equal(hosts, finalState().Hosts())
+	suite.Equal("primary", request.URL.Host)
+}
+
+func (suite *ClientTestSuite) TestClock() {
+	var (
+		fake   = clock.NewFake(time.Date(2021, time.March, 4, 10, 30, 0, 0, time.UTC))
+		rt     = httpmock.NewRoundTripperSuite(suite)
+		client = New(
+			Config{
+				Retries:        2,
+				Interval:       time.Second,
+				Multiplier:     2.0,
+				MaxElapsedTime: time.Minute,
+				Clock:          fake,
+				Check: func(r *http.Response, _ error) bool {
+					return r.StatusCode != http.StatusOK
+				},
+			},
+			&http.Client{
+				Transport: rt,
+			},
+		)
+	)
+
+	rt.OnAny().Return(&http.Response{StatusCode: http.StatusServiceUnavailable, Body: httpmock.EmptyBody()}, nil).Twice()
+	rt.OnAny().Return(&http.Response{StatusCode: http.StatusOK, Body: httpmock.EmptyBody()}, nil).Once()
+
+	ctx, finalState := TrackState(context.Background())
+	request, err := http.NewRequestWithContext(ctx, "GET", "/test", nil)
+	suite.Require().NoError(err)
+
+	result := make(chan *http.Response, 1)
+	go func() {
+		response, err := client.Do(request)
+		suite.NoError(err)
+		result <- response
+	}()
+
+	// the first retry waits for the interval
+	fake.BlockUntil(1)
+	fake.Add(999 * time.Millisecond)
+	suite.Equal(1, fake.Timers())
+	fake.Add(time.Millisecond)
+
+	// the second retry waits twice as long
+	fake.BlockUntil(1)
+	fake.Add(2 * time.Second)
+
+	select {
+	case response := <-result:
+		suite.Require().NotNil(response)
+		suite.Equal(http.StatusOK, response.StatusCode)
+		suite.Equal(3*time.Second, finalState().Waited())
+		rt.AssertExpectations()
+
+	case <-time.After(5 * time.Second):
+		suite.Fail("Do did not return")
+	}
+}
+
+func (suite *ClientTestSuite) TestClockMaxElapsedTime() {
+	testCases := []struct {
+		name             string
+		now              time.Time
+		interval         time.Duration
+		expectedAttempts int
+	}{
+		{
+			name:             "PastExceedsDeadline",
+			now:              time.Date(2021, time.March, 4, 10, 30, 0, 0, time.UTC),
+			interval:         time.Hour,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "FutureExceedsDeadline",
+			now:              time.Now().AddDate(100, 0, 0),
+			interval:         time.Hour,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "PastWithinDeadline",
+			now:              time.Date(2021, time.March, 4, 10, 30, 0, 0, time.UTC),
+			interval:         time.Second,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "FutureWithinDeadline",
+			now:              time.Now().AddDate(100, 0, 0),
+			interval:         time.Second,
+			expectedAttempts: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			var (
+				fake   = clock.NewFake(testCase.now)
+				rt     = httpmock.NewRoundTripperSuite(suite)
+				client = New(
+					Config{
+						Retries:        1,
+						Interval:       testCase.interval,
+						MaxElapsedTime: time.Minute,
+						Clock:          fake,
+						Check: func(r *http.Response, _ error) bool {
+							return r.StatusCode != http.StatusOK
+						},
+					},
+					&http.Client{
+						Transport: rt,
+					},
+				)
+			)
+
+			rt.OnAny().Return(&http.Response{StatusCode: http.StatusServiceUnavailable, Body: httpmock.EmptyBody()}, nil).Once()
+			if testCase.expectedAttempts > 1 {
+				rt.OnAny().Return(&http.Response{StatusCode: http.StatusOK, Body: httpmock.EmptyBody()}, nil).Once()
+			}
+
+			ctx, finalState := TrackState(context.Background())
+			request, err := http.NewRequestWithContext(ctx, "GET", "/test", nil)
+			suite.Require().NoError(err)
+
+			result := make(chan *http.Response, 1)
+			go func() {
+				response, err := client.Do(request)
+				suite.NoError(err)
+				result <- response
+			}()
+
+			if testCase.expectedAttempts > 1 {
+				fake.BlockUntil(1)
+				fake.Add(testCase.interval)
+			}
+
+			select {
+			case response := <-result:
+				suite.Require().NotNil(response)
+				response.Body.Close()
+				suite.Equal(testCase.expectedAttempts, finalState().Attempts())
+				rt.AssertExpectations()
+
+			case <-time.After(5 * time.Second):
+				suite.Fail("Do did not return")
+			}
+		})
+	}
+}
+
 func TestClient(t *testing.T) {
 	suite.Run(t, new(ClientTestSuite))
 }
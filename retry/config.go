@@ -5,6 +5,8 @@ package retry
 
 import (
 	"time"
+
+	"github.com/xmidt-org/httpaux/clock"
 )
 
 const (
@@ -52,17 +54,132 @@ type Config struct {
 	// If this field is not in the open range (0.0, 1.0), no jitter is used.
 	Jitter float64 `json:"jitter" yaml:"jitter"`
 
+	// Backoff selects one of the built-in strategies for computing the time to wait before
+	// each retry.  If unset, BackoffExponential is used, which is the strategy described by
+	// Interval, Multiplier, and Jitter.  See the BackoffType constants for the other strategies.
+	Backoff BackoffType `json:"backoff" yaml:"backoff"`
+
+	// Intervals is the explicit list of waits used by BackoffList.  This field is ignored
+	// for all other strategies.
+	Intervals []time.Duration `json:"intervals" yaml:"intervals"`
+
+	// MaxInterval is the maximum time to wait before any retry, regardless of the Backoff
+	// strategy.  If nonpositive, there is no maximum.
+	MaxInterval time.Duration `json:"maxInterval" yaml:"maxInterval"`
+
+	// CustomBackoff is an optional, custom strategy for computing retry waits.  If set,
+	// Backoff, Interval, Multiplier, Jitter, Intervals, and MaxInterval are ignored.
+	CustomBackoff Backoff `json:"-" yaml:"-"`
+
+	// RetryAfter controls how a Retry-After header in a 429 or 503 response affects
+	// the wait before the next retry.  By default, Retry-After headers are ignored.
+	//
+	// When set to RetryAfterOverride, a Retry-After value replaces the computed interval.
+	// When set to RetryAfterFloor, a Retry-After value is the minimum amount of time to wait.
+	RetryAfter RetryAfterMode `json:"retryAfter" yaml:"retryAfter"`
+
+	// MaxRetryAfter is the cap on any wait obtained from a Retry-After header.  This prevents
+	// a server from stalling a client indefinitely.  If nonpositive, DefaultMaxRetryAfter is used.
+	//
+	// This field has no effect if RetryAfter is unset.
+	MaxRetryAfter time.Duration `json:"maxRetryAfter" yaml:"maxRetryAfter"`
+
+	// MaxElapsedTime is the maximum total time allowed for the initial attempt and all retries,
+	// including the time spent waiting between attempts.  If nonpositive, the only limit
+	// is any deadline on the request's context.
+	//
+	// Whatever the source of the deadline, a retry whose wait would pass that deadline
+	// is not attempted.  Instead, the most recent result is returned.
+	MaxElapsedTime time.Duration `json:"maxElapsedTime" yaml:"maxElapsedTime"`
+
+	// PerAttemptTimeout is the maximum time allowed for each individual attempt.  If nonpositive,
+	// attempts are only limited by the request's context and MaxElapsedTime.
+	//
+	// An attempt that exceeds this timeout is always considered retryable, regardless of Check.
+	PerAttemptTimeout time.Duration `json:"perAttemptTimeout" yaml:"perAttemptTimeout"`
+
+	// IdempotentOnly restricts retries to requests that can be safely repeated.  When true,
+	// a request is only retried if its method is idempotent or it carries an Idempotency-Key
+	// header.  See IsIdempotent.
+	//
+	// An explicit per-request override via WithRetries takes precedence over this field.
+	IdempotentOnly bool `json:"idempotentOnly" yaml:"idempotentOnly"`
+
+	// FailoverHosts are alternate hosts used for retries.  The initial attempt is always sent
+	// to the request's own URL.  Each retry is sent to the next host in this list, cycling back
+	// to the first host as needed.  Hosts are in the same form as url.URL.Host, e.g. "host:port".
+	//
+	// The hosts used for each attempt are available via State.Hosts.  If unset, all retries
+	// are sent to the request's own URL.
+	FailoverHosts []string `json:"failoverHosts" yaml:"failoverHosts"`
+
+	// MaxBufferedBody enables automatic buffering of request bodies.  If positive, a request that
+	// has a body but no GetBody has its body read up front, up to this many bytes, so that each
+	// attempt sends the complete body.  A body larger than this limit fails immediately with
+	// a *BodyTooLargeError.
+	//
+	// If nonpositive, no buffering is done.  In that case, callers are responsible for setting
+	// GetBody on requests with bodies.  http.NewRequest does this for common body types.
+	MaxBufferedBody int64 `json:"maxBufferedBody" yaml:"maxBufferedBody"`
+
+	// MemoryBufferSize is the largest buffered body that is held in memory.  Larger bodies,
+	// up to MaxBufferedBody, are written to a temp file that is removed when the response
+	// body is closed.  If nonpositive, DefaultMemoryBufferSize is used.
+	MemoryBufferSize int64 `json:"memoryBufferSize" yaml:"memoryBufferSize"`
+
+	// TempDir is the directory used for buffered bodies that exceed MemoryBufferSize.
+	// If unset, os.TempDir is used.
+	TempDir string `json:"tempDir" yaml:"tempDir"`
+
+	// Budget is the optional retry budget that limits the overall ratio of retries to
+	// requests.  A Budget is typically shared among several Clients.  If unset, retries
+	// are limited only by Retries and Check.
+	//
+	// When a retry is skipped because the budget is exhausted, the last response and error
+	// are returned and State.BudgetExhausted returns true.
+	Budget *Budget `json:"-" yaml:"-"`
+
+	// OnAttempt is an optional callback invoked before each attempt, including the initial attempt.
+	OnAttempt OnAttempt `json:"-" yaml:"-"`
+
+	// OnRetry is an optional callback invoked each time a retry is scheduled.
+	OnRetry OnRetry `json:"-" yaml:"-"`
+
+	// OnGiveUp is an optional callback invoked when a Client stops retrying a result that
+	// was still retryable.
+	OnGiveUp OnGiveUp `json:"-" yaml:"-"`
+
+	// Clock is the optional source of time for this Client.  It is used to interpret
+	// Retry-After dates and, if Timer is unset, to wait between retries.  If unset,
+	// clock.System is used.
+	//
+	// Deadlines, whether from MaxElapsedTime, PerAttemptTimeout, or the request's context,
+	// are always measured against the wall clock, since that is how a context.Context
+	// enforces them.
+	Clock clock.Clock `json:"-" yaml:"-"`
+
 	// Random is the optional source of randomness used in computing jitter.
 	// If this value is omitted, math/rand.New is used to compute a source
 	// of randomness with the current time as the seed.
 	Random Random `json:"-" yaml:"-"`
 
 	// Timer is the timer strategy used to creating channels for awaiting
-	// until the next retry interval.  If unset, DefaultTimer is used.
+	// until the next retry interval.  If unset, Clock is used to create timers.
+	// If both are unset, DefaultTimer is used.
 	Timer Timer `json:"-" yaml:"-"`
 
 	// Check is the predicate used to determine if the result of http.Client.Do
 	// can be retried.  Even if this predicate returns true, the number of Retries
 	// will not be exceeded.
+	//
+	// If both Check and CheckConfig are unset, DefaultCheck is used.
 	Check Check `json:"-" yaml:"-"`
+
+	// CheckConfig is the declarative alternative to Check.  If both are set, a result
+	// is retried if either Check or the compiled CheckConfig allow it.  For more control
+	// over how checks are combined, use CheckConfig.NewCheck with CheckAny or CheckAll.
+	//
+	// Any Methods in this configuration restrict which requests are retried.  As with
+	// IdempotentOnly, an explicit per-request override via WithRetries takes precedence.
+	CheckConfig *CheckConfig `json:"check" yaml:"check"`
 }
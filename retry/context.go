@@ -6,6 +6,7 @@ package retry
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/xmidt-org/httpaux"
 )
@@ -21,6 +22,12 @@ type State struct {
 
 	previous    *http.Response
 	previousErr error
+
+	budgetExhausted bool
+	gaveUp          bool
+	waited          time.Duration
+
+	hosts []string
 }
 
 // Attempt is the 0-based attempt to execute this HTTP transaction.
@@ -48,6 +55,34 @@ func (s *State) Previous() (*http.Response, error) {
 	return s.previous, s.previousErr
 }
 
+// Attempts is the total number of attempts made so far, including the initial attempt.
+// Once a Client is finished with a request, this is the total number of HTTP transactions
+// that were executed.
+func (s *State) Attempts() int {
+	return s.attempt + 1
+}
+
+// Waited is the total amount of time spent waiting between attempts.
+func (s *State) Waited() time.Duration {
+	return s.waited
+}
+
+// Hosts returns the hosts that each attempt was sent to, in order.  The last element
+// is the host for the current attempt.  After a Client is finished with a request, the
+// last element is the host that produced the final result.
+//
+// The returned slice must not be modified.
+func (s *State) Hosts() []string {
+	return s.hosts
+}
+
+// BudgetExhausted indicates whether a retry was skipped because the
+// Config.Budget did not allow it.  When this method returns true, no further
+// attempts will be made.
+func (s *State) BudgetExhausted() bool {
+	return s.budgetExhausted
+}
+
 // prepareNext preps for the next attempt in a series.
 // the given response's body is drained, closed, and nil'd out.
 func (s *State) prepareNext(previous *http.Response, previousErr error) {
@@ -78,3 +113,86 @@ func GetState(ctx context.Context) *State {
 func withState(ctx context.Context, s *State) context.Context {
 	return context.WithValue(ctx, contextKey{}, s)
 }
+
+// trackerKey is the internal context.Context key that stores a *stateTracker
+type trackerKey struct{}
+
+// stateTracker holds the State of the outermost Client that executed a request
+type stateTracker struct {
+	state *State
+}
+
+// TrackState returns a subcontext that allows callers to obtain the final State for
+// a request after Client.Do returns.  The returned function will return nil until a
+// Client has executed a request with the returned context.
+//
+// If multiple Clients are nested, the State of the outermost Client is tracked.  Unlike
+// GetState, the State returned by the function may be retained, since the Client is finished
+// with it once Do returns.
+//
+//	ctx, finalState := TrackState(context.Background())
+//	request, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+//	response, err := client.Do(request)
+//	fmt.Println("attempts", finalState().Attempts(), "waited", finalState().Waited())
+func TrackState(ctx context.Context) (context.Context, func() *State) {
+	st := new(stateTracker)
+	return context.WithValue(ctx, trackerKey{}, st),
+		func() *State {
+			return st.state
+		}
+}
+
+// track records the given state in any tracker in the context.  Only the
+// outermost Client, i.e. the first one with no State already in the context,
+// is tracked.
+func track(ctx context.Context, s *State) {
+	if st, ok := ctx.Value(trackerKey{}).(*stateTracker); ok && GetState(ctx) == nil {
+		st.state = s
+	}
+}
+
+// overridesKey is the internal context.Context key that stores per-request overrides
+type overridesKey struct{}
+
+// overrides holds the per-request retry settings that replace those in a Client's Config
+type overrides struct {
+	// hasRetries indicates whether retries was explicitly set
+	hasRetries bool
+	retries    int
+
+	check Check
+}
+
+// getOverrides returns the per-request overrides in a context.  If there
+// are no overrides, the zero value is returned.
+func getOverrides(ctx context.Context) overrides {
+	o, _ := ctx.Value(overridesKey{}).(overrides)
+	return o
+}
+
+// WithRetries returns a subcontext that overrides the number of retries for any request
+// that uses it.  A nonpositive value disables retries for the request.  The interval
+// semantics of the Client, e.g. Interval, Multiplier, and Jitter, are still honored.
+//
+// This function and WithCheck may be combined.  Only the Client that executes the request
+// with this context is affected.
+func WithRetries(ctx context.Context, retries int) context.Context {
+	o := getOverrides(ctx)
+	o.hasRetries = true
+	o.retries = retries
+	return context.WithValue(ctx, overridesKey{}, o)
+}
+
+// WithoutRetries returns a subcontext that disables retries for any request that uses it.
+// This is useful for non-idempotent requests that use a Client shared with other requests.
+func WithoutRetries(ctx context.Context) context.Context {
+	return WithRetries(ctx, 0)
+}
+
+// WithCheck returns a subcontext that overrides the Check strategy for any request
+// that uses it.  If check is nil, the Client's Check is used.
+func WithCheck(ctx context.Context, check Check) context.Context {
+	o := getOverrides(ctx)
+	o.check = check
+	return context.WithValue(ctx, overridesKey{}, o)
+}
@@ -83,6 +83,80 @@ func (suite *StateTestSuite) TestGetState() {
 	})
 }
 
+func (suite *StateTestSuite) TestTrackState() {
+	suite.Run("Untracked", func() {
+		suite.NotPanics(func() {
+			track(context.Background(), new(State))
+		})
+	})
+
+	suite.Run("Outermost", func() {
+		ctx, finalState := TrackState(context.Background())
+		suite.Nil(finalState())
+
+		outer := new(State)
+		track(ctx, outer)
+		suite.True(outer == finalState())
+
+		// a nested client sees the outer state in its context
+		track(withState(ctx, outer), new(State))
+		suite.True(outer == finalState())
+	})
+}
+
 func TestState(t *testing.T) {
 	suite.Run(t, new(StateTestSuite))
 }
+
+type OverridesTestSuite struct {
+	suite.Suite
+}
+
+func (suite *OverridesTestSuite) TestMissing() {
+	o := getOverrides(context.Background())
+	suite.False(o.hasRetries)
+	suite.Nil(o.check)
+}
+
+func (suite *OverridesTestSuite) TestWithRetries() {
+	o := getOverrides(WithRetries(context.Background(), 7))
+	suite.True(o.hasRetries)
+	suite.Equal(7, o.retries)
+	suite.Nil(o.check)
+}
+
+func (suite *OverridesTestSuite) TestWithoutRetries() {
+	o := getOverrides(WithoutRetries(context.Background()))
+	suite.True(o.hasRetries)
+	suite.Zero(o.retries)
+	suite.Nil(o.check)
+}
+
+func (suite *OverridesTestSuite) TestWithCheck() {
+	called := false
+	o := getOverrides(WithCheck(context.Background(), func(*http.Response, error) bool {
+		called = true
+		return true
+	}))
+
+	suite.False(o.hasRetries)
+	suite.Require().NotNil(o.check)
+	suite.True(o.check(nil, nil))
+	suite.True(called)
+}
+
+func (suite *OverridesTestSuite) TestCombined() {
+	ctx := WithCheck(
+		WithRetries(context.Background(), 3),
+		func(*http.Response, error) bool { return false },
+	)
+
+	o := getOverrides(ctx)
+	suite.True(o.hasRetries)
+	suite.Equal(3, o.retries)
+	suite.NotNil(o.check)
+}
+
+func TestOverrides(t *testing.T) {
+	suite.Run(t, new(OverridesTestSuite))
+}
@@ -16,6 +16,18 @@ A Client can also be used as client middleware via its Then method.
 	client := New(Config{})
 	decorated := client.Then(new(http.Client))
 
+Retry semantics are also available as http.RoundTripper middleware, for use within
+a transport chain or with code that only accepts an http.RoundTripper:
+
+	transport := NewRoundTripper(Config{
+	  Retries: 2,
+	  Interval: 10 * time.Second,
+	}, new(http.Transport))
+
+	chain := roundtrip.NewChain(
+	  NewConstructor(Config{Retries: 2}),
+	)
+
 Exponential backoff with jitter is also supported.  For example:
 
 	client := New(Config{
@@ -31,6 +43,110 @@ The basic formula for each time to wait before the next retry, in terms of Confi
 
 where n is the 0-based retry.
 
+Other backoff strategies can be selected with Config.Backoff, and Config.MaxInterval caps
+the wait before any retry regardless of strategy:
+
+	client := New(Config{
+	  Retries: 5,
+	  Interval: 1 * time.Second,
+	  Multiplier: 2.0,
+	  Backoff: BackoffFullJitter,
+	  MaxInterval: 30 * time.Second,
+	})
+
+Custom strategies can be supplied by implementing Backoff and setting Config.CustomBackoff.
+
+Servers that respond with 429 or 503 may indicate when to try again via a Retry-After
+header.  A Client can honor this header, either replacing the computed wait or using it
+as a minimum, up to a configurable cap:
+
+	client := New(Config{
+	  Retries: 2,
+	  Interval: 10 * time.Second,
+	  RetryAfter: RetryAfterFloor,
+	  MaxRetryAfter: 2 * time.Minute,
+	})
+
+The number of retries and the Check can be overridden for individual requests via
+the request's context.  This allows a single Client to be used for requests with
+different retry semantics:
+
+	// a non-idempotent request that shouldn't be retried
+	request, _ := http.NewRequestWithContext(
+	  WithoutRetries(ctx),
+	  "POST", "/orders", body,
+	)
+
+	response, err := client.Do(request)
+
+Retrying a request that is not idempotent, such as a POST, can cause duplicate side effects.
+Setting Config.IdempotentOnly restricts retries to idempotent methods and to requests that
+carry an Idempotency-Key header.  The IdempotencyKey middleware can be used to generate
+that header once, so that it is stable across all attempts.
+
+The total time spent on a request, including waits, can be limited with Config.MaxElapsedTime.
+Each individual attempt can be limited with Config.PerAttemptTimeout:
+
+	client := New(Config{
+	  Retries: 3,
+	  Interval: 1 * time.Second,
+	  MaxElapsedTime: 10 * time.Second,
+	  PerAttemptTimeout: 2 * time.Second,
+	})
+
+To prevent retry storms during an outage, a Budget can be shared among Clients.  Retries
+are only allowed while they stay under a ratio of recent requests:
+
+	budget := NewBudget(BudgetConfig{
+	  Ratio: 0.1,
+	  MinPerSecond: 5,
+	})
+
+	client1 := New(Config{Retries: 2, Budget: budget})
+	client2 := New(Config{Retries: 5, Budget: budget})
+
+For idempotent requests against replicated backends, NewHedge creates client middleware
+that sends additional copies of a request when the original hasn't answered within a delay.
+The first good response, as judged by a Check, wins:
+
+	hedged := client.NewChain(
+	  NewHedge(HedgeConfig{
+	    MaxTotalHedges: 1,
+	    Delay: 50 * time.Millisecond,
+	  }),
+	).Then(new(http.Client))
+
+The retry policy can also come from configuration.  Config.CheckConfig unmarshals from JSON or YAML
+and is compiled into a Check:
+
+	{
+	  "retries": 3,
+	  "check": {
+	    "statuses": ["429", "502-504"],
+	    "connectionReset": true,
+	    "timeout": true,
+	    "methods": ["GET", "PUT"]
+	  }
+	}
+
+CheckAny and CheckAll combine a compiled CheckConfig with custom Check functions.
+
+A request body can only be resent if the request has GetBody set.  http.NewRequest does this
+for common body types.  For other bodies, setting Config.MaxBufferedBody buffers the body before
+the initial attempt, spilling to a temp file when it exceeds Config.MemoryBufferSize.  Bodies larger
+than the limit fail with a *BodyTooLargeError before any attempt is made.
+
+When a primary endpoint is unhealthy, Config.FailoverHosts sends each retry to the next host
+in a list.  State.Hosts reports which host each attempt went to, so the last element is the
+host that answered.
+
+Retries can be observed through the OnAttempt, OnRetry, and OnGiveUp callbacks in Config.
+To inspect the final State after Do returns, use TrackState:
+
+	ctx, finalState := TrackState(request.Context())
+	response, err := client.Do(request.WithContext(ctx))
+	log.Println("attempts", finalState().Attempts(), "waited", finalState().Waited())
+
 See the documentation for the Config type for more details.
 
 Deprecated:  This functionality is moving to github.com/xmidt-org/retry
@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"net/http"
+)
+
+// failover is the list of alternate hosts used for retries.  an empty
+// failover leaves requests unchanged.
+type failover []string
+
+func newFailover(cfg Config) failover {
+	return append(failover(nil), cfg.FailoverHosts...)
+}
+
+// apply rewrites the given request to the failover host for the given
+// 1-based retry.  The request must be a shallow copy, as its URL is replaced
+// rather than modified.
+func (f failover) apply(request *http.Request, retry int) {
+	if len(f) == 0 || request.URL == nil {
+		return
+	}
+
+	host := f[(retry-1)%len(f)]
+	if len(request.Host) == 0 || request.Host == request.URL.Host {
+		// only rewrite the Host header if the caller didn't customize it
+		request.Host = host
+	}
+
+	u := *request.URL
+	u.Host = host
+	request.URL = &u
+}
+
+// requestHost returns the host a request will be sent to.
+func requestHost(request *http.Request) string {
+	if request.URL != nil {
+		return request.URL.Host
+	}
+
+	return ""
+}
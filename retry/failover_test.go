@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FailoverTestSuite struct {
+	suite.Suite
+}
+
+func (suite *FailoverTestSuite) newRequest(url string) *http.Request {
+	r, err := http.NewRequest("GET", url, nil)
+	suite.Require().NoError(err)
+	return r
+}
+
+func (suite *FailoverTestSuite) TestEmpty() {
+	var (
+		f        = newFailover(Config{})
+		original = suite.newRequest("http://primary/test")
+		request  = original.WithContext(original.Context())
+	)
+
+	f.apply(request, 1)
+	suite.Equal("primary", request.URL.Host)
+	suite.Equal("primary", request.Host)
+}
+
+func (suite *FailoverTestSuite) TestApply() {
+	var (
+		f        = newFailover(Config{FailoverHosts: []string{"backup1:8080", "backup2"}})
+		original = suite.newRequest("http://primary/test?a=1")
+	)
+
+	for retry, expected := range map[int]string{1: "backup1:8080", 2: "backup2", 3: "backup1:8080"} {
+		request := original.WithContext(original.Context())
+		f.apply(request, retry)
+		suite.Equal(expected, request.URL.Host)
+		suite.Equal(expected, request.Host)
+		suite.Equal("/test", request.URL.Path)
+		suite.Equal("a=1", request.URL.RawQuery)
+	}
+
+	// the original must never be modified
+	suite.Equal("primary", original.URL.Host)
+	suite.Equal("primary", original.Host)
+}
+
+func (suite *FailoverTestSuite) TestCustomHost() {
+	var (
+		f        = newFailover(Config{FailoverHosts: []string{"backup"}})
+		original = suite.newRequest("http://primary/test")
+	)
+
+	original.Host = "virtual.example.com"
+	request := original.WithContext(original.Context())
+	f.apply(request, 1)
+	suite.Equal("backup", request.URL.Host)
+	suite.Equal("virtual.example.com", request.Host)
+}
+
+func (suite *FailoverTestSuite) TestRequestHost() {
+	suite.Equal("primary", requestHost(suite.newRequest("http://primary/test")))
+	suite.Empty(requestHost(new(http.Request)))
+}
+
+func TestFailover(t *testing.T) {
+	suite.Run(t, new(FailoverTestSuite))
+}
@@ -0,0 +1,250 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/xmidt-org/httpaux"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/clock"
+)
+
+const (
+	// DefaultHedgeDelay is used when HedgeConfig.Delay is nonpositive.
+	DefaultHedgeDelay time.Duration = 100 * time.Millisecond
+)
+
+// HedgeConfig is the set of configuration options for hedged requests.  Hedging sends
+// additional copies of a request when the original has not answered within a delay.
+// The first good response wins, and all other copies are canceled.
+//
+// Hedging is only appropriate for idempotent requests against replicated backends.
+// Requests that are not idempotent, as defined by IsIdempotent, are never hedged.
+type HedgeConfig struct {
+	// MaxTotalHedges is the maximum number of additional copies of a request that will
+	// be sent over the lifetime of that request, not including the original.  Copies that
+	// have already completed still count against this limit, which bounds the extra load
+	// a single request can place on a backend.  If this value is unset or nonpositive,
+	// no hedging is performed.
+	MaxTotalHedges int `json:"maxTotalHedges" yaml:"maxTotalHedges"`
+
+	// Delay is the amount of time to wait for an outstanding request before sending
+	// a hedge.  Typically, this is set to some high percentile of observed latency, such
+	// as the p95.  If nonpositive, DefaultHedgeDelay is used.
+	Delay time.Duration `json:"delay" yaml:"delay"`
+
+	// Timer is the timer strategy used to create channels for awaiting the Delay.
+	// If unset, Clock is used to create timers.  If both are unset, DefaultTimer is used.
+	Timer Timer `json:"-" yaml:"-"`
+
+	// Clock is the optional source of timers used when Timer is unset.
+	Clock clock.Clock `json:"-" yaml:"-"`
+
+	// Check is the predicate used to determine if a result is bad.  A result for which
+	// this predicate returns false is considered good and wins the race.  If all copies
+	// produce bad results, the last bad result is returned.  If unset, DefaultCheck is used.
+	//
+	// Additionally, a bad result causes a hedge to be sent immediately if MaxTotalHedges allows it.
+	Check Check `json:"-" yaml:"-"`
+}
+
+// hedgeResult is the outcome of a single copy of a hedged request
+type hedgeResult struct {
+	index    int
+	response *http.Response
+	err      error
+}
+
+// hedger is the httpaux.Client that performs hedging
+type hedger struct {
+	next      httpaux.Client
+	maxHedges int
+	delay     time.Duration
+	timer     Timer
+	check     Check
+}
+
+// NewHedge creates client middleware that hedges idempotent requests.  If cfg.MaxTotalHedges is
+// nonpositive, the returned constructor does no decoration.
+//
+// A request with a body is only hedged if it has a GetBody function, since each copy
+// needs its own body.  Copies that lose the race are canceled, and their responses are
+// cleaned up with httpaux.Cleanup.
+func NewHedge(cfg HedgeConfig) client.Constructor {
+	return func(next httpaux.Client) httpaux.Client {
+		if next == nil {
+			next = http.DefaultClient
+		}
+
+		if cfg.MaxTotalHedges < 1 {
+			return next
+		}
+
+		h := &hedger{
+			next:      next,
+			maxHedges: cfg.MaxTotalHedges,
+			delay:     cfg.Delay,
+			timer:     cfg.Timer,
+			check:     cfg.Check,
+		}
+
+		if h.delay <= 0 {
+			h.delay = DefaultHedgeDelay
+		}
+
+		if h.timer == nil && cfg.Clock != nil {
+			h.timer = ClockTimer(cfg.Clock)
+		} else if h.timer == nil {
+			h.timer = DefaultTimer
+		}
+
+		if h.check == nil {
+			h.check = DefaultCheck
+		}
+
+		return h
+	}
+}
+
+// canHedge tests if a request can have multiple copies in flight
+func canHedge(request *http.Request) bool {
+	if !IsIdempotent(request) {
+		return false
+	}
+
+	return request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+}
+
+// drain cleans up the given number of results, invoking the appropriate cancel
+// function for each one.
+func drain(results <-chan hedgeResult, cancels []context.CancelFunc, n int) {
+	for ; n > 0; n-- {
+		r := <-results
+		httpaux.Cleanup(r.response)
+		cancels[r.index]()
+	}
+}
+
+// winner prepares a result to be returned to the caller.  the result's context
+// is canceled when the response body is closed.
+func winner(r hedgeResult, cancel context.CancelFunc) (*http.Response, error) {
+	cancelOnClose(r.response, cancel)
+	return r.response, r.err
+}
+
+func (h *hedger) Do(request *http.Request) (*http.Response, error) {
+	if !canHedge(request) {
+		return h.next.Do(request)
+	}
+
+	var (
+		ctx     = request.Context()
+		results = make(chan hedgeResult, h.maxHedges+1)
+		cancels = make([]context.CancelFunc, 0, h.maxHedges+1)
+
+		outstanding int
+		fallback    *hedgeResult
+
+		tc   <-chan time.Time
+		stop = func() bool { return false }
+	)
+
+	// send starts another copy of the request in the background
+	send := func() error {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		attempt := request.WithContext(attemptCtx)
+		if len(cancels) > 0 && request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				cancel()
+				return &GetBodyError{Err: err}
+			}
+
+			attempt.Body = body
+		}
+
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		outstanding++
+		go func() {
+			response, err := h.next.Do(attempt)
+			results <- hedgeResult{
+				index:    index,
+				response: response,
+				err:      err,
+			}
+		}()
+
+		return nil
+	}
+
+	// abandon gives up on all outstanding copies and any fallback
+	abandon := func() {
+		stop()
+		for _, cancel := range cancels {
+			cancel()
+		}
+
+		if fallback != nil {
+			httpaux.Cleanup(fallback.response)
+		}
+
+		if outstanding > 0 {
+			go drain(results, cancels, outstanding)
+		}
+	}
+
+	send() // the original request never calls GetBody
+	for outstanding > 0 {
+		if tc == nil && len(cancels) <= h.maxHedges {
+			tc, stop = h.timer(h.delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			abandon()
+			return nil, ctx.Err()
+
+		case <-tc:
+			tc = nil
+			if err := send(); err != nil {
+				abandon()
+				return nil, err
+			}
+
+		case r := <-results:
+			outstanding--
+			if !h.check(r.response, r.err) {
+				// the winner's context must live until its response body is closed
+				cancel := cancels[r.index]
+				cancels[r.index] = func() {}
+				abandon()
+				return winner(r, cancel)
+			}
+
+			// a bad result: hang onto it in case nothing better comes along
+			if fallback != nil {
+				httpaux.Cleanup(fallback.response)
+				cancels[fallback.index]()
+			}
+
+			fallback = &r
+			if len(cancels) <= h.maxHedges {
+				stop()
+				tc = nil
+				if err := send(); err != nil {
+					abandon()
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// every copy produced a bad result
+	stop()
+	return winner(*fallback, cancels[fallback.index])
+}
@@ -0,0 +1,399 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/httpmock"
+)
+
+// hedgeCall is a single copy of a request sent by a hedger
+type hedgeCall struct {
+	request *http.Request
+	reply   chan hedgeResult
+}
+
+type hedgeOutcome struct {
+	response *http.Response
+	err      error
+}
+
+type HedgeTestSuite struct {
+	suite.Suite
+
+	calls  chan hedgeCall
+	timers chan chan time.Time
+}
+
+func (suite *HedgeTestSuite) SetupTest() {
+	suite.calls = make(chan hedgeCall, 10)
+	suite.timers = make(chan chan time.Time, 10)
+}
+
+// next is the decorated client.  each call blocks until the test replies
+// or the request's context is canceled.
+func (suite *HedgeTestSuite) next(request *http.Request) (*http.Response, error) {
+	c := hedgeCall{
+		request: request,
+		reply:   make(chan hedgeResult, 1),
+	}
+
+	suite.calls <- c
+	select {
+	case r := <-c.reply:
+		return r.response, r.err
+
+	case <-request.Context().Done():
+		return &http.Response{Body: httpmock.EmptyBody()}, request.Context().Err()
+	}
+}
+
+func (suite *HedgeTestSuite) timer(time.Duration) (<-chan time.Time, func() bool) {
+	tc := make(chan time.Time, 1)
+	suite.timers <- tc
+	return tc, func() bool { return true }
+}
+
+func (suite *HedgeTestSuite) newHedge(maxHedges int) httpaux.Client {
+	return NewHedge(HedgeConfig{
+		MaxTotalHedges: maxHedges,
+		Delay:          time.Second,
+		Timer:          suite.timer,
+		Check: func(r *http.Response, err error) bool {
+			return err != nil || r.StatusCode >= 500
+		},
+	})(client.Func(suite.next))
+}
+
+func (suite *HedgeTestSuite) do(c httpaux.Client, request *http.Request) <-chan hedgeOutcome {
+	outcome := make(chan hedgeOutcome, 1)
+	go func() {
+		response, err := c.Do(request)
+		outcome <- hedgeOutcome{response: response, err: err}
+	}()
+
+	return outcome
+}
+
+func (suite *HedgeTestSuite) nextCall() hedgeCall {
+	select {
+	case c := <-suite.calls:
+		return c
+	case <-time.After(2 * time.Second):
+		suite.Require().Fail("No call was made")
+		return hedgeCall{}
+	}
+}
+
+func (suite *HedgeTestSuite) fireTimer() {
+	select {
+	case tc := <-suite.timers:
+		tc <- time.Time{}
+	case <-time.After(2 * time.Second):
+		suite.Require().Fail("No timer was started")
+	}
+}
+
+func (suite *HedgeTestSuite) outcome(o <-chan hedgeOutcome) hedgeOutcome {
+	select {
+	case r := <-o:
+		return r
+	case <-time.After(2 * time.Second):
+		suite.Require().Fail("No outcome")
+		return hedgeOutcome{}
+	}
+}
+
+func (suite *HedgeTestSuite) assertCanceled(c hedgeCall) {
+	select {
+	case <-c.request.Context().Done():
+		// passing
+	case <-time.After(2 * time.Second):
+		suite.Fail("The request context was not canceled")
+	}
+}
+
+func (suite *HedgeTestSuite) response(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       httpmock.BodyString(body),
+	}
+}
+
+func (suite *HedgeTestSuite) newRequest(method string) *http.Request {
+	r, err := http.NewRequest(method, "/test", nil)
+	suite.Require().NoError(err)
+	return r
+}
+
+func (suite *HedgeTestSuite) TestNoHedges() {
+	next := client.Func(suite.next)
+	c := NewHedge(HedgeConfig{})(next)
+	suite.Require().NotNil(c)
+	_, ok := c.(*hedger)
+	suite.False(ok)
+
+	suite.NotNil(NewHedge(HedgeConfig{})(nil))
+}
+
+func (suite *HedgeTestSuite) TestDefaults() {
+	c, ok := NewHedge(HedgeConfig{MaxTotalHedges: 1})(nil).(*hedger)
+	suite.Require().True(ok)
+	suite.Equal(DefaultHedgeDelay, c.delay)
+	suite.NotNil(c.timer)
+	suite.NotNil(c.check)
+	suite.Equal(http.DefaultClient, c.next)
+}
+
+func (suite *HedgeTestSuite) TestNotIdempotent() {
+	outcome := suite.do(suite.newHedge(2), suite.newRequest("POST"))
+	call := suite.nextCall()
+	suite.Empty(suite.timers, "no timer should be started for a request that isn't hedged")
+	call.reply <- hedgeResult{response: suite.response(200, "")}
+
+	o := suite.outcome(outcome)
+	suite.NoError(o.err)
+	suite.Equal(200, o.response.StatusCode)
+}
+
+func (suite *HedgeTestSuite) TestFirstWins() {
+	outcome := suite.do(suite.newHedge(2), suite.newRequest("GET"))
+	call := suite.nextCall()
+	call.reply <- hedgeResult{response: suite.response(200, "first")}
+
+	o := suite.outcome(outcome)
+	suite.NoError(o.err)
+	suite.Require().NotNil(o.response)
+	suite.Empty(suite.calls, "no hedges should have been sent")
+
+	b, err := io.ReadAll(o.response.Body)
+	suite.NoError(err)
+	suite.Equal("first", string(b))
+	suite.NoError(call.request.Context().Err(), "the winner's context should be active until the body is closed")
+	o.response.Body.Close()
+	suite.Error(call.request.Context().Err())
+}
+
+func (suite *HedgeTestSuite) TestHedgeWins() {
+	outcome := suite.do(suite.newHedge(2), suite.newRequest("GET"))
+	first := suite.nextCall()
+	suite.fireTimer()
+	second := suite.nextCall()
+	second.reply <- hedgeResult{response: suite.response(200, "second")}
+
+	o := suite.outcome(outcome)
+	suite.NoError(o.err)
+	suite.Require().NotNil(o.response)
+	suite.Equal(200, o.response.StatusCode)
+	suite.assertCanceled(first)
+	suite.NoError(second.request.Context().Err())
+	o.response.Body.Close()
+	suite.Error(second.request.Context().Err())
+}
+
+func (suite *HedgeTestSuite) TestBadResultSendsHedge() {
+	outcome := suite.do(suite.newHedge(1), suite.newRequest("GET"))
+	first := suite.nextCall()
+	firstBody := httpmock.BodyString("first")
+	first.reply <- hedgeResult{response: &http.Response{StatusCode: 503, Body: firstBody}}
+
+	// the bad result should trigger the hedge immediately
+	second := suite.nextCall()
+	second.reply <- hedgeResult{response: suite.response(200, "second")}
+
+	o := suite.outcome(outcome)
+	suite.NoError(o.err)
+	suite.Require().NotNil(o.response)
+	suite.Equal(200, o.response.StatusCode)
+	suite.True(firstBody.Closed(), "the bad result should have been cleaned up")
+	suite.assertCanceled(first)
+	o.response.Body.Close()
+}
+
+func (suite *HedgeTestSuite) TestFailedHedgeSendsHedge() {
+	outcome := suite.do(suite.newHedge(2), suite.newRequest("GET"))
+	first := suite.nextCall()
+	suite.fireTimer()
+	second := suite.nextCall()
+
+	// the first hedge fails fast while the original is still outstanding
+	secondBody := httpmock.BodyString("second")
+	second.reply <- hedgeResult{response: &http.Response{StatusCode: 503, Body: secondBody}}
+
+	third := suite.nextCall()
+	third.reply <- hedgeResult{response: suite.response(200, "third")}
+
+	o := suite.outcome(outcome)
+	suite.NoError(o.err)
+	suite.Require().NotNil(o.response)
+	suite.Equal(200, o.response.StatusCode)
+	suite.True(secondBody.Closed(), "the bad result should have been cleaned up")
+	suite.assertCanceled(first)
+	suite.assertCanceled(second)
+	o.response.Body.Close()
+}
+
+func (suite *HedgeTestSuite) TestTotalHedgesExhausted() {
+	outcome := suite.do(suite.newHedge(1), suite.newRequest("GET"))
+	first := suite.nextCall()
+	suite.fireTimer()
+	second := suite.nextCall()
+
+	// the only hedge fails, and no more are sent even though nothing else is in flight
+	// besides the original
+	second.reply <- hedgeResult{response: &http.Response{StatusCode: 503, Body: httpmock.BodyString("second")}}
+	select {
+	case <-suite.calls:
+		suite.Fail("No more hedges should have been sent")
+	case <-suite.timers:
+		suite.Fail("No more timers should have been started")
+	case <-time.After(100 * time.Millisecond):
+		// passing
+	}
+
+	first.reply <- hedgeResult{response: suite.response(200, "first")}
+	o := suite.outcome(outcome)
+	suite.NoError(o.err)
+	suite.Require().NotNil(o.response)
+	suite.Equal(200, o.response.StatusCode)
+	o.response.Body.Close()
+}
+
+func (suite *HedgeTestSuite) TestAllBad() {
+	outcome := suite.do(suite.newHedge(1), suite.newRequest("GET"))
+	first := suite.nextCall()
+	suite.fireTimer()
+	second := suite.nextCall()
+
+	var (
+		firstBody  = httpmock.BodyString("first")
+		secondBody = httpmock.BodyString("second")
+	)
+
+	first.reply <- hedgeResult{response: &http.Response{StatusCode: 503, Body: firstBody}}
+	second.reply <- hedgeResult{response: &http.Response{StatusCode: 504, Body: secondBody}}
+
+	o := suite.outcome(outcome)
+	suite.NoError(o.err)
+	suite.Require().NotNil(o.response)
+
+	// either bad result can arrive last, and the last bad result is returned
+	loser, winner := first, second
+	if o.response.StatusCode == 503 {
+		suite.True(secondBody.Closed())
+		loser, winner = second, first
+	} else {
+		suite.Equal(504, o.response.StatusCode)
+		suite.True(firstBody.Closed())
+	}
+
+	suite.assertCanceled(loser)
+	suite.NoError(winner.request.Context().Err())
+	o.response.Body.Close()
+	suite.assertCanceled(winner)
+}
+
+func (suite *HedgeTestSuite) TestErrorResult() {
+	expectedErr := errors.New("expected")
+	outcome := suite.do(suite.newHedge(1), suite.newRequest("GET"))
+	first := suite.nextCall()
+	first.reply <- hedgeResult{err: expectedErr}
+
+	second := suite.nextCall()
+	second.reply <- hedgeResult{err: expectedErr}
+
+	o := suite.outcome(outcome)
+	suite.ErrorIs(o.err, expectedErr)
+	suite.Nil(o.response)
+	suite.assertCanceled(first)
+	suite.assertCanceled(second)
+}
+
+func (suite *HedgeTestSuite) TestContextCanceled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	outcome := suite.do(suite.newHedge(1), suite.newRequest("GET").WithContext(ctx))
+	first := suite.nextCall()
+	suite.fireTimer()
+	second := suite.nextCall()
+	cancel()
+
+	o := suite.outcome(outcome)
+	suite.ErrorIs(o.err, context.Canceled)
+	suite.Nil(o.response)
+	suite.assertCanceled(first)
+	suite.assertCanceled(second)
+}
+
+func (suite *HedgeTestSuite) TestGetBody() {
+	request, err := http.NewRequest("PUT", "/test", bytes.NewBufferString("body"))
+	suite.Require().NoError(err)
+
+	outcome := suite.do(suite.newHedge(1), request)
+	first := suite.nextCall()
+	suite.fireTimer()
+	second := suite.nextCall()
+
+	b, err := io.ReadAll(second.request.Body)
+	suite.NoError(err)
+	suite.Equal("body", string(b))
+	second.reply <- hedgeResult{response: suite.response(200, "")}
+
+	o := suite.outcome(outcome)
+	suite.NoError(o.err)
+	suite.Require().NotNil(o.response)
+	suite.assertCanceled(first)
+	o.response.Body.Close()
+}
+
+func (suite *HedgeTestSuite) TestGetBodyError() {
+	expectedErr := errors.New("expected")
+	request, err := http.NewRequest("PUT", "/test", bytes.NewBufferString("body"))
+	suite.Require().NoError(err)
+	request.GetBody = func() (io.ReadCloser, error) {
+		return nil, expectedErr
+	}
+
+	outcome := suite.do(suite.newHedge(1), request)
+	first := suite.nextCall()
+	suite.fireTimer()
+
+	o := suite.outcome(outcome)
+	suite.Nil(o.response)
+	suite.ErrorIs(o.err, expectedErr)
+
+	var getBodyErr *GetBodyError
+	suite.ErrorAs(o.err, &getBodyErr)
+	suite.assertCanceled(first)
+}
+
+func (suite *HedgeTestSuite) TestBodyWithoutGetBody() {
+	request, err := http.NewRequest("PUT", "/test", nil)
+	suite.Require().NoError(err)
+	request.Body = io.NopCloser(bytes.NewBufferString("body"))
+
+	outcome := suite.do(suite.newHedge(2), request)
+	call := suite.nextCall()
+	suite.Empty(suite.timers, "a request without GetBody should not be hedged")
+	call.reply <- hedgeResult{response: suite.response(200, "")}
+
+	o := suite.outcome(outcome)
+	suite.NoError(o.err)
+	suite.NotNil(o.response)
+}
+
+func TestHedge(t *testing.T) {
+	suite.Run(t, new(HedgeTestSuite))
+}
@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"net/http"
+	"time"
+)
+
+// OnAttempt is a callback invoked just before each attempt, including the initial attempt.
+// The State reflects the attempt about to be made.
+//
+// Callbacks must not retain the State.
+type OnAttempt func(*State)
+
+// OnRetry is a callback invoked when a retry has been scheduled, just before the Client waits.
+// The attempt is the 1-based retry about to be made, and wait is how long the Client will wait
+// before making it.  The previous response's body will not be available.
+type OnRetry func(attempt int, wait time.Duration, previous *http.Response, previousErr error)
+
+// OnGiveUp is a callback invoked when a Client stops making attempts even though the most
+// recent result was retryable.  This happens when retries are exhausted, the budget is exhausted,
+// a deadline would be exceeded, the context is canceled, or GetBody fails.  The response and
+// error are what Do returns to its caller.
+//
+// Callbacks must not retain the State.
+type OnGiveUp func(state *State, response *http.Response, err error)
+
+// hooks is the set of lifecycle callbacks for a Client.  all fields are non-nil.
+type hooks struct {
+	onAttempt OnAttempt
+	onRetry   OnRetry
+	onGiveUp  OnGiveUp
+}
+
+func newHooks(cfg Config) (h hooks) {
+	h.onAttempt = cfg.OnAttempt
+	if h.onAttempt == nil {
+		h.onAttempt = func(*State) {}
+	}
+
+	h.onRetry = cfg.OnRetry
+	if h.onRetry == nil {
+		h.onRetry = func(int, time.Duration, *http.Response, error) {}
+	}
+
+	h.onGiveUp = cfg.OnGiveUp
+	if h.onGiveUp == nil {
+		h.onGiveUp = func(*State, *http.Response, error) {}
+	}
+
+	return
+}
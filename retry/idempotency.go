@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"net/http"
+
+	"github.com/xmidt-org/httpaux"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/internal/uuid"
+)
+
+// IdempotencyKeyHeader is the HTTP header that marks a request as safe to retry,
+// even when its method is not idempotent.
+//
+// See: https://datatracker.ietf.org/doc/draft-ietf-httpapi-idempotency-key-header/
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IsIdempotentMethod tests if the given HTTP method is idempotent as defined by RFC 9110.
+// The methods GET, HEAD, OPTIONS, TRACE, PUT, and DELETE are idempotent.
+//
+// See: https://www.rfc-editor.org/rfc/rfc9110#name-idempotent-methods
+func IsIdempotentMethod(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		// NOTE: the empty string is a GET as far as net/http is concerned
+		return true
+
+	default:
+		return false
+	}
+}
+
+// IsIdempotent tests if a request can be safely retried.  A request is idempotent
+// if its method is idempotent or if it carries an Idempotency-Key header.
+func IsIdempotent(request *http.Request) bool {
+	return IsIdempotentMethod(request.Method) ||
+		len(request.Header.Get(IdempotencyKeyHeader)) > 0
+}
+
+// NewIdempotencyKey generates a random key suitable for the Idempotency-Key header.
+// The key is a version 4 UUID.  The returned error is always nil, and exists so that
+// this function can be passed to IdempotencyKey.
+func NewIdempotencyKey() (string, error) {
+	return uuid.NewV4(), nil
+}
+
+// IdempotencyKey is a client middleware that sets an Idempotency-Key header on any
+// request whose method is not idempotent.  If the request already has this header,
+// it is left as is.
+//
+// The gen closure is used to produce keys.  If gen is nil, NewIdempotencyKey is used.
+// If gen returns an error, the request is not executed and that error is returned.
+//
+// When this middleware is applied outside of a Client, the key is generated once and
+// remains stable across all retry attempts:
+//
+//	c := client.NewChain(
+//	  IdempotencyKey(nil),
+//	  New(Config{Retries: 2, IdempotentOnly: true}, nil).Then,
+//	).Then(new(http.Client))
+func IdempotencyKey(gen func() (string, error)) client.Constructor {
+	if gen == nil {
+		gen = NewIdempotencyKey
+	}
+
+	return func(next httpaux.Client) httpaux.Client {
+		return client.Func(func(request *http.Request) (*http.Response, error) {
+			if !IsIdempotent(request) {
+				key, err := gen()
+				if err != nil {
+					return nil, err
+				}
+
+				if request.Header == nil {
+					request.Header = make(http.Header)
+				}
+
+				request.Header.Set(IdempotencyKeyHeader, key)
+			}
+
+			return next.Do(request)
+		})
+	}
+}
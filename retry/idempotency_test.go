@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/httpmock"
+)
+
+type IdempotencyTestSuite struct {
+	suite.Suite
+}
+
+func (suite *IdempotencyTestSuite) newRequest(method string) *http.Request {
+	r, err := http.NewRequest(method, "/test", nil)
+	suite.Require().NoError(err)
+	return r
+}
+
+func (suite *IdempotencyTestSuite) TestIsIdempotentMethod() {
+	for _, method := range []string{"", "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE"} {
+		suite.True(IsIdempotentMethod(method), method)
+	}
+
+	for _, method := range []string{"POST", "PATCH", "CONNECT", "CUSTOM"} {
+		suite.False(IsIdempotentMethod(method), method)
+	}
+}
+
+func (suite *IdempotencyTestSuite) TestIsIdempotent() {
+	suite.True(IsIdempotent(suite.newRequest("GET")))
+	suite.False(IsIdempotent(suite.newRequest("POST")))
+
+	r := suite.newRequest("POST")
+	r.Header.Set(IdempotencyKeyHeader, "key")
+	suite.True(IsIdempotent(r))
+}
+
+func (suite *IdempotencyTestSuite) TestNewIdempotencyKey() {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	first, err := NewIdempotencyKey()
+	suite.Require().NoError(err)
+	suite.Regexp(uuid, first)
+
+	second, err := NewIdempotencyKey()
+	suite.Require().NoError(err)
+	suite.Regexp(uuid, second)
+	suite.NotEqual(first, second)
+}
+
+func (suite *IdempotencyTestSuite) testIdempotencyKeyDefault() {
+	var (
+		rt     = httpmock.NewRoundTripperSuite(suite)
+		client = IdempotencyKey(nil)(&http.Client{Transport: rt})
+	)
+
+	rt.OnAny().AssertRequest(
+		httpmock.RequestAsserterFunc(func(_ *assert.Assertions, r *http.Request) {
+			suite.NotEmpty(r.Header.Get(IdempotencyKeyHeader))
+		}),
+	).Return(new(http.Response), nil).Once()
+
+	response, err := client.Do(suite.newRequest("POST"))
+	suite.NoError(err)
+	suite.NotNil(response)
+	rt.AssertExpectations()
+}
+
+func (suite *IdempotencyTestSuite) testIdempotencyKeyCustom() {
+	var (
+		rt     = httpmock.NewRoundTripperSuite(suite)
+		client = IdempotencyKey(func() (string, error) {
+			return "custom", nil
+		})(&http.Client{Transport: rt})
+	)
+
+	rt.OnAny().AssertRequest(httpmock.Header(IdempotencyKeyHeader, "custom")).
+		Return(new(http.Response), nil).Once()
+
+	response, err := client.Do(suite.newRequest("PATCH"))
+	suite.NoError(err)
+	suite.NotNil(response)
+	rt.AssertExpectations()
+}
+
+func (suite *IdempotencyTestSuite) testIdempotencyKeyExisting() {
+	var (
+		rt     = httpmock.NewRoundTripperSuite(suite)
+		client = IdempotencyKey(func() (string, error) {
+			suite.Fail("The generator should not have been called")
+			return "", nil
+		})(&http.Client{Transport: rt})
+
+		request = suite.newRequest("POST")
+	)
+
+	request.Header.Set(IdempotencyKeyHeader, "existing")
+	rt.OnAny().AssertRequest(httpmock.Header(IdempotencyKeyHeader, "existing")).
+		Return(new(http.Response), nil).Once()
+
+	response, err := client.Do(request)
+	suite.NoError(err)
+	suite.NotNil(response)
+	rt.AssertExpectations()
+}
+
+func (suite *IdempotencyTestSuite) testIdempotencyKeyIdempotentMethod() {
+	var (
+		rt     = httpmock.NewRoundTripperSuite(suite)
+		client = IdempotencyKey(nil)(&http.Client{Transport: rt})
+	)
+
+	rt.OnAny().AssertRequest(httpmock.Header(IdempotencyKeyHeader)).
+		Return(new(http.Response), nil).Once()
+
+	response, err := client.Do(suite.newRequest("PUT"))
+	suite.NoError(err)
+	suite.NotNil(response)
+	rt.AssertExpectations()
+}
+
+func (suite *IdempotencyTestSuite) testIdempotencyKeyError() {
+	var (
+		expectedErr = errors.New("expected")
+		rt          = httpmock.NewRoundTripperSuite(suite)
+		client      = IdempotencyKey(func() (string, error) {
+			return "", expectedErr
+		})(&http.Client{Transport: rt})
+	)
+
+	response, err := client.Do(suite.newRequest("POST"))
+	suite.ErrorIs(err, expectedErr)
+	suite.Nil(response)
+	rt.AssertExpectations()
+}
+
+func (suite *IdempotencyTestSuite) TestIdempotencyKey() {
+	suite.Run("Default", suite.testIdempotencyKeyDefault)
+	suite.Run("Custom", suite.testIdempotencyKeyCustom)
+	suite.Run("Existing", suite.testIdempotencyKeyExisting)
+	suite.Run("IdempotentMethod", suite.testIdempotencyKeyIdempotentMethod)
+	suite.Run("Error", suite.testIdempotencyKeyError)
+}
+
+func TestIdempotency(t *testing.T) {
+	suite.Run(t, new(IdempotencyTestSuite))
+}
@@ -118,3 +118,18 @@ func (i intervals) Len() int {
 func (i intervals) duration(r Random, attempt int) time.Duration {
 	return i[attempt].duration(r)
 }
+
+// Next satisfies the Backoff interface.  If retry is beyond the precomputed
+// intervals, the last interval is used.
+func (i intervals) Next(retry int, _ time.Duration, r Random) time.Duration {
+	switch {
+	case len(i) == 0:
+		return DefaultInterval
+
+	case retry < len(i):
+		return i.duration(r, retry)
+
+	default:
+		return i.duration(r, len(i)-1)
+	}
+}
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// RetryAfterHeader is the canonical name of the HTTP header servers use to
+	// indicate when a client may try again.
+	RetryAfterHeader = "Retry-After"
+
+	// DefaultMaxRetryAfter is used when Config.MaxRetryAfter is nonpositive.
+	DefaultMaxRetryAfter time.Duration = 1 * time.Minute
+)
+
+// RetryAfterMode describes how a Client uses a Retry-After header from
+// the previous attempt's response.  Only responses with http.StatusTooManyRequests
+// or http.StatusServiceUnavailable are consulted.
+type RetryAfterMode string
+
+const (
+	// RetryAfterIgnore indicates that Retry-After headers are ignored.  This is
+	// the default, and any unrecognized mode is treated this way.
+	RetryAfterIgnore RetryAfterMode = ""
+
+	// RetryAfterOverride indicates that a Retry-After value replaces the
+	// computed interval for the next retry.
+	RetryAfterOverride RetryAfterMode = "override"
+
+	// RetryAfterFloor indicates that a Retry-After value is the minimum time
+	// to wait before the next retry.  If the computed interval is longer, it is used.
+	RetryAfterFloor RetryAfterMode = "floor"
+)
+
+// ParseRetryAfter parses the value of a Retry-After header.  Both the delta-seconds
+// and the HTTP-date forms are supported.  For the HTTP-date form, now is used
+// to compute the duration.  A date in the past results in a zero duration.
+//
+// If v is blank or cannot be parsed, this function returns false.
+//
+// See: https://www.rfc-editor.org/rfc/rfc9110#field.retry-after
+func ParseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if len(v) == 0 {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	when, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+
+	d := when.Sub(now)
+	if d < 0 {
+		d = 0
+	}
+
+	return d, true
+}
+
+// retryAfter is the precomputed Retry-After strategy for a Client
+type retryAfter struct {
+	mode RetryAfterMode
+	max  time.Duration
+}
+
+func newRetryAfter(cfg Config) retryAfter {
+	ra := retryAfter{
+		mode: cfg.RetryAfter,
+		max:  cfg.MaxRetryAfter,
+	}
+
+	if ra.max <= 0 {
+		ra.max = DefaultMaxRetryAfter
+	}
+
+	return ra
+}
+
+// adjust returns the time to wait before the next retry, taking into account
+// any Retry-After header in the previous response.  If there is no applicable
+// Retry-After value, wait is returned as is.
+func (ra retryAfter) adjust(wait time.Duration, previous *http.Response, now time.Time) time.Duration {
+	if previous == nil {
+		return wait
+	}
+
+	switch ra.mode {
+	case RetryAfterOverride, RetryAfterFloor:
+		// continue
+
+	default:
+		return wait
+	}
+
+	switch previous.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		// continue
+
+	default:
+		return wait
+	}
+
+	d, ok := ParseRetryAfter(previous.Header.Get(RetryAfterHeader), now)
+	if !ok {
+		return wait
+	} else if d > ra.max {
+		d = ra.max
+	}
+
+	if ra.mode == RetryAfterOverride || d > wait {
+		return d
+	}
+
+	return wait
+}
@@ -0,0 +1,192 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RetryAfterTestSuite struct {
+	suite.Suite
+
+	now time.Time
+}
+
+func (suite *RetryAfterTestSuite) SetupTest() {
+	suite.now = time.Date(2021, time.March, 4, 10, 30, 0, 0, time.UTC)
+}
+
+func (suite *RetryAfterTestSuite) response(statusCode int, retryAfter string) *http.Response {
+	r := &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{},
+	}
+
+	if len(retryAfter) > 0 {
+		r.Header.Set(RetryAfterHeader, retryAfter)
+	}
+
+	return r
+}
+
+func (suite *RetryAfterTestSuite) TestParseRetryAfter() {
+	testCases := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{
+			value: "",
+		},
+		{
+			value: "   ",
+		},
+		{
+			value: "this is not valid",
+		},
+		{
+			value: "-12",
+		},
+		{
+			value:    "0",
+			expected: 0,
+			ok:       true,
+		},
+		{
+			value:    "120",
+			expected: 2 * time.Minute,
+			ok:       true,
+		},
+		{
+			value:    " 7 ",
+			expected: 7 * time.Second,
+			ok:       true,
+		},
+		{
+			value:    suite.now.Add(90 * time.Second).Format(http.TimeFormat),
+			expected: 90 * time.Second,
+			ok:       true,
+		},
+		{
+			value:    suite.now.Add(-time.Hour).Format(http.TimeFormat),
+			expected: 0,
+			ok:       true,
+		},
+	}
+
+	for i, testCase := range testCases {
+		suite.Run(strconv.Itoa(i), func() {
+			actual, ok := ParseRetryAfter(testCase.value, suite.now)
+			suite.Equal(testCase.ok, ok)
+			suite.Equal(testCase.expected, actual)
+		})
+	}
+}
+
+func (suite *RetryAfterTestSuite) TestAdjust() {
+	testCases := []struct {
+		cfg      Config
+		wait     time.Duration
+		previous *http.Response
+		expected time.Duration
+	}{
+		{
+			cfg:      Config{},
+			wait:     5 * time.Second,
+			previous: suite.response(http.StatusTooManyRequests, "30"),
+			expected: 5 * time.Second,
+		},
+		{
+			cfg:      Config{RetryAfter: "unrecognized"},
+			wait:     5 * time.Second,
+			previous: suite.response(http.StatusTooManyRequests, "30"),
+			expected: 5 * time.Second,
+		},
+		{
+			cfg:      Config{RetryAfter: RetryAfterOverride},
+			wait:     5 * time.Second,
+			previous: nil,
+			expected: 5 * time.Second,
+		},
+		{
+			cfg:      Config{RetryAfter: RetryAfterOverride},
+			wait:     5 * time.Second,
+			previous: suite.response(http.StatusGatewayTimeout, "30"),
+			expected: 5 * time.Second,
+		},
+		{
+			cfg:      Config{RetryAfter: RetryAfterOverride},
+			wait:     5 * time.Second,
+			previous: suite.response(http.StatusTooManyRequests, ""),
+			expected: 5 * time.Second,
+		},
+		{
+			cfg:      Config{RetryAfter: RetryAfterOverride},
+			wait:     5 * time.Second,
+			previous: suite.response(http.StatusTooManyRequests, "2"),
+			expected: 2 * time.Second,
+		},
+		{
+			cfg:      Config{RetryAfter: RetryAfterOverride},
+			wait:     5 * time.Second,
+			previous: suite.response(http.StatusServiceUnavailable, "30"),
+			expected: 30 * time.Second,
+		},
+		{
+			cfg:      Config{RetryAfter: RetryAfterOverride},
+			wait:     5 * time.Second,
+			previous: suite.response(http.StatusServiceUnavailable, suite.now.Add(time.Minute).Format(http.TimeFormat)),
+			expected: time.Minute,
+		},
+		{
+			cfg:      Config{RetryAfter: RetryAfterOverride},
+			wait:     5 * time.Second,
+			previous: suite.response(http.StatusServiceUnavailable, "3600"),
+			expected: DefaultMaxRetryAfter,
+		},
+		{
+			cfg:      Config{RetryAfter: RetryAfterOverride, MaxRetryAfter: 10 * time.Second},
+			wait:     5 * time.Second,
+			previous: suite.response(http.StatusServiceUnavailable, "3600"),
+			expected: 10 * time.Second,
+		},
+		{
+			cfg:      Config{RetryAfter: RetryAfterFloor},
+			wait:     5 * time.Second,
+			previous: suite.response(http.StatusTooManyRequests, "2"),
+			expected: 5 * time.Second,
+		},
+		{
+			cfg:      Config{RetryAfter: RetryAfterFloor},
+			wait:     5 * time.Second,
+			previous: suite.response(http.StatusTooManyRequests, "20"),
+			expected: 20 * time.Second,
+		},
+		{
+			cfg:      Config{RetryAfter: RetryAfterFloor, MaxRetryAfter: 10 * time.Second},
+			wait:     5 * time.Second,
+			previous: suite.response(http.StatusTooManyRequests, "20"),
+			expected: 10 * time.Second,
+		},
+	}
+
+	for i, testCase := range testCases {
+		suite.Run(strconv.Itoa(i), func() {
+			ra := newRetryAfter(testCase.cfg)
+			suite.Equal(
+				testCase.expected,
+				ra.adjust(testCase.wait, testCase.previous, suite.now),
+			)
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	suite.Run(t, new(RetryAfterTestSuite))
+}
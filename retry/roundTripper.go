@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"net/http"
+
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+// ThenRoundTrip is a round tripper middleware that decorates an http.RoundTripper with
+// this instance's retry semantics.  If next is nil, http.DefaultTransport is decorated.
+//
+// The returned http.RoundTripper exposes next's CloseIdleConnections method, if it has one.
+// This method allows retry semantics to be placed within an http.Transport chain or used by
+// code that only accepts an http.RoundTripper.
+func (c *Client) ThenRoundTrip(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	clone := new(Client)
+	*clone = *c
+	clone.next = client.Func(next.RoundTrip)
+
+	return roundtrip.PreserveCloseIdler(
+		next,
+		roundtrip.Func(clone.Do),
+	)
+}
+
+// NewRoundTripper constructs an http.RoundTripper that retries HTTP transactions
+// using the given configuration.  This function is the http.RoundTripper analog of New.
+// If next is nil, http.DefaultTransport is used.
+func NewRoundTripper(cfg Config, next http.RoundTripper) http.RoundTripper {
+	return New(cfg, nil).ThenRoundTrip(next)
+}
+
+// NewConstructor produces a roundtrip.Constructor that applies retry semantics
+// from the given configuration.  This allows retries to be part of a roundtrip.Chain.
+func NewConstructor(cfg Config) roundtrip.Constructor {
+	return New(cfg, nil).ThenRoundTrip
+}
@@ -0,0 +1,189 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/httpmock"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+// upgradeBody is the io.ReadWriteCloser body of a 101 Switching Protocols response
+type upgradeBody struct {
+	bytes.Buffer
+}
+
+func (ub *upgradeBody) Close() error {
+	return nil
+}
+
+type RoundTripperTestSuite struct {
+	suite.Suite
+}
+
+func (suite *RoundTripperTestSuite) config(retries int) Config {
+	return Config{
+		Retries: retries,
+		Check: func(r *http.Response, err error) bool {
+			return err == nil && r.StatusCode == http.StatusServiceUnavailable
+		},
+		Timer: func(time.Duration) (<-chan time.Time, func() bool) {
+			tc := make(chan time.Time)
+			close(tc)
+			return tc, func() bool { return true }
+		},
+	}
+}
+
+func (suite *RoundTripperTestSuite) stateAsserter(attempt int) httpmock.RequestAsserterFunc {
+	return func(_ *assert.Assertions, r *http.Request) {
+		s := GetState(r.Context())
+		suite.Require().NotNil(s)
+		suite.Equal(attempt, s.Attempt())
+	}
+}
+
+func (suite *RoundTripperTestSuite) TestRetries() {
+	var (
+		next = httpmock.NewRoundTripperSuite(suite)
+		rt   = NewRoundTripper(suite.config(2), next)
+	)
+
+	suite.Require().NotNil(rt)
+	_, ok := rt.(roundtrip.CloseIdler)
+	suite.False(ok, "CloseIdleConnections should not be exposed when next doesn't expose it")
+
+	next.OnAny().AssertRequest(httpmock.Body("a lovely body")).
+		Return(&http.Response{StatusCode: http.StatusServiceUnavailable}, nil).Once()
+	next.OnAny().AssertRequest(httpmock.Body("a lovely body")).
+		Return(&http.Response{StatusCode: http.StatusOK}, nil).Once()
+
+	original, err := http.NewRequest("POST", "/test", bytes.NewBufferString("a lovely body"))
+	suite.Require().NoError(err)
+	originalBody := original.Body
+
+	response, err := rt.RoundTrip(original)
+	suite.NoError(err)
+	suite.Require().NotNil(response)
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.True(originalBody == original.Body, "the original request should not be modified")
+	next.AssertExpectations()
+}
+
+func (suite *RoundTripperTestSuite) TestDefaultTransport() {
+	rt := NewRoundTripper(Config{}, nil)
+	suite.Require().NotNil(rt)
+
+	_, ok := rt.(roundtrip.CloseIdler)
+	suite.True(ok, "http.DefaultTransport's CloseIdleConnections should be exposed")
+}
+
+func (suite *RoundTripperTestSuite) TestCloseIdleConnections() {
+	var (
+		next = &httpmock.CloseIdler{
+			RoundTripper: httpmock.NewRoundTripperSuite(suite),
+		}
+
+		rt = NewConstructor(suite.config(1))(next)
+	)
+
+	suite.Require().NotNil(rt)
+	next.OnCloseIdleConnections().Once()
+	roundtrip.CloseIdleConnections(rt)
+	next.AssertExpectations()
+}
+
+func (suite *RoundTripperTestSuite) TestChain() {
+	var (
+		next = httpmock.NewRoundTripperSuite(suite)
+		rt   = roundtrip.NewChain(NewConstructor(suite.config(1))).Then(next)
+	)
+
+	next.OnAny().AssertRequest(suite.stateAsserter(0)).
+		Return(&http.Response{StatusCode: http.StatusServiceUnavailable}, nil).Once()
+	next.OnAny().AssertRequest(suite.stateAsserter(1)).
+		Return(&http.Response{StatusCode: http.StatusOK}, nil).Once()
+
+	request, err := http.NewRequest("GET", "/test", nil)
+	suite.Require().NoError(err)
+
+	response, err := rt.RoundTrip(request)
+	suite.NoError(err)
+	suite.Require().NotNil(response)
+	suite.Equal(http.StatusOK, response.StatusCode)
+	next.AssertExpectations()
+}
+
+func (suite *RoundTripperTestSuite) TestResponseBodyUntouched() {
+	testCases := []struct {
+		name        string
+		cfg         Config
+		requestBody string
+		statusCode  int
+	}{
+		{
+			name:       "ZeroConfig",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:        "BufferedInMemory",
+			cfg:         Config{Retries: 1, MaxBufferedBody: 100},
+			requestBody: "buffered",
+			statusCode:  http.StatusOK,
+		},
+		{
+			name:       "SwitchingProtocols",
+			statusCode: http.StatusSwitchingProtocols,
+		},
+		{
+			name:       "SwitchingProtocolsMaxElapsedTime",
+			cfg:        Config{MaxElapsedTime: time.Minute},
+			statusCode: http.StatusSwitchingProtocols,
+		},
+		{
+			name:       "SwitchingProtocolsPerAttemptTimeout",
+			cfg:        Config{PerAttemptTimeout: time.Minute},
+			statusCode: http.StatusSwitchingProtocols,
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			var (
+				body = new(upgradeBody)
+				next = httpmock.NewRoundTripperSuite(suite)
+				rt   = NewRoundTripper(testCase.cfg, next)
+			)
+
+			next.OnAny().Return(&http.Response{StatusCode: testCase.statusCode, Body: body}, nil).Once()
+
+			request, err := http.NewRequest("GET", "/test", nil)
+			suite.Require().NoError(err)
+			if len(testCase.requestBody) > 0 {
+				request.Body = io.NopCloser(strings.NewReader(testCase.requestBody))
+			}
+
+			response, err := rt.RoundTrip(request)
+			suite.NoError(err)
+			suite.Require().NotNil(response)
+			suite.True(body == response.Body, "the response body should not be wrapped")
+
+			_, ok := response.Body.(io.ReadWriteCloser)
+			suite.True(ok, "the response body should be an io.ReadWriteCloser")
+			next.AssertExpectations()
+		})
+	}
+}
+
+func TestRoundTripper(t *testing.T) {
+	suite.Run(t, new(RoundTripperTestSuite))
+}
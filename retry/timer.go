@@ -3,7 +3,11 @@
 
 package retry
 
-import "time"
+import (
+	"time"
+
+	"github.com/xmidt-org/httpaux/clock"
+)
 
 // Timer is a strategy for starting a timer with its stop function
 type Timer func(time.Duration) (<-chan time.Time, func() bool)
@@ -14,3 +18,12 @@ func DefaultTimer(d time.Duration) (<-chan time.Time, func() bool) {
 	t := time.NewTimer(d)
 	return t.C, t.Stop
 }
+
+// ClockTimer adapts a clock.Clock to the Timer strategy.  This allows a clock.Fake
+// to drive the waits between retries in tests.
+func ClockTimer(c clock.Clock) Timer {
+	return func(d time.Duration) (<-chan time.Time, func() bool) {
+		t := c.NewTimer(d)
+		return t.C(), t.Stop
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/httpaux/clock"
 )
 
 func TestDefaultTimer(t *testing.T) {
@@ -24,3 +25,28 @@ func TestDefaultTimer(t *testing.T) {
 	assert.True(stop())
 	assert.False(stop())
 }
+
+func TestClockTimer(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+
+		start = time.Now()
+		fake  = clock.NewFake(start)
+
+		tc, stop = ClockTimer(fake)(time.Minute)
+	)
+
+	require.NotNil(tc)
+	require.NotNil(stop)
+	fake.Add(time.Minute)
+
+	select {
+	case actual := <-tc:
+		assert.Equal(start.Add(time.Minute), actual)
+	default:
+		assert.Fail("the timer did not fire")
+	}
+
+	assert.False(stop())
+}
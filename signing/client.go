@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package signing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/textproto"
+	"time"
+
+	"github.com/xmidt-org/httpaux"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/clock"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+// nonceSize is the number of random bytes in each nonce
+const nonceSize = 16
+
+// SignerConfig is the set of configuration options for signing requests.
+type SignerConfig struct {
+	// Keys is the source of signing keys.  This field is required.
+	Keys KeyStore `json:"-" yaml:"-"`
+
+	// Header is the header that carries the signature.  If unset, DefaultHeader is used.
+	Header string `json:"header" yaml:"header"`
+
+	// Headers are the request headers that are signed.  HostHeader signs the request's host.
+	// If unset, DefaultHeaders is used.
+	Headers []string `json:"headers" yaml:"headers"`
+
+	// Random is the optional source of randomness for nonces.  If unset, crypto/rand.Reader is used.
+	Random io.Reader `json:"-" yaml:"-"`
+
+	// Clock is the optional source of signature timestamps.  If unset, clock.System is used.
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+// signer is the common implementation for signing middleware
+type signer struct {
+	keys    KeyStore
+	header  string
+	headers []string
+	random  io.Reader
+	now     func() time.Time
+}
+
+func newSigner(cfg SignerConfig) *signer {
+	if cfg.Keys == nil {
+		panic("signing: a KeyStore is required")
+	}
+
+	s := &signer{
+		keys:    cfg.Keys,
+		header:  DefaultHeader,
+		headers: headerNames(cfg.Headers),
+		random:  cfg.Random,
+		now:     clock.OrSystem(cfg.Clock).Now,
+	}
+
+	if len(cfg.Header) > 0 {
+		s.header = textproto.CanonicalMIMEHeaderKey(cfg.Header)
+	}
+
+	if len(s.headers) == 0 {
+		s.headers = headerNames(DefaultHeaders())
+	}
+
+	if s.random == nil {
+		s.random = rand.Reader
+	}
+
+	return s
+}
+
+// readBody reads and closes a request body.  A missing body yields a nil slice.
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil || body == http.NoBody {
+		return nil, nil
+	}
+
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// sign produces a signed copy of the given request.  The original request is not modified,
+// although its body is consumed and closed.
+func (s *signer) sign(request *http.Request) (*http.Request, error) {
+	body, err := readBody(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.keys.Current(request.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	var nonce [nonceSize]byte
+	if _, err := io.ReadFull(s.random, nonce[:]); err != nil {
+		return nil, err
+	}
+
+	signed := request.Clone(request.Context())
+	if body != nil {
+		signed.ContentLength = int64(len(body))
+		signed.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+
+		signed.Body, _ = signed.GetBody()
+	}
+
+	sig := signature{
+		keyID:     key.ID,
+		timestamp: s.now().Unix(),
+		nonce:     base64.RawURLEncoding.EncodeToString(nonce[:]),
+		headers:   s.headers,
+	}
+
+	sig.sig = computeSignature(key.Secret, signed, sig, bodyDigest(body))
+	signed.Header.Set(s.header, sig.String())
+	return signed, nil
+}
+
+// NewClient creates client middleware that signs each request.  Request bodies are
+// read in full in order to compute their digest, and the outgoing request's GetBody
+// replays that content.  If signing fails, the request is not sent and the error is returned.
+//
+// Since servers reject a nonce that has already been used, this middleware must be
+// applied inside any retry middleware, so that each attempt gets a fresh signature:
+//
+//	c := client.NewChain(
+//	  retry.New(retry.Config{Retries: 2}, nil).Then,
+//	  signing.NewClient(signing.SignerConfig{Keys: keys}),
+//	).Then(new(http.Client))
+//
+// If the decorated client is nil, http.DefaultClient is used.
+func NewClient(cfg SignerConfig) client.Constructor {
+	s := newSigner(cfg)
+	return func(next httpaux.Client) httpaux.Client {
+		if next == nil {
+			next = http.DefaultClient
+		}
+
+		return client.Func(func(request *http.Request) (*http.Response, error) {
+			signed, err := s.sign(request)
+			if err != nil {
+				return nil, err
+			}
+
+			return next.Do(signed)
+		})
+	}
+}
+
+// NewRoundTripper creates round tripper middleware that signs each request just as NewClient
+// does, including the requirement that it be applied inside any retry middleware.
+//
+// If the decorated round tripper is nil, http.DefaultTransport is used.
+func NewRoundTripper(cfg SignerConfig) roundtrip.Constructor {
+	s := newSigner(cfg)
+	return func(next http.RoundTripper) http.RoundTripper {
+		if next == nil {
+			next = http.DefaultTransport
+		}
+
+		return roundtrip.PreserveCloseIdler(
+			next,
+			roundtrip.Func(func(request *http.Request) (*http.Response, error) {
+				signed, err := s.sign(request)
+				if err != nil {
+					return nil, err
+				}
+
+				return next.RoundTrip(signed)
+			}),
+		)
+	}
+}
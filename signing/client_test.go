@@ -0,0 +1,266 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package signing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/clock"
+	"github.com/xmidt-org/httpaux/httpmock"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+// failingKeys is a KeyStore that always fails
+type failingKeys struct {
+	err error
+}
+
+func (fk failingKeys) Current(context.Context) (Key, error)     { return Key{}, fk.err }
+func (fk failingKeys) Get(context.Context, string) (Key, error) { return Key{}, fk.err }
+
+type ClientTestSuite struct {
+	suite.Suite
+
+	keys  *KeyRing
+	clock *clock.Fake
+}
+
+func (suite *ClientTestSuite) SetupTest() {
+	suite.keys = NewKeyRing("k1", []byte("secret"))
+	suite.clock = clock.NewFake(time.Unix(1718000000, 0))
+}
+
+func (suite *ClientTestSuite) config() SignerConfig {
+	return SignerConfig{
+		Keys:   suite.keys,
+		Random: bytes.NewReader(bytes.Repeat([]byte{0xab}, 1024)),
+		Clock:  suite.clock,
+	}
+}
+
+// assertSigned verifies the signature of an outgoing request
+func (suite *ClientTestSuite) assertSigned(assert *assert.Assertions, header string, expectedBody string, r *http.Request) {
+	s, err := parseSignature(r.Header.Get(header))
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Equal("k1", s.keyID)
+	assert.Equal(int64(1718000000), s.timestamp)
+	assert.NotEmpty(s.nonce)
+
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		body, err = io.ReadAll(r.Body)
+		assert.NoError(err)
+	}
+
+	assert.Equal(expectedBody, string(body))
+	assert.Equal(computeSignature([]byte("secret"), r, s, bodyDigest(body)), s.sig)
+
+	if len(expectedBody) > 0 {
+		replay, err := r.GetBody()
+		if assert.NoError(err) {
+			b, err := io.ReadAll(replay)
+			assert.NoError(err)
+			assert.Equal(expectedBody, string(b))
+		}
+	}
+}
+
+// newRequest creates a request with the headers that signing tests expect
+func (suite *ClientTestSuite) newRequest(body string) *http.Request {
+	var b io.Reader
+	if len(body) > 0 {
+		b = strings.NewReader(body)
+	}
+
+	request, err := http.NewRequest("POST", "http://example.com/path?q=1", b)
+	suite.Require().NoError(err)
+	request.Header.Set("Content-Type", "text/plain")
+	request.Header.Set("X-Request-ID", "123")
+	return request
+}
+
+func (suite *ClientTestSuite) TestSign() {
+	testCases := []struct {
+		name           string
+		cfg            func(SignerConfig) SignerConfig
+		body           string
+		expectedHeader string
+		expectedSigned []string
+	}{
+		{
+			name:           "NoBody",
+			expectedHeader: DefaultHeader,
+			expectedSigned: []string{"host", "content-type"},
+		},
+		{
+			name:           "Body",
+			body:           "here is a lovely body",
+			expectedHeader: DefaultHeader,
+			expectedSigned: []string{"host", "content-type"},
+		},
+		{
+			name: "Custom",
+			cfg: func(cfg SignerConfig) SignerConfig {
+				cfg.Header = "x-custom-signature"
+				cfg.Headers = []string{"X-Request-ID"}
+				return cfg
+			},
+			body:           "body",
+			expectedHeader: "X-Custom-Signature",
+			expectedSigned: []string{"x-request-id"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			suite.SetupTest()
+			cfg := suite.config()
+			if testCase.cfg != nil {
+				cfg = testCase.cfg(cfg)
+			}
+
+			request := suite.newRequest(testCase.body)
+			signed, err := newSigner(cfg).sign(request)
+			suite.Require().NoError(err)
+			suite.Require().NotNil(signed)
+
+			s, err := parseSignature(signed.Header.Get(testCase.expectedHeader))
+			suite.Require().NoError(err)
+			suite.Equal(testCase.expectedSigned, s.headers)
+			suite.assertSigned(suite.Assert(), testCase.expectedHeader, testCase.body, signed)
+
+			// the original request must never be modified
+			suite.Empty(request.Header.Get(testCase.expectedHeader))
+		})
+	}
+}
+
+func (suite *ClientTestSuite) TestSignError() {
+	suite.Run("KeyStoreError", func() {
+		expectedErr := errors.New("expected")
+		cfg := suite.config()
+		cfg.Keys = failingKeys{err: expectedErr}
+
+		_, err := newSigner(cfg).sign(suite.newRequest(""))
+		suite.ErrorIs(err, expectedErr)
+	})
+
+	suite.Run("BodyError", func() {
+		expectedErr := errors.New("expected")
+		request, err := http.NewRequest("POST", "http://example.com/", iotest.ErrReader(expectedErr))
+		suite.Require().NoError(err)
+
+		_, err = newSigner(suite.config()).sign(request)
+		suite.ErrorIs(err, expectedErr)
+	})
+
+	suite.Run("RandomError", func() {
+		cfg := suite.config()
+		cfg.Random = iotest.ErrReader(io.ErrUnexpectedEOF)
+
+		_, err := newSigner(cfg).sign(suite.newRequest(""))
+		suite.ErrorIs(err, io.ErrUnexpectedEOF)
+	})
+}
+
+// expectSigned returns a mock transport that expects a request signed with the default
+// configuration and the given body
+func (suite *ClientTestSuite) expectSigned(body string) *httpmock.RoundTripper {
+	next := httpmock.NewRoundTripperSuite(suite)
+	next.OnAny().AssertRequest(
+		httpmock.RequestAsserterFunc(func(assert *assert.Assertions, r *http.Request) {
+			suite.assertSigned(assert, DefaultHeader, body, r)
+		}),
+	).Return(&http.Response{StatusCode: http.StatusOK, Body: httpmock.EmptyBody()}, nil).Once()
+
+	return next
+}
+
+func (suite *ClientTestSuite) TestNewClient() {
+	var (
+		next = suite.expectSigned("body")
+		c    = client.NewChain(NewClient(suite.config())).Then(&http.Client{Transport: next})
+	)
+
+	response, err := c.Do(suite.newRequest("body"))
+	suite.Require().NoError(err)
+	response.Body.Close()
+	next.AssertExpectations()
+}
+
+func (suite *ClientTestSuite) TestNewClientError() {
+	var (
+		expectedErr = errors.New("expected")
+		cfg         = suite.config()
+		next        = httpmock.NewRoundTripperSuite(suite)
+	)
+
+	cfg.Keys = failingKeys{err: expectedErr}
+	c := client.NewChain(NewClient(cfg)).Then(&http.Client{Transport: next})
+
+	_, err := c.Do(suite.newRequest("")) //nolint:bodyclose
+	suite.ErrorIs(err, expectedErr)
+	next.AssertExpectations()
+}
+
+func (suite *ClientTestSuite) TestNewClientDefault() {
+	suite.NotNil(NewClient(suite.config())(nil))
+	suite.Panics(func() {
+		NewClient(SignerConfig{})
+	})
+}
+
+func (suite *ClientTestSuite) TestNewRoundTripper() {
+	var (
+		next = &httpmock.CloseIdler{
+			RoundTripper: suite.expectSigned("body"),
+		}
+
+		rt = roundtrip.NewChain(NewRoundTripper(suite.config())).Then(next)
+	)
+
+	response, err := rt.RoundTrip(suite.newRequest("body"))
+	suite.Require().NoError(err)
+	response.Body.Close()
+
+	next.OnCloseIdleConnections().Once()
+	roundtrip.CloseIdleConnections(rt)
+	next.AssertExpectations()
+}
+
+func (suite *ClientTestSuite) TestNewRoundTripperError() {
+	var (
+		expectedErr = errors.New("expected")
+		cfg         = suite.config()
+		next        = httpmock.NewRoundTripperSuite(suite)
+	)
+
+	cfg.Keys = failingKeys{err: expectedErr}
+	response, err := NewRoundTripper(cfg)(next).RoundTrip(suite.newRequest("")) //nolint:bodyclose
+	suite.Nil(response)
+	suite.ErrorIs(err, expectedErr)
+	next.AssertExpectations()
+}
+
+func (suite *ClientTestSuite) TestNewRoundTripperDefault() {
+	suite.NotNil(NewRoundTripper(suite.config())(nil))
+}
+
+func TestClient(t *testing.T) {
+	suite.Run(t, new(ClientTestSuite))
+}
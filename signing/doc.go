@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package signing provides tamper-evident HTTP requests using HMAC-SHA256 signatures.
+
+Client middleware signs the method, request target, selected headers, a digest of the body,
+a timestamp, and a random nonce.  The signature, along with the information needed to verify it,
+is written to a single header:
+
+	X-Signature: key=2024-06,ts=1718000000,nonce=...,headers=host;content-type,sig=...
+
+Server middleware verifies that header, rejects requests whose timestamp falls outside
+an allowed clock skew, and rejects replays of a nonce.  Verification failures are rendered
+with an erraux.Encoder:
+
+	keys := signing.NewKeyRing("2024-06", secret)
+
+	c := client.NewChain(
+	  signing.NewClient(signing.SignerConfig{Keys: keys}),
+	).Then(new(http.Client))
+
+	h := signing.NewServer(signing.VerifierConfig{Keys: keys})(myHandler)
+
+Since each nonce is accepted only once, signing middleware must be applied inside any retry
+middleware.  Otherwise, every retry resends the same signature and is rejected as a replay.
+Servers require that certain headers, by default those in DefaultHeaders, are always signed.
+
+Secrets are obtained through a KeyStore.  KeyRing is an in-memory KeyStore that supports
+rotation: a new key becomes the signing key, while older keys remain valid for verification
+until they are removed.
+*/
+package signing
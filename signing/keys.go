@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package signing
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrKeyNotFound is returned by a KeyStore when there is no key with a given identifier.
+	ErrKeyNotFound = errors.New("signing key not found")
+
+	// ErrNoCurrentKey is returned by a KeyStore when there is no key to sign with.
+	ErrNoCurrentKey = errors.New("no current signing key")
+)
+
+// Key is a shared secret used for HMAC signatures.
+type Key struct {
+	// ID identifies this key.  It is transmitted with each signature, so that the
+	// verifier knows which secret to use.  An ID must not contain commas.
+	ID string
+
+	// Secret is the HMAC secret.
+	Secret []byte
+}
+
+// KeyStore provides keys for signing and verifying.  Implementations must be safe
+// for concurrent use.
+type KeyStore interface {
+	// Current returns the key used to sign new requests.  If there is no such key,
+	// this method returns ErrNoCurrentKey.
+	Current(ctx context.Context) (Key, error)
+
+	// Get returns the key with the given identifier, which is used to verify requests.
+	// If there is no such key, this method returns ErrKeyNotFound.
+	Get(ctx context.Context, id string) (Key, error)
+}
+
+// KeyRing is an in-memory KeyStore that supports key rotation.
+type KeyRing struct {
+	lock    sync.RWMutex
+	keys    map[string][]byte
+	current string
+}
+
+var _ KeyStore = (*KeyRing)(nil)
+
+// NewKeyRing creates a KeyRing whose current key has the given ID and secret.
+func NewKeyRing(id string, secret []byte) *KeyRing {
+	kr := &KeyRing{
+		keys: make(map[string][]byte),
+	}
+
+	kr.Rotate(id, secret)
+	return kr
+}
+
+// Current returns the key used for signing.
+func (kr *KeyRing) Current(context.Context) (Key, error) {
+	defer kr.lock.RUnlock()
+	kr.lock.RLock()
+
+	if secret, ok := kr.keys[kr.current]; ok {
+		return Key{ID: kr.current, Secret: secret}, nil
+	}
+
+	return Key{}, ErrNoCurrentKey
+}
+
+// Get returns the key with the given identifier.
+func (kr *KeyRing) Get(_ context.Context, id string) (Key, error) {
+	defer kr.lock.RUnlock()
+	kr.lock.RLock()
+
+	if secret, ok := kr.keys[id]; ok {
+		return Key{ID: id, Secret: secret}, nil
+	}
+
+	return Key{}, ErrKeyNotFound
+}
+
+// Add adds a key that is valid for verification but is not used for signing.  This
+// is useful to distribute a new key to verifiers before signers start using it.
+// Any existing key with the same ID is replaced.
+func (kr *KeyRing) Add(id string, secret []byte) {
+	defer kr.lock.Unlock()
+	kr.lock.Lock()
+	kr.keys[id] = append([]byte(nil), secret...)
+}
+
+// Rotate adds a key and makes it the key used for signing.  The previous keys remain
+// valid for verification until they are removed.
+func (kr *KeyRing) Rotate(id string, secret []byte) {
+	defer kr.lock.Unlock()
+	kr.lock.Lock()
+	kr.keys[id] = append([]byte(nil), secret...)
+	kr.current = id
+}
+
+// Remove removes a key, so that it is no longer valid for verification.  If the
+// removed key was the current key, signing fails until another key is rotated in.
+func (kr *KeyRing) Remove(id string) {
+	defer kr.lock.Unlock()
+	kr.lock.Lock()
+	delete(kr.keys, id)
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package signing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type KeyRingTestSuite struct {
+	suite.Suite
+}
+
+func (suite *KeyRingTestSuite) TestRotation() {
+	ctx := context.Background()
+	secret := []byte("secret1")
+	kr := NewKeyRing("k1", secret)
+
+	// the key ring must not share the caller's slice
+	secret[0] = 'X'
+
+	current, err := kr.Current(ctx)
+	suite.Require().NoError(err)
+	suite.Equal(Key{ID: "k1", Secret: []byte("secret1")}, current)
+
+	// a key that's only added is not used for signing
+	kr.Add("k2", []byte("secret2"))
+	current, err = kr.Current(ctx)
+	suite.Require().NoError(err)
+	suite.Equal("k1", current.ID)
+
+	k2, err := kr.Get(ctx, "k2")
+	suite.Require().NoError(err)
+	suite.Equal([]byte("secret2"), k2.Secret)
+
+	kr.Rotate("k3", []byte("secret3"))
+	current, err = kr.Current(ctx)
+	suite.Require().NoError(err)
+	suite.Equal(Key{ID: "k3", Secret: []byte("secret3")}, current)
+
+	// previous keys remain valid for verification
+	k1, err := kr.Get(ctx, "k1")
+	suite.Require().NoError(err)
+	suite.Equal([]byte("secret1"), k1.Secret)
+
+	kr.Remove("k1")
+	_, err = kr.Get(ctx, "k1")
+	suite.ErrorIs(err, ErrKeyNotFound)
+
+	kr.Remove("k3")
+	_, err = kr.Current(ctx)
+	suite.ErrorIs(err, ErrNoCurrentKey)
+}
+
+func TestKeyRing(t *testing.T) {
+	suite.Run(t, new(KeyRingTestSuite))
+}
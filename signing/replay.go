@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package signing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/xmidt-org/httpaux/clock"
+)
+
+// ReplayCache records the nonces of verified requests, so that replays can be rejected.
+// Implementations must be safe for concurrent use.  Servers that share keys across multiple
+// instances will typically need a shared implementation.
+type ReplayCache interface {
+	// Add records a nonce until the given expiry.  This method returns false if the nonce
+	// was already recorded and has not expired, which indicates a replay.
+	Add(ctx context.Context, nonce string, expires time.Time) (bool, error)
+}
+
+// minSweep is the smallest number of nonces a MemoryReplayCache holds before
+// it sweeps expired nonces
+const minSweep = 64
+
+// MemoryReplayCache is an in-memory ReplayCache.  Expired nonces are swept periodically
+// as new nonces are added.
+type MemoryReplayCache struct {
+	lock      sync.Mutex
+	now       func() time.Time
+	nonces    map[string]time.Time
+	nextSweep int
+}
+
+var _ ReplayCache = (*MemoryReplayCache)(nil)
+
+// NewMemoryReplayCache creates an empty MemoryReplayCache.  If c is nil, clock.System is used.
+func NewMemoryReplayCache(c clock.Clock) *MemoryReplayCache {
+	return &MemoryReplayCache{
+		now:       clock.OrSystem(c).Now,
+		nonces:    make(map[string]time.Time),
+		nextSweep: minSweep,
+	}
+}
+
+// Add records a nonce, returning false if the nonce has already been seen.
+func (mrc *MemoryReplayCache) Add(_ context.Context, nonce string, expires time.Time) (bool, error) {
+	defer mrc.lock.Unlock()
+	mrc.lock.Lock()
+
+	now := mrc.now()
+	if existing, ok := mrc.nonces[nonce]; ok && now.Before(existing) {
+		return false, nil
+	}
+
+	mrc.nonces[nonce] = expires
+	if len(mrc.nonces) >= mrc.nextSweep {
+		for n, e := range mrc.nonces {
+			if !now.Before(e) {
+				delete(mrc.nonces, n)
+			}
+		}
+
+		mrc.nextSweep = max(2*len(mrc.nonces), minSweep)
+	}
+
+	return true, nil
+}
+
+// Len returns the number of nonces currently held, including any expired nonces
+// that have not yet been swept.
+func (mrc *MemoryReplayCache) Len() int {
+	defer mrc.lock.Unlock()
+	mrc.lock.Lock()
+	return len(mrc.nonces)
+}
@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package signing
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/clock"
+)
+
+type MemoryReplayCacheTestSuite struct {
+	suite.Suite
+}
+
+func (suite *MemoryReplayCacheTestSuite) TestAdd() {
+	var (
+		ctx = context.Background()
+		c   = clock.NewFake(time.Now())
+		mrc = NewMemoryReplayCache(c)
+	)
+
+	added, err := mrc.Add(ctx, "n1", c.Now().Add(time.Minute))
+	suite.NoError(err)
+	suite.True(added)
+
+	added, err = mrc.Add(ctx, "n1", c.Now().Add(time.Minute))
+	suite.NoError(err)
+	suite.False(added)
+
+	// once expired, a nonce may be used again
+	c.Add(time.Minute)
+	added, err = mrc.Add(ctx, "n1", c.Now().Add(time.Minute))
+	suite.NoError(err)
+	suite.True(added)
+}
+
+func (suite *MemoryReplayCacheTestSuite) TestSweep() {
+	var (
+		ctx = context.Background()
+		c   = clock.NewFake(time.Now())
+		mrc = NewMemoryReplayCache(c)
+	)
+
+	for i := 0; i < minSweep-1; i++ {
+		added, err := mrc.Add(ctx, strconv.Itoa(i), c.Now().Add(time.Minute))
+		suite.Require().NoError(err)
+		suite.Require().True(added)
+	}
+
+	suite.Equal(minSweep-1, mrc.Len())
+	c.Add(time.Minute)
+
+	// this add triggers a sweep of everything that expired
+	added, err := mrc.Add(ctx, "last", c.Now().Add(time.Minute))
+	suite.NoError(err)
+	suite.True(added)
+	suite.Equal(1, mrc.Len())
+}
+
+func TestMemoryReplayCache(t *testing.T) {
+	suite.Run(t, new(MemoryReplayCacheTestSuite))
+}
@@ -0,0 +1,250 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package signing
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xmidt-org/httpaux/clock"
+	"github.com/xmidt-org/httpaux/erraux"
+)
+
+const (
+	// DefaultMaxSkew is the largest difference allowed between a signature's timestamp and
+	// the server's clock when no positive maximum is configured.
+	DefaultMaxSkew = 5 * time.Minute
+
+	// DefaultMaxBodySize is the largest request body that will be verified when no
+	// positive maximum is configured.
+	DefaultMaxBodySize int64 = 1 << 20
+)
+
+var (
+	// ErrClockSkew indicates that a signature's timestamp was too far from the server's clock.
+	ErrClockSkew = errors.New("signature timestamp outside the allowed clock skew")
+
+	// ErrReplay indicates that a signature's nonce had already been used.
+	ErrReplay = errors.New("replayed signature")
+)
+
+// VerifyError indicates that a request's signature could not be verified.
+// When rendered by an erraux.Encoder, this error produces a 401 response.
+type VerifyError struct {
+	// Err is the reason verification failed.
+	Err error
+}
+
+// Unwrap returns the reason verification failed.
+func (err *VerifyError) Unwrap() error {
+	return err.Err
+}
+
+// Error fulfills the error interface.
+func (err *VerifyError) Error() string {
+	var o strings.Builder
+	o.WriteString("signature verification failed: [")
+	o.WriteString(err.Err.Error())
+	o.WriteRune(']')
+	return o.String()
+}
+
+// StatusCode returns http.StatusUnauthorized.
+func (err *VerifyError) StatusCode() int {
+	return http.StatusUnauthorized
+}
+
+// BodyTooLargeError indicates that a request body exceeded the size that a server
+// is willing to verify.  When rendered by an erraux.Encoder, this error produces a 413 response.
+type BodyTooLargeError struct {
+	// Limit is the maximum body size.
+	Limit int64
+}
+
+// Error fulfills the error interface.
+func (err *BodyTooLargeError) Error() string {
+	var o strings.Builder
+	o.WriteString("request body exceeds the signature verification limit of ")
+	o.WriteString(strconv.FormatInt(err.Limit, 10))
+	o.WriteString(" bytes")
+	return o.String()
+}
+
+// StatusCode returns http.StatusRequestEntityTooLarge.
+func (err *BodyTooLargeError) StatusCode() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+// VerifierConfig is the set of configuration options for verifying requests.
+type VerifierConfig struct {
+	// Keys is the source of verification keys.  This field is required.
+	Keys KeyStore `json:"-" yaml:"-"`
+
+	// Header is the header that carries the signature.  If unset, DefaultHeader is used.
+	Header string `json:"header" yaml:"header"`
+
+	// RequiredHeaders are the request headers that every signature must cover.  A signature
+	// that omits any of these headers is rejected, even if it is otherwise valid.  HostHeader
+	// requires the request's host to be signed.  If unset, DefaultHeaders is used.
+	RequiredHeaders []string `json:"requiredHeaders" yaml:"requiredHeaders"`
+
+	// MaxSkew is the largest difference allowed between a signature's timestamp and the
+	// server's clock, in either direction.  If nonpositive, DefaultMaxSkew is used.
+	MaxSkew time.Duration `json:"maxSkew" yaml:"maxSkew"`
+
+	// MaxBodySize is the largest request body that will be read to verify its digest.
+	// If nonpositive, DefaultMaxBodySize is used.
+	MaxBodySize int64 `json:"maxBodySize" yaml:"maxBodySize"`
+
+	// ReplayCache records nonces to reject replays.  If unset, a MemoryReplayCache is created.
+	ReplayCache ReplayCache `json:"-" yaml:"-"`
+
+	// Encoder renders verification errors.  The zero value renders the default JSON
+	// representation, using the status codes supplied by VerifyError and BodyTooLargeError.
+	Encoder erraux.Encoder `json:"-" yaml:"-"`
+
+	// Clock is the optional source of time for enforcing clock skew.  If unset, clock.System is used.
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+// verifier is the serverside signature verification implementation
+type verifier struct {
+	keys        KeyStore
+	header      string
+	required    []string
+	maxSkew     time.Duration
+	maxBodySize int64
+	replay      ReplayCache
+	now         func() time.Time
+}
+
+func newVerifier(cfg VerifierConfig) *verifier {
+	if cfg.Keys == nil {
+		panic("signing: a KeyStore is required")
+	}
+
+	v := &verifier{
+		keys:        cfg.Keys,
+		header:      DefaultHeader,
+		required:    headerNames(cfg.RequiredHeaders),
+		maxSkew:     cfg.MaxSkew,
+		maxBodySize: cfg.MaxBodySize,
+		replay:      cfg.ReplayCache,
+		now:         clock.OrSystem(cfg.Clock).Now,
+	}
+
+	if len(cfg.Header) > 0 {
+		v.header = textproto.CanonicalMIMEHeaderKey(cfg.Header)
+	}
+
+	if len(v.required) == 0 {
+		v.required = headerNames(DefaultHeaders())
+	}
+
+	if v.maxSkew <= 0 {
+		v.maxSkew = DefaultMaxSkew
+	}
+
+	if v.maxBodySize < 1 {
+		v.maxBodySize = DefaultMaxBodySize
+	}
+
+	if v.replay == nil {
+		v.replay = NewMemoryReplayCache(cfg.Clock)
+	}
+
+	return v
+}
+
+// verify checks the signature of a request.  Since the body must be read to verify its
+// digest, the returned request has a body that replays the original content.
+func (v *verifier) verify(request *http.Request) (*http.Request, error) {
+	header := request.Header.Get(v.header)
+	if len(header) == 0 {
+		return nil, &VerifyError{Err: ErrMissingSignature}
+	}
+
+	s, err := parseSignature(header)
+	if err != nil {
+		return nil, &VerifyError{Err: err}
+	}
+
+	for _, name := range v.required {
+		if !slices.Contains(s.headers, name) {
+			return nil, &VerifyError{Err: fmt.Errorf("%w: %s", ErrHeaderNotSigned, name)}
+		}
+	}
+
+	timestamp := time.Unix(s.timestamp, 0)
+	if skew := v.now().Sub(timestamp); skew > v.maxSkew || skew < -v.maxSkew {
+		return nil, &VerifyError{Err: ErrClockSkew}
+	}
+
+	key, err := v.keys.Get(request.Context(), s.keyID)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, &VerifyError{Err: err}
+	} else if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if request.Body != nil && request.Body != http.NoBody {
+		body, err = io.ReadAll(io.LimitReader(request.Body, v.maxBodySize+1))
+		if err != nil {
+			return nil, err
+		} else if int64(len(body)) > v.maxBodySize {
+			return nil, &BodyTooLargeError{Limit: v.maxBodySize}
+		}
+	}
+
+	if !hmac.Equal(s.sig, computeSignature(key.Secret, request, s, bodyDigest(body))) {
+		return nil, &VerifyError{Err: ErrSignatureMismatch}
+	}
+
+	// only record nonces of authentic requests, so that forgeries can't fill the cache
+	added, err := v.replay.Add(request.Context(), s.keyID+":"+s.nonce, timestamp.Add(v.maxSkew))
+	if err != nil {
+		return nil, err
+	} else if !added {
+		return nil, &VerifyError{Err: ErrReplay}
+	}
+
+	verified := request.WithContext(request.Context())
+	if body != nil {
+		verified.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return verified, nil
+}
+
+// NewServer creates serverside middleware that verifies request signatures.  Requests
+// that fail verification are not passed to the decorated handler.  Instead, the error
+// is rendered with VerifierConfig.Encoder.
+//
+// Each nonce is accepted only once.  Clients that retry requests must sign each attempt
+// separately.  See NewClient.
+func NewServer(cfg VerifierConfig) func(http.Handler) http.Handler {
+	v := newVerifier(cfg)
+	encoder := cfg.Encoder
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
+			verified, err := v.verify(request)
+			if err != nil {
+				encoder.Encode(request.Context(), err, rw)
+				return
+			}
+
+			next.ServeHTTP(rw, verified)
+		})
+	}
+}
@@ -0,0 +1,410 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package signing
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/clock"
+	"github.com/xmidt-org/httpaux/erraux"
+	"github.com/xmidt-org/httpaux/retry"
+)
+
+type ServerTestSuite struct {
+	suite.Suite
+
+	keys  *KeyRing
+	clock *clock.Fake
+
+	// handled is the body seen by the decorated handler, or nil if it wasn't called
+	handled *string
+}
+
+func (suite *ServerTestSuite) SetupTest() {
+	suite.keys = NewKeyRing("k1", []byte("secret"))
+	suite.clock = clock.NewFake(time.Unix(1718000000, 0))
+	suite.handled = nil
+}
+
+func (suite *ServerTestSuite) verifierConfig() VerifierConfig {
+	return VerifierConfig{
+		Keys:        suite.keys,
+		MaxSkew:     time.Minute,
+		MaxBodySize: 64,
+		Clock:       suite.clock,
+	}
+}
+
+func (suite *ServerTestSuite) newHandler(cfg VerifierConfig) http.Handler {
+	return NewServer(cfg)(
+		http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
+			b, err := io.ReadAll(request.Body)
+			suite.NoError(err)
+			body := string(b)
+			suite.handled = &body
+			rw.WriteHeader(http.StatusNoContent)
+		}),
+	)
+}
+
+// signed produces a signed serverside request
+func (suite *ServerTestSuite) signed(method, target, body string) *http.Request {
+	var r io.Reader
+	if len(body) > 0 {
+		r = strings.NewReader(body)
+	}
+
+	request, err := http.NewRequest(method, target, r)
+	suite.Require().NoError(err)
+	request.Header.Set("Content-Type", "text/plain")
+
+	signed, err := newSigner(SignerConfig{Keys: suite.keys, Clock: suite.clock}).sign(request)
+	suite.Require().NoError(err)
+
+	// convert to a serverside request
+	serverRequest := httptest.NewRequest(method, target, signed.Body)
+	serverRequest.Header = signed.Header
+	return serverRequest
+}
+
+// serve runs a request through the verifier, returning the status code and the error cause, if any
+func (suite *ServerTestSuite) serve(h http.Handler, request *http.Request) (int, string) {
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, request)
+
+	var fields map[string]interface{}
+	if rw.Body.Len() > 0 {
+		suite.Require().NoError(json.Unmarshal(rw.Body.Bytes(), &fields))
+	}
+
+	cause, _ := fields["cause"].(string)
+	return rw.Code, cause
+}
+
+func (suite *ServerTestSuite) TestValid() {
+	h := suite.newHandler(suite.verifierConfig())
+	statusCode, _ := suite.serve(h, suite.signed("POST", "http://example.com/path?q=1", "lovely body"))
+	suite.Equal(http.StatusNoContent, statusCode)
+	suite.Require().NotNil(suite.handled)
+	suite.Equal("lovely body", *suite.handled)
+
+	suite.handled = nil
+	statusCode, _ = suite.serve(h, suite.signed("GET", "http://example.com/", ""))
+	suite.Equal(http.StatusNoContent, statusCode)
+	suite.Require().NotNil(suite.handled)
+	suite.Empty(*suite.handled)
+}
+
+func (suite *ServerTestSuite) TestRejected() {
+	testCases := []struct {
+		name               string
+		request            func() *http.Request
+		expectedStatusCode int
+		expectedErr        error
+	}{
+		{
+			name: "Missing",
+			request: func() *http.Request {
+				return httptest.NewRequest("GET", "http://example.com/", nil)
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErr:        ErrMissingSignature,
+		},
+		{
+			name: "Malformed",
+			request: func() *http.Request {
+				r := httptest.NewRequest("GET", "http://example.com/", nil)
+				r.Header.Set(DefaultHeader, "garbage")
+				return r
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErr:        ErrMalformedSignature,
+		},
+		{
+			name: "TamperedBody",
+			request: func() *http.Request {
+				r := suite.signed("POST", "http://example.com/", "original")
+				tampered := httptest.NewRequest("POST", "http://example.com/", strings.NewReader("tampered"))
+				tampered.Header = r.Header
+				return tampered
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErr:        ErrSignatureMismatch,
+		},
+		{
+			name: "TamperedPath",
+			request: func() *http.Request {
+				r := suite.signed("GET", "http://example.com/path", "")
+				r.URL.Path = "/other"
+				return r
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErr:        ErrSignatureMismatch,
+		},
+		{
+			name: "TamperedHeader",
+			request: func() *http.Request {
+				r := suite.signed("GET", "http://example.com/", "")
+				r.Header.Set("Content-Type", "application/json")
+				return r
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErr:        ErrSignatureMismatch,
+		},
+		{
+			name: "UnknownKey",
+			request: func() *http.Request {
+				r := suite.signed("GET", "http://example.com/", "")
+				suite.keys.Remove("k1")
+				return r
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErr:        ErrKeyNotFound,
+		},
+		{
+			name: "Past",
+			request: func() *http.Request {
+				r := suite.signed("GET", "http://example.com/", "")
+				suite.clock.Add(time.Minute + time.Second)
+				return r
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErr:        ErrClockSkew,
+		},
+		{
+			name: "Future",
+			request: func() *http.Request {
+				r := suite.signed("GET", "http://example.com/", "")
+				suite.clock.Add(-time.Minute - time.Second)
+				return r
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErr:        ErrClockSkew,
+		},
+		{
+			name: "BodyTooLarge",
+			request: func() *http.Request {
+				return suite.signed("POST", "http://example.com/", strings.Repeat("x", 65))
+			},
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedErr:        &BodyTooLargeError{Limit: 64},
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			suite.SetupTest()
+			h := suite.newHandler(suite.verifierConfig())
+			statusCode, cause := suite.serve(h, testCase.request())
+			suite.Equal(testCase.expectedStatusCode, statusCode)
+			suite.Contains(cause, testCase.expectedErr.Error())
+			suite.Nil(suite.handled)
+		})
+	}
+}
+
+// signedWith produces a signed serverside GET request that signs only the given headers
+func (suite *ServerTestSuite) signedWith(headers ...string) *http.Request {
+	request, err := http.NewRequest("GET", "http://example.com/", nil)
+	suite.Require().NoError(err)
+	request.Header.Set("X-Request-ID", "123")
+
+	signed, err := newSigner(SignerConfig{Keys: suite.keys, Headers: headers, Clock: suite.clock}).sign(request)
+	suite.Require().NoError(err)
+
+	serverRequest := httptest.NewRequest("GET", "http://example.com/", nil)
+	serverRequest.Header = signed.Header
+	return serverRequest
+}
+
+func (suite *ServerTestSuite) TestRequiredHeaders() {
+	suite.Run("DefaultRejected", func() {
+		suite.SetupTest()
+		h := suite.newHandler(suite.verifierConfig())
+		statusCode, cause := suite.serve(h, suite.signedWith("X-Request-ID"))
+		suite.Equal(http.StatusUnauthorized, statusCode)
+		suite.Contains(cause, ErrHeaderNotSigned.Error())
+		suite.Contains(cause, "host")
+		suite.Nil(suite.handled)
+	})
+
+	suite.Run("EmptyRejected", func() {
+		suite.SetupTest()
+		request := suite.signedWith("X-Request-ID")
+
+		// forge a signature that declares no headers at all
+		s, err := parseSignature(request.Header.Get(DefaultHeader))
+		suite.Require().NoError(err)
+		s.headers = nil
+		s.sig = computeSignature([]byte("secret"), request, s, bodyDigest(nil))
+		request.Header.Set(DefaultHeader, s.String())
+
+		statusCode, cause := suite.serve(suite.newHandler(suite.verifierConfig()), request)
+		suite.Equal(http.StatusUnauthorized, statusCode)
+		suite.Contains(cause, ErrHeaderNotSigned.Error())
+		suite.Nil(suite.handled)
+	})
+
+	suite.Run("Custom", func() {
+		suite.SetupTest()
+		cfg := suite.verifierConfig()
+		cfg.RequiredHeaders = []string{"x-request-id"}
+		h := suite.newHandler(cfg)
+
+		statusCode, _ := suite.serve(h, suite.signedWith("X-Request-ID"))
+		suite.Equal(http.StatusNoContent, statusCode)
+		suite.NotNil(suite.handled)
+
+		suite.handled = nil
+		statusCode, cause := suite.serve(h, suite.signedWith(HostHeader))
+		suite.Equal(http.StatusUnauthorized, statusCode)
+		suite.Contains(cause, "x-request-id")
+		suite.Nil(suite.handled)
+	})
+}
+
+func (suite *ServerTestSuite) TestReplay() {
+	h := suite.newHandler(suite.verifierConfig())
+	request := suite.signed("POST", "http://example.com/", "body")
+	header := request.Header.Clone()
+
+	statusCode, _ := suite.serve(h, request)
+	suite.Equal(http.StatusNoContent, statusCode)
+
+	suite.handled = nil
+	replay := httptest.NewRequest("POST", "http://example.com/", strings.NewReader("body"))
+	replay.Header = header
+	statusCode, cause := suite.serve(h, replay)
+	suite.Equal(http.StatusUnauthorized, statusCode)
+	suite.Contains(cause, ErrReplay.Error())
+	suite.Nil(suite.handled)
+}
+
+func (suite *ServerTestSuite) TestKeyStoreError() {
+	cfg := suite.verifierConfig()
+	cfg.Keys = failingKeys{err: errors.New("key store unavailable")}
+	statusCode, cause := suite.serve(suite.newHandler(cfg), suite.signed("GET", "http://example.com/", ""))
+	suite.Equal(http.StatusInternalServerError, statusCode)
+	suite.Equal("key store unavailable", cause)
+}
+
+func (suite *ServerTestSuite) TestCustomEncoder() {
+	cfg := suite.verifierConfig()
+	cfg.Encoder = erraux.Encoder{}.Add(
+		erraux.Is(ErrClockSkew).StatusCode(http.StatusForbidden),
+	)
+
+	request := suite.signed("GET", "http://example.com/", "")
+	suite.clock.Add(time.Hour)
+	statusCode, _ := suite.serve(suite.newHandler(cfg), request)
+	suite.Equal(http.StatusForbidden, statusCode)
+}
+
+func (suite *ServerTestSuite) TestEndToEnd() {
+	server := httptest.NewServer(suite.newHandler(VerifierConfig{Keys: suite.keys}))
+	defer server.Close()
+
+	c := client.NewChain(
+		NewClient(SignerConfig{Keys: suite.keys}),
+	).Then(server.Client())
+
+	for _, body := range []string{"first", "second"} {
+		request, err := http.NewRequest("PUT", server.URL+"/resource?x=y", strings.NewReader(body))
+		suite.Require().NoError(err)
+
+		response, err := c.Do(request)
+		suite.Require().NoError(err)
+		response.Body.Close()
+		suite.Equal(http.StatusNoContent, response.StatusCode)
+		suite.Require().NotNil(suite.handled)
+		suite.Equal(body, *suite.handled)
+	}
+
+	// rotation: the verifier accepts both keys
+	suite.keys.Rotate("k2", []byte("new secret"))
+	request, err := http.NewRequest("GET", server.URL, nil)
+	suite.Require().NoError(err)
+	response, err := c.Do(request)
+	suite.Require().NoError(err)
+	response.Body.Close()
+	suite.Equal(http.StatusNoContent, response.StatusCode)
+
+	suite.Panics(func() {
+		NewServer(VerifierConfig{})
+	})
+}
+
+func (suite *ServerTestSuite) TestRetries() {
+	var attempts int
+	server := httptest.NewServer(NewServer(VerifierConfig{Keys: suite.keys})(
+		http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			attempts++
+			if attempts == 1 {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+			} else {
+				rw.WriteHeader(http.StatusNoContent)
+			}
+		}),
+	))
+
+	defer server.Close()
+
+	retryConfig := retry.Config{
+		Retries:  1,
+		Interval: time.Millisecond,
+		Check: func(r *http.Response, err error) bool {
+			return err == nil && r.StatusCode == http.StatusServiceUnavailable
+		},
+	}
+
+	testCases := []struct {
+		name               string
+		chain              client.Chain
+		expectedStatusCode int
+	}{
+		{
+			name: "SigningInsideRetry",
+			chain: client.NewChain(
+				retry.New(retryConfig, nil).Then,
+				NewClient(SignerConfig{Keys: suite.keys}),
+			),
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			// every attempt carries the same nonce, so the retry is rejected as a replay
+			name: "SigningOutsideRetry",
+			chain: client.NewChain(
+				NewClient(SignerConfig{Keys: suite.keys}),
+				retry.New(retryConfig, nil).Then,
+			),
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, testCase := range testCases {
+		suite.Run(testCase.name, func() {
+			attempts = 0
+			request, err := http.NewRequest("POST", server.URL, strings.NewReader("body"))
+			suite.Require().NoError(err)
+
+			response, err := testCase.chain.Then(server.Client()).Do(request)
+			suite.Require().NoError(err)
+			response.Body.Close()
+			suite.Equal(testCase.expectedStatusCode, response.StatusCode)
+		})
+	}
+}
+
+func TestServer(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
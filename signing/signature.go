@@ -0,0 +1,189 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultHeader is the header that carries signatures when no header is configured.
+	DefaultHeader = "X-Signature"
+
+	// HostHeader is the name used to sign the request's host.  Go moves the Host
+	// header into http.Request.Host, so it is handled specially.
+	HostHeader = "Host"
+
+	// canonicalVersion is the first line of the string to sign, which allows the
+	// format to change in the future
+	canonicalVersion = "v1"
+)
+
+var (
+	// ErrMissingSignature indicates that a request had no signature header.
+	ErrMissingSignature = errors.New("missing signature")
+
+	// ErrMalformedSignature indicates that a signature header could not be parsed.
+	ErrMalformedSignature = errors.New("malformed signature")
+
+	// ErrSignatureMismatch indicates that a signature did not match the request.
+	ErrSignatureMismatch = errors.New("signature mismatch")
+
+	// ErrHeaderNotSigned indicates that a signature did not cover a header that
+	// the server requires to be signed.
+	ErrHeaderNotSigned = errors.New("required header not signed")
+)
+
+// DefaultHeaders returns the headers that are signed when no headers are configured.
+func DefaultHeaders() []string {
+	return []string{HostHeader, "Content-Type"}
+}
+
+// signature is the parsed form of the signature header
+type signature struct {
+	keyID     string
+	timestamp int64
+	nonce     string
+	headers   []string // lowercased header names
+	sig       []byte
+}
+
+// String formats this signature as a header value.
+func (s signature) String() string {
+	var o strings.Builder
+	o.WriteString("key=")
+	o.WriteString(s.keyID)
+	o.WriteString(",ts=")
+	o.WriteString(strconv.FormatInt(s.timestamp, 10))
+	o.WriteString(",nonce=")
+	o.WriteString(s.nonce)
+	o.WriteString(",headers=")
+	o.WriteString(strings.Join(s.headers, ";"))
+	o.WriteString(",sig=")
+	o.WriteString(base64.RawURLEncoding.EncodeToString(s.sig))
+	return o.String()
+}
+
+// parseSignature parses a signature header value.  All fields are required, although
+// the list of headers may be empty.
+func parseSignature(v string) (s signature, err error) {
+	var seen int
+	for _, field := range strings.Split(v, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return signature{}, ErrMalformedSignature
+		}
+
+		switch name {
+		case "key":
+			s.keyID = value
+
+		case "ts":
+			s.timestamp, err = strconv.ParseInt(value, 10, 64)
+
+		case "nonce":
+			s.nonce = value
+
+		case "headers":
+			if len(value) > 0 {
+				s.headers = strings.Split(value, ";")
+			}
+
+		case "sig":
+			s.sig, err = base64.RawURLEncoding.DecodeString(value)
+
+		default:
+			// ignore unknown fields, for forward compatibility
+			continue
+		}
+
+		if err != nil {
+			return signature{}, ErrMalformedSignature
+		}
+
+		seen++
+	}
+
+	if seen != 5 || len(s.keyID) == 0 || len(s.nonce) == 0 || len(s.sig) == 0 {
+		return signature{}, ErrMalformedSignature
+	}
+
+	return
+}
+
+// headerNames canonicalizes a list of header names for a signature.
+func headerNames(names []string) []string {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		normalized = append(normalized, strings.ToLower(strings.TrimSpace(name)))
+	}
+
+	return normalized
+}
+
+// headerValue returns the value of a signed header.  Multiple values are joined with commas.
+func headerValue(request *http.Request, name string) string {
+	if name == "host" {
+		if len(request.Host) > 0 {
+			return request.Host
+		} else if request.URL != nil {
+			return request.URL.Host
+		}
+
+		return ""
+	}
+
+	values := request.Header.Values(textproto.CanonicalMIMEHeaderKey(name))
+	trimmed := make([]string, len(values))
+	for i, v := range values {
+		trimmed[i] = strings.TrimSpace(v)
+	}
+
+	return strings.Join(trimmed, ",")
+}
+
+// bodyDigest computes the hex SHA-256 digest of a request body.
+func bodyDigest(body []byte) string {
+	d := sha256.Sum256(body)
+	return hex.EncodeToString(d[:])
+}
+
+// canonical produces the string to sign for a request.
+func canonical(request *http.Request, s signature, digest string) []byte {
+	var o strings.Builder
+	o.WriteString(canonicalVersion)
+	o.WriteByte('\n')
+	o.WriteString(request.Method)
+	o.WriteByte('\n')
+	o.WriteString(request.URL.RequestURI())
+	o.WriteByte('\n')
+	o.WriteString(strconv.FormatInt(s.timestamp, 10))
+	o.WriteByte('\n')
+	o.WriteString(s.nonce)
+	o.WriteByte('\n')
+	for _, name := range s.headers {
+		o.WriteString(name)
+		o.WriteByte(':')
+		o.WriteString(headerValue(request, name))
+		o.WriteByte('\n')
+	}
+
+	o.WriteString(digest)
+	return []byte(o.String())
+}
+
+// computeSignature computes the HMAC-SHA256 of a request's canonical string.
+func computeSignature(secret []byte, request *http.Request, s signature, digest string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(canonical(request, s, digest))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package signing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SignatureTestSuite struct {
+	suite.Suite
+}
+
+func (suite *SignatureTestSuite) TestRoundTrip() {
+	expected := signature{
+		keyID:     "k1",
+		timestamp: 1718000000,
+		nonce:     "abc",
+		headers:   []string{"host", "content-type"},
+		sig:       []byte{1, 2, 3, 255},
+	}
+
+	actual, err := parseSignature(expected.String())
+	suite.Require().NoError(err)
+	suite.Equal(expected, actual)
+
+	expected.headers = nil
+	actual, err = parseSignature(expected.String())
+	suite.Require().NoError(err)
+	suite.Equal(expected, actual)
+
+	// unknown fields are ignored
+	actual, err = parseSignature(expected.String() + ",future=1")
+	suite.Require().NoError(err)
+	suite.Equal(expected, actual)
+}
+
+func (suite *SignatureTestSuite) TestMalformed() {
+	malformed := []string{
+		"",
+		"key=k1",
+		"key=k1,ts=1,nonce=n,headers=,sig",
+		"key=k1,ts=notanumber,nonce=n,headers=,sig=AQID",
+		"key=k1,ts=1,nonce=n,headers=,sig=!!!",
+		"key=,ts=1,nonce=n,headers=,sig=AQID",
+		"key=k1,ts=1,nonce=,headers=,sig=AQID",
+		"key=k1,ts=1,nonce=n,headers=,sig=",
+	}
+
+	for _, v := range malformed {
+		suite.Run(v, func() {
+			_, err := parseSignature(v)
+			suite.ErrorIs(err, ErrMalformedSignature)
+		})
+	}
+}
+
+func (suite *SignatureTestSuite) TestHeaderValue() {
+	request := httptest.NewRequest("GET", "http://example.com/path", nil)
+	request.Header.Add("X-Multi", " a ")
+	request.Header.Add("X-Multi", "b")
+
+	suite.Equal("example.com", headerValue(request, "host"))
+	suite.Equal("a,b", headerValue(request, "x-multi"))
+	suite.Empty(headerValue(request, "x-missing"))
+
+	request.Host = ""
+	suite.Equal("example.com", headerValue(request, "host"))
+}
+
+func (suite *SignatureTestSuite) TestCanonical() {
+	request := httptest.NewRequest("POST", "http://example.com/path?q=1", nil)
+	request.Header.Set("Content-Type", "text/plain")
+
+	s := signature{
+		timestamp: 1718000000,
+		nonce:     "nonce",
+		headers:   []string{"host", "content-type"},
+	}
+
+	suite.Equal(
+		"v1\nPOST\n/path?q=1\n1718000000\nnonce\nhost:example.com\ncontent-type:text/plain\n"+bodyDigest(nil),
+		string(canonical(request, s, bodyDigest(nil))),
+	)
+
+	// any change to the request changes the signature
+	secret := []byte("secret")
+	original := computeSignature(secret, request, s, bodyDigest(nil))
+	suite.NotEqual(original, computeSignature(secret, request, s, bodyDigest([]byte("body"))))
+	suite.NotEqual(original, computeSignature([]byte("other"), request, s, bodyDigest(nil)))
+
+	request.Header.Set("Content-Type", "application/json")
+	suite.NotEqual(original, computeSignature(secret, request, s, bodyDigest(nil)))
+}
+
+func (suite *SignatureTestSuite) TestDefaultHeaders() {
+	suite.Equal([]string{HostHeader, "Content-Type"}, DefaultHeaders())
+	suite.Equal([]string{"host", "content-type"}, headerNames(DefaultHeaders()))
+	suite.Equal(http.CanonicalHeaderKey(DefaultHeader), DefaultHeader)
+}
+
+func TestSignature(t *testing.T) {
+	suite.Run(t, new(SignatureTestSuite))
+}
@@ -0,0 +1,20 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package trace
+
+import "context"
+
+// contextKey is the internal context.Context key that stores the current SpanContext
+type contextKey struct{}
+
+// WithSpanContext returns a subcontext that carries the given SpanContext.
+func WithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, sc)
+}
+
+// FromContext returns the SpanContext carried by the given context, if any.
+func FromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(contextKey{}).(SpanContext)
+	return sc, ok
+}
@@ -0,0 +1,31 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ContextTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ContextTestSuite) TestFromContext() {
+	_, ok := FromContext(context.Background())
+	suite.False(ok)
+
+	expected, err := ParseTraceparent(testTraceparent)
+	suite.Require().NoError(err)
+
+	actual, ok := FromContext(WithSpanContext(context.Background(), expected))
+	suite.True(ok)
+	suite.Equal(expected, actual)
+}
+
+func TestContext(t *testing.T) {
+	suite.Run(t, new(ContextTestSuite))
+}
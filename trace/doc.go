@@ -0,0 +1,25 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package trace implements W3C Trace Context propagation for HTTP clients, round trippers,
+and servers.  See https://www.w3.org/TR/trace-context/.
+
+Server middleware parses the traceparent and tracestate headers of each request, starts a
+child span, and places that span's SpanContext in the request context.  Client middleware
+starts a child of the SpanContext in the request context, or a new trace if there is none, and
+injects the traceparent and tracestate headers into the outgoing request:
+
+	cfg := trace.Config{
+	  Recorder: myRecorder,
+	}
+
+	h := trace.NewServer(cfg)(myHandler)
+	c := client.NewChain(trace.NewClient(cfg)).Then(new(http.Client))
+
+Outgoing requests made with the context of an incoming request become part of the same trace.
+
+This package does not export spans anywhere.  Instead, the start and end of each span is
+reported to a Recorder, which can log them, record metrics, or forward them to a tracing system.
+*/
+package trace
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package trace
+
+import (
+	"net/http"
+
+	"github.com/xmidt-org/httpaux"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/observe"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+// NewServer creates serverside middleware that continues the trace described by each
+// request's traceparent and tracestate headers.  A request without a valid traceparent
+// starts a new trace.  The server span's SpanContext is available to handlers via FromContext.
+func NewServer(cfg Config) func(http.Handler) http.Handler {
+	t := newTracer(cfg)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
+			parent, _ := Extract(request.Header)
+			s := t.start(KindServer, parent, request)
+
+			ow := observe.New(rw)
+			next.ServeHTTP(ow, request.WithContext(
+				WithSpanContext(request.Context(), s.SpanContext),
+			))
+
+			t.end(s, observe.FinalStatusCode(ow), nil)
+		})
+	}
+}
+
+// inject starts a client span and produces the outgoing request.  The original
+// request is never modified.
+func (t *tracer) inject(request *http.Request) (*http.Request, Span) {
+	parent, _ := FromContext(request.Context())
+	s := t.start(KindClient, parent, request)
+
+	outgoing := request.Clone(WithSpanContext(request.Context(), s.SpanContext))
+	Inject(outgoing.Header, s.SpanContext)
+	return outgoing, s
+}
+
+// clientEnd finishes a client span.
+func (t *tracer) clientEnd(s Span, response *http.Response, err error) {
+	var statusCode int
+	if response != nil {
+		statusCode = response.StatusCode
+	}
+
+	t.end(s, statusCode, err)
+}
+
+// NewClient creates client middleware that starts a span for each outgoing request and
+// injects the traceparent and tracestate headers.  The parent span is taken from the
+// request's context.  If there is none, a new trace is started.
+//
+// If the decorated client is nil, http.DefaultClient is used.
+func NewClient(cfg Config) client.Constructor {
+	t := newTracer(cfg)
+	return func(next httpaux.Client) httpaux.Client {
+		if next == nil {
+			next = http.DefaultClient
+		}
+
+		return client.Func(func(request *http.Request) (*http.Response, error) {
+			outgoing, s := t.inject(request)
+			response, err := next.Do(outgoing)
+			t.clientEnd(s, response, err)
+			return response, err
+		})
+	}
+}
+
+// NewRoundTripper creates round tripper middleware that records a client span for each
+// HTTP transaction just as NewClient does.
+//
+// If the decorated round tripper is nil, http.DefaultTransport is used.
+func NewRoundTripper(cfg Config) roundtrip.Constructor {
+	t := newTracer(cfg)
+	return func(next http.RoundTripper) http.RoundTripper {
+		if next == nil {
+			next = http.DefaultTransport
+		}
+
+		return roundtrip.PreserveCloseIdler(
+			next,
+			roundtrip.Func(func(request *http.Request) (*http.Response, error) {
+				outgoing, s := t.inject(request)
+				response, err := next.RoundTrip(outgoing)
+				t.clientEnd(s, response, err)
+				return response, err
+			}),
+		)
+	}
+}
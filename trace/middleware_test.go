@@ -0,0 +1,290 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package trace
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/xmidt-org/httpaux/client"
+	"github.com/xmidt-org/httpaux/clock"
+	"github.com/xmidt-org/httpaux/httpmock"
+	"github.com/xmidt-org/httpaux/roundtrip"
+)
+
+// testRecorder is a Recorder that keeps every span it is given
+type testRecorder struct {
+	lock    sync.Mutex
+	started []Span
+	ended   []Span
+}
+
+func (r *testRecorder) Start(s Span) {
+	r.lock.Lock()
+	r.started = append(r.started, s)
+	r.lock.Unlock()
+}
+
+func (r *testRecorder) End(s Span) {
+	r.lock.Lock()
+	r.ended = append(r.ended, s)
+	r.lock.Unlock()
+}
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+
+	recorder *testRecorder
+	clock    *clock.Fake
+}
+
+func (suite *MiddlewareTestSuite) SetupTest() {
+	suite.recorder = new(testRecorder)
+	suite.clock = clock.NewFake(time.Now())
+}
+
+func (suite *MiddlewareTestSuite) config() Config {
+	return Config{
+		Recorder: suite.recorder,
+		Clock:    suite.clock,
+	}
+}
+
+func (suite *MiddlewareTestSuite) parent() SpanContext {
+	sc, err := ParseTraceparent(testTraceparent)
+	suite.Require().NoError(err)
+	sc.TraceState = "vendor=value"
+	return sc
+}
+
+// assertChild verifies that a span is a child of the given parent.
+func (suite *MiddlewareTestSuite) assertChild(parent SpanContext, s Span) {
+	suite.Equal(parent, s.Parent)
+	suite.Equal(parent.TraceID, s.SpanContext.TraceID)
+	suite.Equal(parent.Flags, s.SpanContext.Flags)
+	suite.Equal(parent.TraceState, s.SpanContext.TraceState)
+	suite.True(s.SpanContext.SpanID.IsValid())
+	suite.NotEqual(parent.SpanID, s.SpanContext.SpanID)
+}
+
+func (suite *MiddlewareTestSuite) TestServer() {
+	suite.Run("ContinueTrace", func() {
+		suite.SetupTest()
+		parent := suite.parent()
+
+		var handled SpanContext
+		h := NewServer(suite.config())(
+			http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
+				var ok bool
+				handled, ok = FromContext(request.Context())
+				suite.True(ok)
+				suite.clock.Add(time.Second)
+				rw.WriteHeader(http.StatusTeapot)
+			}),
+		)
+
+		request := httptest.NewRequest("GET", "/", nil)
+		Inject(request.Header, parent)
+		h.ServeHTTP(httptest.NewRecorder(), request)
+
+		suite.Require().Len(suite.recorder.started, 1)
+		suite.Require().Len(suite.recorder.ended, 1)
+		started, ended := suite.recorder.started[0], suite.recorder.ended[0]
+
+		suite.Equal(KindServer, started.Kind)
+		suite.assertChild(parent, started)
+		suite.Equal(handled, started.SpanContext)
+		suite.Zero(started.StatusCode)
+		suite.True(started.End.IsZero())
+
+		suite.Equal(started.SpanContext, ended.SpanContext)
+		suite.Equal(http.StatusTeapot, ended.StatusCode)
+		suite.Equal(time.Second, ended.End.Sub(ended.Start))
+		suite.NoError(ended.Err)
+	})
+
+	suite.Run("NewTrace", func() {
+		suite.SetupTest()
+		h := NewServer(suite.config())(
+			http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+		)
+
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set(TraceparentHeader, "invalid")
+		h.ServeHTTP(httptest.NewRecorder(), request)
+
+		suite.Require().Len(suite.recorder.ended, 1)
+		ended := suite.recorder.ended[0]
+		suite.False(ended.Parent.IsValid())
+		suite.True(ended.SpanContext.IsValid())
+		suite.True(ended.SpanContext.IsSampled())
+		suite.Equal(http.StatusOK, ended.StatusCode)
+	})
+
+	suite.Run("NotSampled", func() {
+		suite.SetupTest()
+		cfg := suite.config()
+		cfg.Sampler = func(*http.Request) bool { return false }
+		h := NewServer(cfg)(
+			http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+		)
+
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		suite.Require().Len(suite.recorder.ended, 1)
+		suite.False(suite.recorder.ended[0].SpanContext.IsSampled())
+	})
+}
+
+func (suite *MiddlewareTestSuite) TestDefaults() {
+	h := NewServer(Config{})(
+		http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
+			sc, ok := FromContext(request.Context())
+			suite.True(ok)
+			suite.True(sc.IsValid())
+		}),
+	)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func (suite *MiddlewareTestSuite) TestRandom() {
+	// an all-zero identifier is invalid, so the first 16 bytes must be skipped
+	cfg := suite.config()
+	cfg.Random = bytes.NewReader(append(
+		make([]byte, 16),
+		bytes.Repeat([]byte{0xab}, 16+8)...,
+	))
+
+	h := NewServer(cfg)(
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+	)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	suite.Require().Len(suite.recorder.ended, 1)
+	suite.Equal("abababababababababababababababab", suite.recorder.ended[0].SpanContext.TraceID.String())
+	suite.Equal("abababababababab", suite.recorder.ended[0].SpanContext.SpanID.String())
+}
+
+// sendAs returns a mock transport that captures the request it is sent and
+// responds with the given status code
+func (suite *MiddlewareTestSuite) sendAs(sent **http.Request, statusCode int) *httpmock.RoundTripper {
+	next := httpmock.NewRoundTripperSuite(suite)
+	next.OnAny().AssertRequest(
+		httpmock.RequestAsserterFunc(func(_ *assert.Assertions, r *http.Request) {
+			*sent = r
+		}),
+	).Return(&http.Response{StatusCode: statusCode, Body: httpmock.EmptyBody()}, nil).Once()
+
+	return next
+}
+
+// assertClientSpan verifies the single client span produced for a request with the given parent.
+func (suite *MiddlewareTestSuite) assertClientSpan(parent SpanContext, original, sent *http.Request, statusCode int) {
+	suite.Empty(original.Header.Get(TraceparentHeader), "the original request should not be modified")
+	suite.Require().Len(suite.recorder.started, 1)
+	suite.Require().Len(suite.recorder.ended, 1)
+	ended := suite.recorder.ended[0]
+	suite.Equal(KindClient, ended.Kind)
+	suite.assertChild(parent, ended)
+	suite.Equal(statusCode, ended.StatusCode)
+
+	suite.Require().NotNil(sent)
+	suite.Equal(ended.SpanContext.Traceparent(), sent.Header.Get(TraceparentHeader))
+	suite.Equal("vendor=value", sent.Header.Get(TracestateHeader))
+
+	sc, ok := FromContext(sent.Context())
+	suite.True(ok)
+	suite.Equal(ended.SpanContext, sc)
+}
+
+func (suite *MiddlewareTestSuite) TestNewClient() {
+	var (
+		parent = suite.parent()
+		sent   *http.Request
+		next   = suite.sendAs(&sent, http.StatusAccepted)
+		c      = client.NewChain(NewClient(suite.config())).Then(&http.Client{Transport: next})
+	)
+
+	request, err := http.NewRequestWithContext(
+		WithSpanContext(context.Background(), parent), "GET", "http://example.com/", nil,
+	)
+
+	suite.Require().NoError(err)
+	response, err := c.Do(request)
+	suite.Require().NoError(err)
+	defer response.Body.Close()
+	suite.Equal(http.StatusAccepted, response.StatusCode)
+	next.AssertExpectations()
+
+	suite.assertClientSpan(parent, request, sent, http.StatusAccepted)
+}
+
+func (suite *MiddlewareTestSuite) TestNewClientError() {
+	var (
+		expectedErr = errors.New("expected")
+		c           = NewClient(suite.config())(client.Func(func(*http.Request) (*http.Response, error) {
+			return nil, expectedErr
+		}))
+	)
+
+	request, err := http.NewRequest("GET", "http://example.com/", nil)
+	suite.Require().NoError(err)
+	_, err = c.Do(request) //nolint:bodyclose
+	suite.ErrorIs(err, expectedErr)
+
+	suite.Require().Len(suite.recorder.ended, 1)
+	ended := suite.recorder.ended[0]
+	suite.False(ended.Parent.IsValid())
+	suite.True(ended.SpanContext.IsValid())
+	suite.Zero(ended.StatusCode)
+	suite.ErrorIs(ended.Err, expectedErr)
+}
+
+func (suite *MiddlewareTestSuite) TestNewClientDefault() {
+	suite.NotNil(NewClient(suite.config())(nil))
+}
+
+func (suite *MiddlewareTestSuite) TestNewRoundTripper() {
+	var (
+		parent = suite.parent()
+		sent   *http.Request
+		next   = &httpmock.CloseIdler{
+			RoundTripper: suite.sendAs(&sent, http.StatusNoContent),
+		}
+
+		rt = roundtrip.NewChain(NewRoundTripper(suite.config())).Then(next)
+	)
+
+	request, err := http.NewRequestWithContext(
+		WithSpanContext(context.Background(), parent), "GET", "http://example.com/", nil,
+	)
+
+	suite.Require().NoError(err)
+	response, err := rt.RoundTrip(request)
+	suite.Require().NoError(err)
+	defer response.Body.Close()
+	suite.Equal(http.StatusNoContent, response.StatusCode)
+
+	suite.assertClientSpan(parent, request, sent, http.StatusNoContent)
+
+	next.OnCloseIdleConnections().Once()
+	roundtrip.CloseIdleConnections(rt)
+	next.AssertExpectations()
+}
+
+func (suite *MiddlewareTestSuite) TestNewRoundTripperDefault() {
+	suite.NotNil(NewRoundTripper(suite.config())(nil))
+}
+
+func TestMiddleware(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package trace
+
+import (
+	"crypto/rand"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/xmidt-org/httpaux/clock"
+)
+
+// Kind describes the role of a span.
+type Kind int
+
+const (
+	// KindServer is a span for handling an incoming request.
+	KindServer Kind = iota + 1
+
+	// KindClient is a span for an outgoing request.
+	KindClient
+)
+
+// String returns a human-readable label for this kind.
+func (k Kind) String() string {
+	switch k {
+	case KindServer:
+		return "server"
+
+	case KindClient:
+		return "client"
+
+	default:
+		return "unknown"
+	}
+}
+
+// Span describes a single HTTP transaction within a trace.
+type Span struct {
+	// Kind indicates whether this span is for a server or a client.
+	Kind Kind
+
+	// SpanContext identifies this span.
+	SpanContext SpanContext
+
+	// Parent identifies this span's parent.  This will be the zero value if this span
+	// started a new trace.
+	Parent SpanContext
+
+	// Request is the HTTP request for this span.  Recorders must not modify it.
+	Request *http.Request
+
+	// Start is when this span started.
+	Start time.Time
+
+	// End is when this span ended.  This field is unset when a span is started.
+	End time.Time
+
+	// StatusCode is the HTTP response status code.  This field is unset when a span is
+	// started, and remains unset if a client transaction failed with an error.
+	StatusCode int
+
+	// Err is the error from a client transaction.  This field is always nil for server spans.
+	Err error
+}
+
+// Recorder receives spans as they start and end.  Implementations must be safe for
+// concurrent use.
+type Recorder interface {
+	// Start is called when a span starts.
+	Start(Span)
+
+	// End is called when a span ends.
+	End(Span)
+}
+
+// nopRecorder is the Recorder used when none is configured
+type nopRecorder struct{}
+
+func (nopRecorder) Start(Span) {}
+func (nopRecorder) End(Span)   {}
+
+// Config is the set of configuration options for trace middleware.
+type Config struct {
+	// Recorder is the optional Recorder for spans.  If unset, spans are propagated
+	// but not recorded.
+	Recorder Recorder `json:"-" yaml:"-"`
+
+	// Sampler is the optional strategy for deciding whether a new trace is sampled.  It is
+	// only used when there is no parent span.  Child spans always inherit their parent's flags.
+	// If unset, all new traces are sampled.
+	Sampler func(*http.Request) bool `json:"-" yaml:"-"`
+
+	// Random is the optional source of randomness for trace and span identifiers.
+	// If unset, crypto/rand.Reader is used.
+	Random io.Reader `json:"-" yaml:"-"`
+
+	// Clock is the optional source of time for span start and end times.  If unset,
+	// clock.System is used.
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+// tracer is the common implementation for all trace middleware
+type tracer struct {
+	recorder Recorder
+	sampler  func(*http.Request) bool
+	random   io.Reader
+	now      func() time.Time
+}
+
+func newTracer(cfg Config) *tracer {
+	t := &tracer{
+		recorder: cfg.Recorder,
+		sampler:  cfg.Sampler,
+		random:   cfg.Random,
+		now:      clock.OrSystem(cfg.Clock).Now,
+	}
+
+	if t.recorder == nil {
+		t.recorder = nopRecorder{}
+	}
+
+	if t.sampler == nil {
+		t.sampler = func(*http.Request) bool { return true }
+	}
+
+	if t.random == nil {
+		t.random = rand.Reader
+	}
+
+	return t
+}
+
+// newID fills the given slice with random bytes, retrying until the result is nonzero.
+func (t *tracer) newID(id []byte) {
+	for {
+		if _, err := io.ReadFull(t.random, id); err != nil {
+			panic(err) // the random source is broken
+		}
+
+		for _, b := range id {
+			if b != 0 {
+				return
+			}
+		}
+	}
+}
+
+// start begins a span that is a child of the given parent.  If the parent is invalid,
+// a new trace is started.
+func (t *tracer) start(kind Kind, parent SpanContext, request *http.Request) Span {
+	s := Span{
+		Kind:    kind,
+		Request: request,
+	}
+
+	if parent.IsValid() {
+		s.Parent = parent
+		s.SpanContext.TraceID = parent.TraceID
+		s.SpanContext.Flags = parent.Flags
+		s.SpanContext.TraceState = parent.TraceState
+	} else {
+		t.newID(s.SpanContext.TraceID[:])
+		if t.sampler(request) {
+			s.SpanContext.Flags = FlagSampled
+		}
+	}
+
+	t.newID(s.SpanContext.SpanID[:])
+	s.Start = t.now()
+	t.recorder.Start(s)
+	return s
+}
+
+// end finishes a span.
+func (t *tracer) end(s Span, statusCode int, err error) {
+	s.End = t.now()
+	s.StatusCode = statusCode
+	s.Err = err
+	t.recorder.End(s)
+}
@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package trace
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	// TraceparentHeader is the W3C header that carries the trace and parent span identifiers.
+	TraceparentHeader = "traceparent"
+
+	// TracestateHeader is the W3C header that carries vendor-specific trace information.
+	TracestateHeader = "tracestate"
+
+	// traceparentVersion is the version of the traceparent format this package produces
+	traceparentVersion = "00"
+
+	// traceparentLength is the length of a version 00 traceparent
+	traceparentLength = 55
+)
+
+var (
+	// ErrInvalidTraceparent indicates that a traceparent value could not be parsed.
+	ErrInvalidTraceparent = errors.New("invalid traceparent")
+)
+
+// TraceID identifies a trace, which is the set of all spans for a distributed operation.
+type TraceID [16]byte
+
+// IsValid tests if this TraceID is nonzero.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// String returns the lowercase hex form of this TraceID.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID identifies a single span within a trace.
+type SpanID [8]byte
+
+// IsValid tests if this SpanID is nonzero.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// String returns the lowercase hex form of this SpanID.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// Flags are the trace flags of a SpanContext.
+type Flags byte
+
+const (
+	// FlagSampled indicates that the caller may have recorded trace data.
+	FlagSampled Flags = 0x01
+)
+
+// SpanContext is the propagated state of a span.
+type SpanContext struct {
+	// TraceID is the trace this span belongs to.
+	TraceID TraceID
+
+	// SpanID identifies this span.
+	SpanID SpanID
+
+	// Flags are the trace flags, such as FlagSampled.
+	Flags Flags
+
+	// TraceState is the vendor-specific tracestate value, which is propagated as is.
+	TraceState string
+}
+
+// IsValid tests if both the TraceID and SpanID of this SpanContext are valid.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// IsSampled tests if FlagSampled is set.
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// Traceparent returns the version 00 traceparent header value for this SpanContext.
+func (sc SpanContext) Traceparent() string {
+	var o strings.Builder
+	o.Grow(traceparentLength)
+	o.WriteString(traceparentVersion)
+	o.WriteRune('-')
+	o.WriteString(sc.TraceID.String())
+	o.WriteRune('-')
+	o.WriteString(sc.SpanID.String())
+	o.WriteRune('-')
+	o.WriteString(hex.EncodeToString([]byte{byte(sc.Flags)}))
+	return o.String()
+}
+
+// decodeLowerHex decodes lowercase hex into dst, which must be exactly half the length of src.
+func decodeLowerHex(dst []byte, src string) bool {
+	if len(src) != 2*len(dst) || strings.ToLower(src) != src {
+		return false
+	}
+
+	_, err := hex.Decode(dst, []byte(src))
+	return err == nil
+}
+
+// ParseTraceparent parses a traceparent header value.  The returned SpanContext has no
+// TraceState.  Any error returned wraps ErrInvalidTraceparent.
+//
+// As required by the W3C specification, versions later than 00 are parsed as far as
+// the version 00 fields, and an all-zero trace or parent identifier is invalid.
+func ParseTraceparent(v string) (sc SpanContext, err error) {
+	var version [1]byte
+	switch {
+	case len(v) < traceparentLength:
+		err = ErrInvalidTraceparent
+
+	case !decodeLowerHex(version[:], v[0:2]) || version[0] == 0xff:
+		err = ErrInvalidTraceparent
+
+	case version[0] == 0x00 && len(v) != traceparentLength:
+		err = ErrInvalidTraceparent
+
+	case len(v) > traceparentLength && v[traceparentLength] != '-':
+		err = ErrInvalidTraceparent
+
+	case v[2] != '-' || v[35] != '-' || v[52] != '-':
+		err = ErrInvalidTraceparent
+	}
+
+	var flags [1]byte
+	switch {
+	case err != nil:
+		// already invalid
+
+	case !decodeLowerHex(sc.TraceID[:], v[3:35]) || !sc.TraceID.IsValid():
+		err = ErrInvalidTraceparent
+
+	case !decodeLowerHex(sc.SpanID[:], v[36:52]) || !sc.SpanID.IsValid():
+		err = ErrInvalidTraceparent
+
+	case !decodeLowerHex(flags[:], v[53:55]):
+		err = ErrInvalidTraceparent
+
+	default:
+		sc.Flags = Flags(flags[0])
+	}
+
+	if err != nil {
+		return SpanContext{}, err
+	}
+
+	return
+}
+
+// Extract parses the SpanContext from the traceparent and tracestate headers.  If there
+// is no valid traceparent, this function returns false and the tracestate is ignored.
+func Extract(h http.Header) (SpanContext, bool) {
+	values := h.Values(TraceparentHeader)
+	if len(values) != 1 {
+		// multiple traceparent headers are invalid
+		return SpanContext{}, false
+	}
+
+	sc, err := ParseTraceparent(strings.TrimSpace(values[0]))
+	if err != nil {
+		return SpanContext{}, false
+	}
+
+	sc.TraceState = strings.Join(h.Values(TracestateHeader), ",")
+	return sc, true
+}
+
+// Inject sets the traceparent and tracestate headers from the given SpanContext.
+// If the SpanContext has no TraceState, any tracestate header is removed.
+func Inject(h http.Header, sc SpanContext) {
+	h.Set(TraceparentHeader, sc.Traceparent())
+	if len(sc.TraceState) > 0 {
+		h.Set(TracestateHeader, sc.TraceState)
+	} else {
+		h.Del(TracestateHeader)
+	}
+}
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2024 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package trace
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID      = "00f067aa0ba902b7"
+)
+
+type SpanContextTestSuite struct {
+	suite.Suite
+}
+
+func (suite *SpanContextTestSuite) TestParseTraceparent() {
+	suite.Run("Valid", func() {
+		sc, err := ParseTraceparent(testTraceparent)
+		suite.Require().NoError(err)
+		suite.True(sc.IsValid())
+		suite.True(sc.IsSampled())
+		suite.Equal(testTraceID, sc.TraceID.String())
+		suite.Equal(testSpanID, sc.SpanID.String())
+		suite.Equal(testTraceparent, sc.Traceparent())
+	})
+
+	suite.Run("NotSampled", func() {
+		sc, err := ParseTraceparent("00-" + testTraceID + "-" + testSpanID + "-00")
+		suite.Require().NoError(err)
+		suite.False(sc.IsSampled())
+	})
+
+	suite.Run("FutureVersion", func() {
+		sc, err := ParseTraceparent("cc-" + testTraceID + "-" + testSpanID + "-01-what-the-future-holds")
+		suite.Require().NoError(err)
+		suite.Equal(testTraceID, sc.TraceID.String())
+		suite.Equal(testSpanID, sc.SpanID.String())
+		suite.Equal(FlagSampled, sc.Flags)
+	})
+
+	invalid := []string{
+		"",
+		"00",
+		testTraceparent + "-",
+		"ff-" + testTraceID + "-" + testSpanID + "-01",
+		"cc-" + testTraceID + "-" + testSpanID + "-01.extra",
+		"0g-" + testTraceID + "-" + testSpanID + "-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-" + testSpanID + "-01",
+		"00-00000000000000000000000000000000-" + testSpanID + "-01",
+		"00-" + testTraceID + "-0000000000000000-01",
+		"00-" + testTraceID + "-" + testSpanID + "-0x",
+		"00_" + testTraceID + "_" + testSpanID + "_01",
+	}
+
+	for _, v := range invalid {
+		suite.Run(v, func() {
+			sc, err := ParseTraceparent(v)
+			suite.ErrorIs(err, ErrInvalidTraceparent)
+			suite.Equal(SpanContext{}, sc)
+		})
+	}
+}
+
+func (suite *SpanContextTestSuite) TestExtract() {
+	suite.Run("Missing", func() {
+		_, ok := Extract(http.Header{})
+		suite.False(ok)
+	})
+
+	suite.Run("Invalid", func() {
+		h := http.Header{}
+		h.Set(TraceparentHeader, "invalid")
+		h.Set(TracestateHeader, "vendor=value")
+		_, ok := Extract(h)
+		suite.False(ok)
+	})
+
+	suite.Run("Duplicate", func() {
+		h := http.Header{}
+		h.Add(TraceparentHeader, testTraceparent)
+		h.Add(TraceparentHeader, testTraceparent)
+		_, ok := Extract(h)
+		suite.False(ok)
+	})
+
+	suite.Run("Valid", func() {
+		h := http.Header{}
+		h.Set(TraceparentHeader, testTraceparent)
+		h.Add(TracestateHeader, "a=1")
+		h.Add(TracestateHeader, "b=2")
+		sc, ok := Extract(h)
+		suite.Require().True(ok)
+		suite.Equal(testTraceID, sc.TraceID.String())
+		suite.Equal("a=1,b=2", sc.TraceState)
+	})
+}
+
+func (suite *SpanContextTestSuite) TestInject() {
+	sc, err := ParseTraceparent(testTraceparent)
+	suite.Require().NoError(err)
+
+	h := http.Header{}
+	h.Set(TracestateHeader, "stale=1")
+	Inject(h, sc)
+	suite.Equal(testTraceparent, h.Get(TraceparentHeader))
+	suite.Empty(h.Values(TracestateHeader))
+
+	sc.TraceState = "vendor=value"
+	Inject(h, sc)
+	suite.Equal(testTraceparent, h.Get(TraceparentHeader))
+	suite.Equal("vendor=value", h.Get(TracestateHeader))
+}
+
+func TestSpanContext(t *testing.T) {
+	suite.Run(t, new(SpanContextTestSuite))
+}